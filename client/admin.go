@@ -0,0 +1,128 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+var (
+	health       = route("GET", "/health")
+	apiKeyCreate = route("POST", "/admin/apiKeys/create")
+	apiKeyList   = route("GET", "/admin/apiKeys/list")
+	apiKeyRevoke = route("POST", "/admin/apiKeys/revoke")
+	chatLink     = route("POST", "/admin/chatIdentities/link")
+	importBundle = route("POST", "/admin/import")
+	stateExport  = route("GET", "/admin/state/export")
+	stateImport  = route("POST", "/admin/state/import")
+)
+
+// APIKeyRequest - параметры нового ключа; TeamName или UserID обязательны для роли team-lead
+type APIKeyRequest struct {
+	Name     string `json:"name"`
+	Role     string `json:"role"`
+	UserID   string `json:"user_id,omitempty"`
+	TeamName string `json:"team_name,omitempty"`
+}
+
+// Health возвращает nil, если сервис отвечает
+func (c *Client) Health(ctx context.Context) error {
+	return c.call(ctx, health, nil, nil, nil)
+}
+
+func (c *Client) CreateAPIKey(ctx context.Context, req APIKeyRequest) (*NewAPIKey, error) {
+	var key NewAPIKey
+	if err := c.call(ctx, apiKeyCreate, nil, req, &key); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (c *Client) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	var resp struct {
+		APIKeys []APIKey `json:"api_keys"`
+	}
+	if err := c.call(ctx, apiKeyList, nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.APIKeys, nil
+}
+
+func (c *Client) RevokeAPIKey(ctx context.Context, name string) (*APIKey, error) {
+	var resp struct {
+		APIKey *APIKey `json:"api_key"`
+	}
+	if err := c.call(ctx, apiKeyRevoke, nil, map[string]string{"name": name}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.APIKey, nil
+}
+
+// LinkChatIdentity связывает пользователя чата с пользователем сервиса для slash-команд
+func (c *Client) LinkChatIdentity(ctx context.Context, chatUserID, userID string) (*ChatIdentity, error) {
+	identity := &ChatIdentity{ChatUserID: chatUserID, UserID: userID}
+	if err := c.call(ctx, chatLink, nil, identity, identity); err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+// Import загружает набор команд, пользователей и PR. Ошибки в строках набора - не ошибка вызова:
+// они в ImportReport.Errors, и тогда ничего не применяется.
+func (c *Client) Import(ctx context.Context, bundle *ImportBundle, dryRun bool) (*ImportReport, error) {
+	var report ImportReport
+	if err := c.call(ctx, importBundle, importQuery(dryRun), bundle, &report, http.StatusUnprocessableEntity); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// ImportCSV загружает один раздел набора в CSV: teams, users или pull_requests
+func (c *Client) ImportCSV(ctx context.Context, section string, csv io.Reader, dryRun bool) (*ImportReport, error) {
+	data, err := io.ReadAll(csv)
+	if err != nil {
+		return nil, err
+	}
+	q := importQuery(dryRun)
+	q.Set("section", section)
+	req := &Request{
+		Method:      importBundle.method,
+		Path:        importBundle.path,
+		Query:       q,
+		Body:        data,
+		ContentType: "text/csv",
+		accept:      []int{http.StatusUnprocessableEntity},
+	}
+	var report ImportReport
+	if err := c.send(ctx, req, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func importQuery(dryRun bool) url.Values {
+	q := url.Values{}
+	if dryRun {
+		q.Set("dry_run", "true")
+	}
+	return q
+}
+
+// ExportState скачивает архив всего состояния сервиса
+func (c *Client) ExportState(ctx context.Context) (*StateArchive, error) {
+	var archive StateArchive
+	if err := c.call(ctx, stateExport, nil, nil, &archive); err != nil {
+		return nil, err
+	}
+	return &archive, nil
+}
+
+// ImportState восстанавливает архив в пустую базу; в непустую - ошибка CodeNotEmpty
+func (c *Client) ImportState(ctx context.Context, archive *StateArchive, dryRun bool) (*StateRestoreReport, error) {
+	var report StateRestoreReport
+	if err := c.call(ctx, stateImport, importQuery(dryRun), archive, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
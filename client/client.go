@@ -0,0 +1,291 @@
+// Package client - Go-клиент HTTP API сервиса назначения ревьюверов.
+//
+// Типы запросов и ответов - псевдонимы типов сервиса (models.go), поэтому не расходятся с ним,
+// а команда check-openapi проверяет, что у каждой операции спецификации есть метод клиента (Endpoints).
+//
+//	c := client.New("http://localhost:8080", client.WithToken(os.Getenv("PR_REVIEWER_TOKEN")))
+//	pr, err := c.CreatePR(ctx, "PR-1", "Add search", "u1")
+//	if client.ErrorCode(err) == client.CodePRExists {
+//		...
+//	}
+//
+// Ошибки сети, ответы 5xx, 429 и 409 IDEMPOTENCY_IN_PROGRESS повторяются с экспоненциальной задержкой
+// (RetryPolicy). GET-запросы повторяются всегда. POST-запрос повторяется, только если вызывающий задал
+// Idempotency-Key (WithIdempotencyKey): без ключа ответ мог потеряться после того, как сервис применил
+// изменение, и повтор применил бы его второй раз. Ключ работает, если на сервере включена идемпотентность;
+// иначе сервис отклоняет запрос с ключом (IDEMPOTENCY_DISABLED), а не выполняет его без защиты.
+// Сроки задаются контекстом: у http.Client по умолчанию нет таймаута, чтобы не обрывать потоки.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	idempotencyHeader = "Idempotency-Key"
+	requestIDHeader   = "X-Request-ID"
+)
+
+// RetryPolicy - сколько раз и с какой задержкой повторять запрос
+type RetryPolicy struct {
+	// MaxAttempts - число попыток вместе с первой; 1 отключает повторы
+	MaxAttempts int
+	// MinBackoff - задержка перед первым повтором, дальше удваивается до MaxBackoff
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+var (
+	DefaultRetryPolicy = RetryPolicy{MaxAttempts: 4, MinBackoff: 250 * time.Millisecond, MaxBackoff: 5 * time.Second}
+	NoRetry            = RetryPolicy{MaxAttempts: 1}
+)
+
+// backoff - задержка перед повтором номер attempt: экспонента со случайным разбросом (full jitter).
+// Retry-After сервера важнее, но не больше MaxBackoff.
+func (p RetryPolicy) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return min(retryAfter, p.MaxBackoff)
+	}
+	d := p.MinBackoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	d = min(d, p.MaxBackoff)
+	if d <= 0 {
+		return 0
+	}
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(d)))
+	if err != nil {
+		return d
+	}
+	return time.Duration(n.Int64()) + 1
+}
+
+type Client struct {
+	baseURL string
+	http    *http.Client
+	token   string
+	actor   string
+	retry   RetryPolicy
+}
+
+type Option func(*Client)
+
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.http = hc
+	}
+}
+
+// WithToken задаёт учётные данные: JWT передаётся как Bearer, API-ключ - в X-API-Key
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithActor задаёт X-Actor - инициатора изменений в журнале аудита, когда сервис работает без аутентификации
+func WithActor(actor string) Option {
+	return func(c *Client) {
+		c.actor = actor
+	}
+}
+
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Client) {
+		if policy.MaxAttempts < 1 {
+			policy.MaxAttempts = 1
+		}
+		c.retry = policy
+	}
+}
+
+// New создаёт клиент сервиса по адресу вида http://host:8080
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    &http.Client{},
+		retry:   DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+type idempotencyKeyContext struct{}
+
+// WithIdempotencyKey задаёт Idempotency-Key POST-запроса; с ним клиент повторяет POST при ошибках.
+// Ключ должен быть свой у каждого изменения; постоянный ключ делает повтор безопасным
+// и после перезапуска вызывающего процесса.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContext{}, key)
+}
+
+// Request - произвольный запрос к API, например для эндпоинта, которого ещё нет среди методов клиента
+type Request struct {
+	Method      string
+	Path        string
+	Query       url.Values
+	Header      http.Header
+	Body        []byte
+	ContentType string
+
+	// accept - статусы ошибок, тело которых - обычный ответ (отчёт импорта или пакета с ошибками)
+	accept []int
+}
+
+// Do выполняет запрос с теми же повторами и разбором ошибок, что и типизированные методы;
+// POST повторяется, если Idempotency-Key задан в req.Header или через WithIdempotencyKey.
+// Ответ 4xx или 5xx возвращается как *Error; тело успешного ответа закрывает вызывающий.
+func (c *Client) Do(ctx context.Context, req *Request) (*http.Response, error) {
+	header := req.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	// Общие для всех попыток: по ключу сервер узнаёт повтор, по X-Request-ID попытки видны в аудите как одна
+	if req.Method == "POST" && header.Get(idempotencyHeader) == "" {
+		if key, _ := ctx.Value(idempotencyKeyContext{}).(string); key != "" {
+			header.Set(idempotencyHeader, key)
+		}
+	}
+	if header.Get(requestIDHeader) == "" {
+		header.Set(requestIDHeader, randomID())
+	}
+	// Изменение без ключа не повторяется: сервис мог применить его, а ответ потеряться
+	repeatable := req.Method == "GET" || header.Get(idempotencyHeader) != ""
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.attempt(ctx, req, header)
+		if err == nil {
+			return resp, nil
+		}
+		retryAfter, retry := retryable(err)
+		if !retry || !repeatable || attempt >= c.retry.MaxAttempts || ctx.Err() != nil {
+			return nil, err
+		}
+
+		timer := time.NewTimer(c.retry.backoff(attempt, retryAfter))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		}
+	}
+}
+
+func (c *Client) attempt(ctx context.Context, req *Request, header http.Header) (*http.Response, error) {
+	u := c.baseURL + req.Path
+	if len(req.Query) > 0 {
+		u += "?" + req.Query.Encode()
+	}
+	var body io.Reader
+	if req.Body != nil {
+		body = bytes.NewReader(req.Body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header = header.Clone()
+	if req.Body != nil {
+		httpReq.Header.Set("Content-Type", req.ContentType)
+	}
+	if c.token != "" {
+		if strings.Count(c.token, ".") == 2 {
+			httpReq.Header.Set("Authorization", "Bearer "+c.token)
+		} else {
+			httpReq.Header.Set("X-API-Key", c.token)
+		}
+	}
+	if c.actor != "" {
+		httpReq.Header.Set("X-Actor", c.actor)
+	}
+
+	resp, err := c.http.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 400 {
+		return resp, nil
+	}
+	for _, status := range req.accept {
+		if resp.StatusCode == status {
+			return resp, nil
+		}
+	}
+	defer resp.Body.Close()
+	return nil, decodeError(resp)
+}
+
+// retryable решает, стоит ли повторять запрос; ошибки контекста не повторяются
+func retryable(err error) (time.Duration, bool) {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		retry := apiErr.StatusCode >= 500 || apiErr.StatusCode == http.StatusTooManyRequests ||
+			apiErr.Code == CodeIdempotencyInProgress
+		return apiErr.retryAfter, retry
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return 0, false
+	}
+	var urlErr *url.Error
+	return 0, errors.As(err, &urlErr)
+}
+
+// call отправляет in как JSON и разбирает ответ в out; nil пропускает тело запроса или ответа
+func (c *Client) call(ctx context.Context, e endpoint, query url.Values, in, out interface{}, accept ...int) error {
+	req := &Request{Method: e.method, Path: e.path, Query: query, accept: accept}
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		req.Body, req.ContentType = data, "application/json"
+	}
+	return c.send(ctx, req, out)
+}
+
+func (c *Client) send(ctx context.Context, req *Request, out interface{}) error {
+	resp, err := c.Do(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s %s: decode response: %w", req.Method, req.Path, err)
+	}
+	return nil
+}
+
+// stream возвращает тело ответа как есть: выгрузки CSV и NDJSON
+func (c *Client) stream(ctx context.Context, e endpoint, query url.Values) (io.ReadCloser, error) {
+	resp, err := c.Do(ctx, &Request{Method: e.method, Path: e.path, Query: query})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func randomID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,658 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"pr-reviewer-service/internal/models"
+	"pr-reviewer-service/internal/openapi"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fastRetry - повторы без заметных пауз, чтобы тесты не ждали DefaultRetryPolicy
+var fastRetry = RetryPolicy{MaxAttempts: 4, MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+// recordedRequest - то, что фейковый сервер получил за одну попытку
+type recordedRequest struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   string
+}
+
+// fakeServer отвечает на запросы клиента без базы: ответ задаёт handle, каждая попытка записывается.
+// Перед handle стоит проверка запросов по настоящей спецификации, поэтому ошибки валидации - как у сервиса.
+type fakeServer struct {
+	*httptest.Server
+	handle func(w http.ResponseWriter, r *http.Request, attempt int)
+
+	mu       sync.Mutex
+	requests []recordedRequest
+}
+
+func newFakeServer(t *testing.T, handle func(w http.ResponseWriter, r *http.Request, attempt int)) *fakeServer {
+	t.Helper()
+	f := &fakeServer{handle: handle}
+	f.Server = httptest.NewServer(openapi.MustLoad().Middleware(http.HandlerFunc(f.serve)))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeServer) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	f.mu.Lock()
+	f.requests = append(f.requests, recordedRequest{r.Method, r.URL.Path, r.URL.Query(), r.Header.Clone(), string(body)})
+	attempt := len(f.requests)
+	f.mu.Unlock()
+	f.handle(w, r, attempt)
+}
+
+func (f *fakeServer) received() []recordedRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]recordedRequest(nil), f.requests...)
+}
+
+func (f *fakeServer) client(opts ...Option) *Client {
+	return New(f.URL+"/", append([]Option{WithRetry(fastRetry)}, opts...)...)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError отвечает в формате ошибок сервиса
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	var resp models.ErrorResponse
+	resp.Error.Code, resp.Error.Message = code, message
+	writeJSON(w, status, resp)
+}
+
+func TestTypedMethods(t *testing.T) {
+	created := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	pr := &PullRequest{
+		PullRequestID:     "pr-1",
+		PullRequestName:   "Add search",
+		AuthorID:          "u1",
+		Status:            StatusOpen,
+		AssignedReviewers: []string{"u2", "u3"},
+		CreatedAt:         &created,
+	}
+	server := newFakeServer(t, func(w http.ResponseWriter, r *http.Request, attempt int) {
+		switch r.URL.Path {
+		case "/pullRequest/create", "/pullRequest/get", "/pullRequest/merge":
+			writeJSON(w, http.StatusOK, map[string]interface{}{"pr": pr})
+		case "/pullRequest/reassign":
+			writeJSON(w, http.StatusOK, map[string]interface{}{"pr": pr, "replaced_by": "u4"})
+		case "/pullRequest/list":
+			writeJSON(w, http.StatusOK, PullRequestPage{PullRequests: []PullRequest{*pr}, NextCursor: "c2"})
+		case "/team/add":
+			var team Team
+			json.NewDecoder(r.Body).Decode(&team)
+			writeJSON(w, http.StatusCreated, map[string]interface{}{"team": team})
+		case "/users/setIsActive":
+			writeJSON(w, http.StatusOK, map[string]interface{}{"user": User{UserID: "u2", TeamName: "backend"}})
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	c := server.client(WithActor("release-bot"))
+	ctx := context.Background()
+
+	got, err := c.CreatePR(ctx, "pr-1", "Add search", "u1")
+	if err != nil {
+		t.Fatalf("CreatePR() error = %v", err)
+	}
+	if got.PullRequestID != "pr-1" || len(got.AssignedReviewers) != 2 || !got.CreatedAt.Equal(created) {
+		t.Errorf("CreatePR() = %+v", got)
+	}
+	if _, err := c.GetPR(ctx, "pr-1"); err != nil {
+		t.Fatalf("GetPR() error = %v", err)
+	}
+	if _, err := c.MergePR(ctx, "pr-1"); err != nil {
+		t.Fatalf("MergePR() error = %v", err)
+	}
+	reassigned, err := c.ReassignReviewer(ctx, "pr-1", "u2")
+	if err != nil {
+		t.Fatalf("ReassignReviewer() error = %v", err)
+	}
+	if reassigned.ReplacedBy != "u4" || reassigned.PR == nil || reassigned.PR.PullRequestID != "pr-1" {
+		t.Errorf("ReassignReviewer() = %+v", reassigned)
+	}
+	page, err := c.ListPRs(ctx, PRListOptions{
+		TeamName:    "backend",
+		Statuses:    []string{StatusOpen, StatusMerged},
+		MinAge:      36 * time.Hour,
+		OverdueOnly: true,
+		Sort:        "created_at",
+		Order:       "desc",
+		ListOptions: ListOptions{Limit: 20, Cursor: "c1"},
+	})
+	if err != nil {
+		t.Fatalf("ListPRs() error = %v", err)
+	}
+	if len(page.PullRequests) != 1 || page.NextCursor != "c2" {
+		t.Errorf("ListPRs() = %+v", page)
+	}
+	team, err := c.CreateTeam(ctx, &Team{TeamName: "backend", Members: []TeamMember{{UserID: "u1", Username: "Alice", IsActive: true}}})
+	if err != nil {
+		t.Fatalf("CreateTeam() error = %v", err)
+	}
+	if team.TeamName != "backend" || len(team.Members) != 1 {
+		t.Errorf("CreateTeam() = %+v", team)
+	}
+	if _, err := c.SetUserActive(ctx, "u2", false); err != nil {
+		t.Fatalf("SetUserActive() error = %v", err)
+	}
+
+	requests := server.received()
+	want := []struct {
+		method, path string
+		query        url.Values
+		body         map[string]interface{}
+	}{
+		{"POST", "/pullRequest/create", url.Values{}, map[string]interface{}{"pull_request_id": "pr-1", "pull_request_name": "Add search", "author_id": "u1"}},
+		{"GET", "/pullRequest/get", url.Values{"pull_request_id": {"pr-1"}}, nil},
+		{"POST", "/pullRequest/merge", url.Values{}, map[string]interface{}{"pull_request_id": "pr-1"}},
+		{"POST", "/pullRequest/reassign", url.Values{}, map[string]interface{}{"pull_request_id": "pr-1", "old_user_id": "u2"}},
+		{"GET", "/pullRequest/list", url.Values{
+			"team_name": {"backend"},
+			"status":    {"OPEN,MERGED"},
+			"min_age":   {"36h0m0s"},
+			"overdue":   {"true"},
+			"sort":      {"created_at"},
+			"order":     {"desc"},
+			"limit":     {"20"},
+			"cursor":    {"c1"},
+		}, nil},
+		{"POST", "/team/add", url.Values{}, map[string]interface{}{
+			"team_name": "backend",
+			"members":   []interface{}{map[string]interface{}{"user_id": "u1", "username": "Alice", "is_active": true}},
+		}},
+		{"POST", "/users/setIsActive", url.Values{}, map[string]interface{}{"user_id": "u2", "is_active": false}},
+	}
+	if len(requests) != len(want) {
+		t.Fatalf("server received %d requests, want %d", len(requests), len(want))
+	}
+	for i, w := range want {
+		r := requests[i]
+		if r.Method != w.method || r.Path != w.path {
+			t.Errorf("request %d = %s %s, want %s %s", i, r.Method, r.Path, w.method, w.path)
+			continue
+		}
+		if fmt.Sprint(r.Query) != fmt.Sprint(w.query) {
+			t.Errorf("%s query = %v, want %v", w.path, r.Query, w.query)
+		}
+		if r.Header.Get("X-Actor") != "release-bot" {
+			t.Errorf("%s X-Actor = %q", w.path, r.Header.Get("X-Actor"))
+		}
+		if w.body == nil {
+			if r.Body != "" {
+				t.Errorf("%s has a body: %s", w.path, r.Body)
+			}
+			continue
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("%s Content-Type = %q", w.path, ct)
+		}
+		var body map[string]interface{}
+		if err := json.Unmarshal([]byte(r.Body), &body); err != nil {
+			t.Errorf("%s body %q: %v", w.path, r.Body, err)
+			continue
+		}
+		if fmt.Sprint(body) != fmt.Sprint(w.body) {
+			t.Errorf("%s body = %v, want %v", w.path, body, w.body)
+		}
+	}
+}
+
+func TestRunPRBatchRejectedReport(t *testing.T) {
+	server := newFakeServer(t, func(w http.ResponseWriter, r *http.Request, attempt int) {
+		writeJSON(w, http.StatusUnprocessableEntity, BatchReport{
+			Mode:   BatchAtomic,
+			Failed: 1,
+			Results: []BatchResult{
+				{Index: 0, Op: BatchOpMerge, PullRequestID: "pr-1", Status: models.BatchItemSkipped},
+				{Index: 1, Op: BatchOpReassign, PullRequestID: "pr-2", Status: models.BatchItemError,
+					Error: &models.BatchItemErrorBody{Code: CodePRMerged, Message: "cannot reassign on merged PR"}},
+			},
+		})
+	})
+
+	report, err := server.client().RunPRBatch(context.Background(), &BatchRequest{
+		Mode: BatchAtomic,
+		Operations: []BatchOperation{
+			{Op: BatchOpMerge, PullRequestID: "pr-1"},
+			{Op: BatchOpReassign, PullRequestID: "pr-2", OldUserID: "u2"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("RunPRBatch() error = %v, want the report", err)
+	}
+	if report.Failed != 1 || len(report.Results) != 2 || report.Results[1].Error == nil || report.Results[1].Error.Code != CodePRMerged {
+		t.Errorf("RunPRBatch() = %+v", report)
+	}
+	// 422 - ответ, а не ошибка: повторять нечего
+	if n := len(server.received()); n != 1 {
+		t.Errorf("server received %d requests, want 1", n)
+	}
+}
+
+func TestErrorDecoding(t *testing.T) {
+	tests := []struct {
+		name    string
+		handle  func(w http.ResponseWriter)
+		status  int
+		code    string
+		message string
+	}{
+		{"service error", func(w http.ResponseWriter) {
+			writeError(w, http.StatusConflict, CodePRExists, "PR id already exists")
+		}, http.StatusConflict, CodePRExists, "PR id already exists"},
+		{"not found", func(w http.ResponseWriter) {
+			writeError(w, http.StatusNotFound, CodeNotFound, "resource not found")
+		}, http.StatusNotFound, CodeNotFound, "resource not found"},
+		{"plain error", func(w http.ResponseWriter) {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "admin role required"})
+		}, http.StatusForbidden, "", "admin role required"},
+		{"not json", func(w http.ResponseWriter) {
+			http.Error(w, "upstream is down", http.StatusBadRequest)
+		}, http.StatusBadRequest, "", "upstream is down"},
+		{"empty body", func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}, http.StatusUnauthorized, "", "Unauthorized"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeServer(t, func(w http.ResponseWriter, r *http.Request, attempt int) {
+				w.Header().Set(requestIDHeader, r.Header.Get(requestIDHeader))
+				tt.handle(w)
+			})
+			_, err := server.client().CreatePR(context.Background(), "pr-1", "Add search", "u1")
+
+			apiErr, ok := err.(*Error)
+			if !ok {
+				t.Fatalf("error = %T %v, want *Error", err, err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Code != tt.code || apiErr.Message != tt.message {
+				t.Errorf("error = %+v", apiErr)
+			}
+			if ErrorCode(err) != tt.code {
+				t.Errorf("ErrorCode() = %q, want %q", ErrorCode(err), tt.code)
+			}
+			requests := server.received()
+			if apiErr.RequestID == "" || apiErr.RequestID != requests[0].Header.Get(requestIDHeader) {
+				t.Errorf("RequestID = %q, sent %q", apiErr.RequestID, requests[0].Header.Get(requestIDHeader))
+			}
+			// 4xx, кроме 429 и IDEMPOTENCY_IN_PROGRESS, не повторяются
+			if len(requests) != 1 {
+				t.Errorf("server received %d requests, want 1", len(requests))
+			}
+		})
+	}
+
+	if code := ErrorCode(fmt.Errorf("dial tcp: connection refused")); code != "" {
+		t.Errorf("ErrorCode(non-API error) = %q", code)
+	}
+	wrapped := fmt.Errorf("create: %w", &Error{StatusCode: http.StatusConflict, Code: CodePRModified})
+	if code := ErrorCode(wrapped); code != CodePRModified {
+		t.Errorf("ErrorCode(wrapped) = %q", code)
+	}
+}
+
+func TestValidationErrorDetails(t *testing.T) {
+	// Запрос отклоняет проверка по спецификации, до обработчика он не доходит
+	server := newFakeServer(t, func(w http.ResponseWriter, r *http.Request, attempt int) {
+		t.Errorf("invalid request reached the handler: %s %s", r.Method, r.URL)
+	})
+	_, err := server.client().CreatePR(context.Background(), "pr-1", "", "u1")
+
+	if ErrorCode(err) != CodeValidation {
+		t.Fatalf("error = %v, want %s", err, CodeValidation)
+	}
+	details := err.(*Error).Details
+	if len(details) == 0 {
+		t.Fatal("VALIDATION_ERROR without details")
+	}
+	found := false
+	for _, d := range details {
+		if d.In == "body" && d.Field == "pull_request_name" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("details = %+v, want an error for body pull_request_name", details)
+	}
+	if !strings.Contains(err.Error(), "pull_request_name") {
+		t.Errorf("Error() = %q does not mention the field", err.Error())
+	}
+}
+
+func TestRetries(t *testing.T) {
+	failures := []struct {
+		name string
+		fail func(w http.ResponseWriter)
+	}{
+		{"server error", func(w http.ResponseWriter) {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "database is unavailable")
+		}},
+		{"unavailable", func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}},
+		{"rate limited", func(w http.ResponseWriter) {
+			w.Header().Set("Retry-After", "1")
+			writeError(w, http.StatusTooManyRequests, "RATE_LIMITED", "too many requests")
+		}},
+		{"idempotency in progress", func(w http.ResponseWriter) {
+			writeError(w, http.StatusConflict, CodeIdempotencyInProgress, "a request with this Idempotency-Key is still in progress")
+		}},
+	}
+	// GET повторяется всегда, POST - только с ключом от вызывающего
+	calls := []struct {
+		name string
+		call func(c *Client) error
+		key  string
+	}{
+		{"GET", func(c *Client) error {
+			_, err := c.GetPR(context.Background(), "pr-1")
+			return err
+		}, ""},
+		{"POST with key", func(c *Client) error {
+			_, err := c.CreatePR(WithIdempotencyKey(context.Background(), "create-pr-1"), "pr-1", "Add search", "u1")
+			return err
+		}, "create-pr-1"},
+	}
+	for _, f := range failures {
+		for _, call := range calls {
+			t.Run(f.name+"/"+call.name, func(t *testing.T) {
+				server := newFakeServer(t, func(w http.ResponseWriter, r *http.Request, attempt int) {
+					if attempt < 3 {
+						f.fail(w)
+						return
+					}
+					writeJSON(w, http.StatusOK, map[string]interface{}{"pr": PullRequest{PullRequestID: "pr-1"}})
+				})
+
+				start := time.Now()
+				if err := call.call(server.client()); err != nil {
+					t.Fatalf("error = %v", err)
+				}
+				// Retry-After в 1 с ограничен MaxBackoff политики
+				if elapsed := time.Since(start); elapsed > time.Second/2 {
+					t.Errorf("retries took %v", elapsed)
+				}
+
+				requests := server.received()
+				if len(requests) != 3 {
+					t.Fatalf("server received %d requests, want 3", len(requests))
+				}
+				requestID := requests[0].Header.Get(requestIDHeader)
+				if requestID == "" {
+					t.Fatal("first attempt has no X-Request-ID")
+				}
+				for i, r := range requests {
+					if r.Header.Get(idempotencyHeader) != call.key {
+						t.Errorf("attempt %d: Idempotency-Key = %q, want %q", i+1, r.Header.Get(idempotencyHeader), call.key)
+					}
+					if r.Header.Get(requestIDHeader) != requestID {
+						t.Errorf("attempt %d: X-Request-ID = %q, want %q", i+1, r.Header.Get(requestIDHeader), requestID)
+					}
+					if r.Body != requests[0].Body {
+						t.Errorf("attempt %d: body = %q, want %q", i+1, r.Body, requests[0].Body)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestPOSTWithoutKeyNotRetried(t *testing.T) {
+	ctx := context.Background()
+	writes := map[string]func(c *Client) error{
+		"CreatePR": func(c *Client) error {
+			_, err := c.CreatePR(ctx, "pr-1", "Add search", "u1")
+			return err
+		},
+		"ReassignReviewer": func(c *Client) error {
+			_, err := c.ReassignReviewer(ctx, "pr-1", "u2")
+			return err
+		},
+		"RunPRBatch": func(c *Client) error {
+			_, err := c.RunPRBatch(ctx, &BatchRequest{Mode: BatchAtomic, Operations: []BatchOperation{{Op: BatchOpMerge, PullRequestID: "pr-1"}}})
+			return err
+		},
+		"CreateTeam": func(c *Client) error {
+			_, err := c.CreateTeam(ctx, &Team{TeamName: "backend", Members: []TeamMember{}})
+			return err
+		},
+	}
+	for name, write := range writes {
+		t.Run(name, func(t *testing.T) {
+			// Сервис мог применить изменение до ответа 503: повтор без ключа применил бы его второй раз
+			server := newFakeServer(t, func(w http.ResponseWriter, r *http.Request, attempt int) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			})
+			err := write(server.client())
+			if apiErr, ok := err.(*Error); !ok || apiErr.StatusCode != http.StatusServiceUnavailable {
+				t.Fatalf("error = %v, want the 503", err)
+			}
+			requests := server.received()
+			if len(requests) != 1 {
+				t.Errorf("server received %d requests, want 1", len(requests))
+			}
+			if key := requests[0].Header.Get(idempotencyHeader); key != "" {
+				t.Errorf("client invented Idempotency-Key %q", key)
+			}
+		})
+	}
+}
+
+func TestPOSTWithoutKeyNotRetriedOnNetworkError(t *testing.T) {
+	// Соединение рвётся без ответа: запрос мог дойти до сервиса
+	server := newFakeServer(t, func(w http.ResponseWriter, r *http.Request, attempt int) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conn.Close()
+	})
+	c := server.client()
+
+	if _, err := c.MergePR(context.Background(), "pr-1"); err == nil {
+		t.Fatal("MergePR() succeeded")
+	}
+	if n := len(server.received()); n != 1 {
+		t.Errorf("POST without key: server received %d requests, want 1", n)
+	}
+
+	if _, err := c.GetPR(context.Background(), "pr-1"); err == nil {
+		t.Fatal("GetPR() succeeded")
+	}
+	if n := len(server.received()) - 1; n != fastRetry.MaxAttempts {
+		t.Errorf("GET: server received %d requests, want %d", n, fastRetry.MaxAttempts)
+	}
+}
+
+func TestRetriesExhausted(t *testing.T) {
+	server := newFakeServer(t, func(w http.ResponseWriter, r *http.Request, attempt int) {
+		writeError(w, http.StatusBadGateway, CodeInternal, fmt.Sprintf("attempt %d", attempt))
+	})
+	_, err := server.client().GetPR(context.Background(), "pr-1")
+
+	if n := len(server.received()); n != fastRetry.MaxAttempts {
+		t.Errorf("server received %d requests, want %d", n, fastRetry.MaxAttempts)
+	}
+	// Возвращается ошибка последней попытки
+	apiErr, ok := err.(*Error)
+	if !ok || apiErr.StatusCode != http.StatusBadGateway || apiErr.Message != fmt.Sprintf("attempt %d", fastRetry.MaxAttempts) {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestNoRetry(t *testing.T) {
+	server := newFakeServer(t, func(w http.ResponseWriter, r *http.Request, attempt int) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	_, err := server.client(WithRetry(NoRetry)).GetPR(context.Background(), "pr-1")
+	if err == nil {
+		t.Fatal("GetPR() succeeded")
+	}
+	if n := len(server.received()); n != 1 {
+		t.Errorf("server received %d requests, want 1", n)
+	}
+}
+
+func TestRetryStopsOnContext(t *testing.T) {
+	server := newFakeServer(t, func(w http.ResponseWriter, r *http.Request, attempt int) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	slow := RetryPolicy{MaxAttempts: 10, MinBackoff: time.Minute, MaxBackoff: time.Minute}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := server.client(WithRetry(slow)).GetPR(ctx, "pr-1")
+	if err == nil {
+		t.Fatal("GetPR() succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("backoff ignored the context: returned after %v", elapsed)
+	}
+	if n := len(server.received()); n != 1 {
+		t.Errorf("server received %d requests, want 1", n)
+	}
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	server := newFakeServer(t, func(w http.ResponseWriter, r *http.Request, attempt int) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"pr": PullRequest{PullRequestID: "pr-1"}})
+	})
+	c := server.client()
+
+	ctx := WithIdempotencyKey(context.Background(), "deploy-42-merge")
+	if _, err := c.MergePR(ctx, "pr-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.MergePR(context.Background(), "pr-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetPR(ctx, "pr-1"); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.Do(context.Background(), &Request{
+		Method: "POST", Path: "/pullRequest/merge",
+		Header: http.Header{idempotencyHeader: {"explicit"}},
+		Body:   []byte(`{"pull_request_id":"pr-1"}`), ContentType: "application/json",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	requests := server.received()
+	want := []string{"deploy-42-merge", "", "", "explicit"}
+	for i, key := range want {
+		if got := requests[i].Header.Get(idempotencyHeader); got != key {
+			t.Errorf("%s %s: Idempotency-Key = %q, want %q", requests[i].Method, requests[i].Path, got, key)
+		}
+	}
+}
+
+func TestCredentials(t *testing.T) {
+	tests := []struct {
+		token, bearer, apiKey string
+	}{
+		{"eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiJ1MSJ9.c2ln", "Bearer eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiJ1MSJ9.c2ln", ""},
+		{"prk_3f9c2a", "", "prk_3f9c2a"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		server := newFakeServer(t, func(w http.ResponseWriter, r *http.Request, attempt int) {
+			w.WriteHeader(http.StatusOK)
+		})
+		if err := server.client(WithToken(tt.token)).Health(context.Background()); err != nil {
+			t.Fatalf("Health() error = %v", err)
+		}
+		header := server.received()[0].Header
+		if header.Get("Authorization") != tt.bearer || header.Get("X-API-Key") != tt.apiKey {
+			t.Errorf("token %q: Authorization = %q, X-API-Key = %q", tt.token,
+				header.Get("Authorization"), header.Get("X-API-Key"))
+		}
+	}
+}
+
+func TestWatchReviewsReconnects(t *testing.T) {
+	server := newFakeServer(t, func(w http.ResponseWriter, r *http.Request, attempt int) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		switch attempt {
+		case 1:
+			// Первое подключение обрывается после одного изменения
+			fmt.Fprint(w, "retry: 1\n\n")
+			fmt.Fprint(w, "id: 10\nevent: snapshot\ndata: {\"pull_requests\":[{\"pull_request_id\":\"pr-1\",\"status\":\"OPEN\"}]}\n\n")
+			fmt.Fprint(w, ": keepalive\n\n")
+			fmt.Fprint(w, "id: 11\nevent: assigned\ndata: {\"event_id\":11,\"kind\":\"assigned\",\"pull_request_id\":\"pr-2\",\"user_id\":\"u2\"}\n\n")
+		default:
+			fmt.Fprint(w, "id: 12\nevent: merged\ndata: {\"event_id\":12,\"kind\":\"merged\",\"pull_request_id\":\"pr-1\",\"user_id\":\"u2\"}\n\n")
+		}
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := server.client().WatchReviews(ctx, "u2", "")
+	if err != nil {
+		t.Fatalf("WatchReviews() error = %v", err)
+	}
+	defer stream.Close()
+
+	want := []struct{ id, typ, pr string }{
+		{"10", EventSnapshot, "pr-1"},
+		{"11", EventAssigned, "pr-2"},
+		{"12", EventMerged, "pr-1"},
+	}
+	for _, w := range want {
+		event, err := stream.Next()
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		pr := ""
+		if len(event.Snapshot) == 1 {
+			pr = event.Snapshot[0].PullRequestID
+		} else if event.Change != nil {
+			pr = event.Change.PullRequestID
+		}
+		if event.ID != w.id || event.Type != w.typ || pr != w.pr {
+			t.Errorf("event = %+v, want %s %s %s", event, w.id, w.typ, w.pr)
+		}
+	}
+	if stream.LastEventID() != "12" {
+		t.Errorf("LastEventID() = %q", stream.LastEventID())
+	}
+
+	requests := server.received()
+	if len(requests) < 2 {
+		t.Fatalf("server received %d requests, want a reconnect", len(requests))
+	}
+	if requests[0].Query.Get("user_id") != "u2" || requests[0].Header.Get("Last-Event-ID") != "" {
+		t.Errorf("first connection: query %v, Last-Event-ID %q", requests[0].Query, requests[0].Header.Get("Last-Event-ID"))
+	}
+	if got := requests[1].Header.Get("Last-Event-ID"); got != "11" {
+		t.Errorf("reconnect Last-Event-ID = %q, want 11", got)
+	}
+
+	stream.Close()
+	if _, err := stream.Next(); err != context.Canceled {
+		t.Errorf("Next() after Close = %v, want context.Canceled", err)
+	}
+}
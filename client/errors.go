@@ -0,0 +1,111 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Коды ошибок сервиса из поля error.code
+const (
+	CodeValidation            = "VALIDATION_ERROR"
+	CodeInvalidParam          = "INVALID_PARAM"
+	CodeInvalidCursor         = "INVALID_CURSOR"
+	CodeInvalidRequest        = "INVALID_REQUEST"
+	CodeInvalidName           = "INVALID_NAME"
+	CodeInvalidRole           = "INVALID_ROLE"
+	CodeInvalidSLA            = "INVALID_SLA"
+	CodeInvalidReminder       = "INVALID_REMINDER"
+	CodeInvalidBatch          = "INVALID_BATCH"
+	CodeInvalidBundle         = "INVALID_BUNDLE"
+	CodeInvalidArchive        = "INVALID_ARCHIVE"
+	CodeInvalidIdempotencyKey = "INVALID_IDEMPOTENCY_KEY"
+	CodeRequestTooLarge       = "REQUEST_TOO_LARGE"
+	CodeNotAcceptable         = "NOT_ACCEPTABLE"
+	CodeTeamRequired          = "TEAM_REQUIRED"
+	CodeUnauthorized          = "UNAUTHORIZED"
+	CodeForbidden             = "FORBIDDEN"
+	CodeNotFound              = "NOT_FOUND"
+	CodeTeamExists            = "TEAM_EXISTS"
+	CodePRExists              = "PR_EXISTS"
+	CodeKeyExists             = "KEY_EXISTS"
+	CodeNotEmpty              = "NOT_EMPTY"
+	CodePRMerged              = "PR_MERGED"
+	CodeNotAssigned           = "NOT_ASSIGNED"
+	CodeNoCandidate           = "NO_CANDIDATE"
+	CodePRModified            = "PR_MODIFIED"
+	CodeIdempotencyKeyReused  = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyInProgress = "IDEMPOTENCY_IN_PROGRESS"
+	CodeIdempotencyDisabled   = "IDEMPOTENCY_DISABLED"
+	CodeInternal              = "INTERNAL"
+)
+
+// Error - ответ сервиса со статусом 4xx или 5xx
+type Error struct {
+	StatusCode int
+	// Code - один из Code*; пуст для ответов вида {"error": "сообщение"}
+	Code    string
+	Message string
+	// Details - ошибки отдельных полей при VALIDATION_ERROR
+	Details []FieldError
+	// RequestID - X-Request-ID запроса, по нему изменение ищется в журнале аудита
+	RequestID string
+
+	retryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	if e.Code != "" {
+		b.WriteString(e.Code + ": ")
+	}
+	fmt.Fprintf(&b, "%s (HTTP %d)", e.Message, e.StatusCode)
+	for _, d := range e.Details {
+		fmt.Fprintf(&b, "\n  %s %s: %s", d.In, d.Field, d.Message)
+	}
+	return b.String()
+}
+
+// ErrorCode возвращает код ошибки сервиса или пустую строку, если err не ответ сервиса
+func ErrorCode(err error) string {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+	return ""
+}
+
+// decodeError разбирает оба вида ошибок сервиса: {"error": {"code", "message", "details"}} и {"error": "сообщение"}
+func decodeError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	e := &Error{
+		StatusCode: resp.StatusCode,
+		Message:    strings.TrimSpace(string(data)),
+		RequestID:  resp.Header.Get(requestIDHeader),
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		e.retryAfter = time.Duration(seconds) * time.Second
+	}
+
+	var envelope struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(data, &envelope) == nil && len(envelope.Error) > 0 {
+		var detailed ErrorResponse
+		var plain string
+		if json.Unmarshal(data, &detailed) == nil && detailed.Error.Code != "" {
+			e.Code, e.Message, e.Details = detailed.Error.Code, detailed.Error.Message, detailed.Error.Details
+		} else if json.Unmarshal(envelope.Error, &plain) == nil {
+			e.Message = plain
+		}
+	}
+	if e.Message == "" {
+		e.Message = http.StatusText(resp.StatusCode)
+	}
+	return e
+}
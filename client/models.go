@@ -0,0 +1,103 @@
+package client
+
+import "pr-reviewer-service/internal/models"
+
+// Типы сервиса; псевдонимы, а не копии, чтобы изменение модели сразу было видно клиентам
+type (
+	Team               = models.Team
+	TeamMember         = models.TeamMember
+	TeamSummary        = models.TeamSummary
+	TeamSLA            = models.TeamSLA
+	User               = models.User
+	PullRequest        = models.PullRequest
+	PullRequestShort   = models.PullRequestShort
+	PullRequestPage    = models.PRListPage
+	ReviewPage         = models.ReviewPage
+	PREvent            = models.PREvent
+	ReviewStreamEvent  = models.ReviewStreamEvent
+	ReminderSettings   = models.ReminderSettings
+	SLABreach          = models.SLABreach
+	Stats              = models.Stats
+	FairnessReport     = models.FairnessReport
+	MemberFairness     = models.MemberFairness
+	StatsSeries        = models.StatsSeries
+	StatsPoint         = models.StatsPoint
+	AuditEntry         = models.AuditEntry
+	APIKey             = models.APIKey
+	ChatIdentity       = models.ChatIdentity
+	ImportBundle       = models.ImportBundle
+	ImportReport       = models.ImportReport
+	ImportError        = models.ImportError
+	StateArchive       = models.StateArchive
+	StateRestoreReport = models.StateRestoreReport
+	BatchRequest       = models.PRBatchRequest
+	BatchOperation     = models.PRBatchOperation
+	BatchReport        = models.PRBatchReport
+	BatchResult        = models.PRBatchResult
+	ErrorResponse      = models.ErrorResponse
+	FieldError         = models.FieldError
+)
+
+const (
+	StatusOpen   = "OPEN"
+	StatusMerged = "MERGED"
+
+	BatchAtomic     = models.BatchAtomic
+	BatchBestEffort = models.BatchBestEffort
+	BatchOpCreate   = models.BatchOpCreate
+	BatchOpMerge    = models.BatchOpMerge
+	BatchOpReassign = models.BatchOpReassign
+)
+
+// Ответы, у которых нет своей модели в сервисе
+
+type TeamPage struct {
+	Teams      []TeamSummary `json:"teams"`
+	NextCursor string        `json:"next_cursor"`
+}
+
+type UserPage struct {
+	Users      []User `json:"users"`
+	NextCursor string `json:"next_cursor"`
+}
+
+type AuditPage struct {
+	Entries    []AuditEntry `json:"entries"`
+	NextCursor string       `json:"next_cursor"`
+}
+
+// Reassignment - результат замены ревьювера; ReplacedBy - новый ревьювер
+type Reassignment struct {
+	PR         *PullRequest `json:"pr"`
+	ReplacedBy string       `json:"replaced_by"`
+}
+
+// NewAPIKey - выпущенный ключ; Key показывается только один раз
+type NewAPIKey struct {
+	APIKey *APIKey `json:"api_key"`
+	Key    string  `json:"key"`
+}
+
+// endpoint - операция API, которую вызывает метод клиента
+type endpoint struct {
+	method string
+	path   string
+}
+
+var endpoints []endpoint
+
+func route(method, path string) endpoint {
+	e := endpoint{method: method, path: path}
+	endpoints = append(endpoints, e)
+	return e
+}
+
+// Endpoints возвращает операции API, у которых есть методы клиента, в виде "POST /team/add".
+// По этому списку check-openapi находит операции спецификации без метода.
+func Endpoints() []string {
+	list := make([]string, len(endpoints))
+	for i, e := range endpoints {
+		list[i] = e.method + " " + e.path
+	}
+	return list
+}
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	prCreate   = route("POST", "/pullRequest/create")
+	prMerge    = route("POST", "/pullRequest/merge")
+	prReassign = route("POST", "/pullRequest/reassign")
+	prGet      = route("GET", "/pullRequest/get")
+	prTimeline = route("GET", "/pullRequest/timeline")
+	prList     = route("GET", "/pullRequest/list")
+	prBatch    = route("POST", "/pullRequest/batch")
+)
+
+// PRListOptions - фильтр поиска и выгрузки PR; пустые поля не ограничивают выборку
+type PRListOptions struct {
+	TeamName   string
+	AuthorID   string
+	ReviewerID string
+	// NameContains - подстрока названия, Search - полнотекстовый поиск по названию
+	NameContains string
+	Search       string
+	Statuses     []string
+	// MinAge, MaxAge - возраст PR от момента создания
+	MinAge      time.Duration
+	MaxAge      time.Duration
+	NoReviewers bool
+	OverdueOnly bool
+	Sort        string
+	Order       string
+	ListOptions
+}
+
+func (o PRListOptions) encode(q url.Values) {
+	setString(q, "team_name", o.TeamName)
+	setString(q, "author_id", o.AuthorID)
+	setString(q, "reviewer_id", o.ReviewerID)
+	setString(q, "name", o.NameContains)
+	setString(q, "q", o.Search)
+	setString(q, "status", strings.Join(o.Statuses, ","))
+	if o.MinAge > 0 {
+		q.Set("min_age", o.MinAge.String())
+	}
+	if o.MaxAge > 0 {
+		q.Set("max_age", o.MaxAge.String())
+	}
+	if o.NoReviewers {
+		q.Set("no_reviewers", "true")
+	}
+	if o.OverdueOnly {
+		q.Set("overdue", "true")
+	}
+	setString(q, "sort", o.Sort)
+	setString(q, "order", o.Order)
+	o.ListOptions.encode(q)
+}
+
+// CreatePR создаёт PR и назначает до двух ревьюверов из команды автора
+func (c *Client) CreatePR(ctx context.Context, id, name, authorID string) (*PullRequest, error) {
+	body := map[string]string{"pull_request_id": id, "pull_request_name": name, "author_id": authorID}
+	var resp struct {
+		PR *PullRequest `json:"pr"`
+	}
+	if err := c.call(ctx, prCreate, nil, body, &resp); err != nil {
+		return nil, err
+	}
+	return resp.PR, nil
+}
+
+// MergePR помечает PR как MERGED; повторный вызов для слитого PR возвращает его же
+func (c *Client) MergePR(ctx context.Context, id string) (*PullRequest, error) {
+	var resp struct {
+		PR *PullRequest `json:"pr"`
+	}
+	if err := c.call(ctx, prMerge, nil, map[string]string{"pull_request_id": id}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.PR, nil
+}
+
+// ReassignReviewer заменяет ревьювера oldUserID другим активным участником его команды
+func (c *Client) ReassignReviewer(ctx context.Context, id, oldUserID string) (*Reassignment, error) {
+	body := map[string]string{"pull_request_id": id, "old_user_id": oldUserID}
+	var resp Reassignment
+	if err := c.call(ctx, prReassign, nil, body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *Client) GetPR(ctx context.Context, id string) (*PullRequest, error) {
+	var resp struct {
+		PR *PullRequest `json:"pr"`
+	}
+	if err := c.call(ctx, prGet, url.Values{"pull_request_id": {id}}, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.PR, nil
+}
+
+// GetPRTimeline возвращает историю PR от старых событий к новым
+func (c *Client) GetPRTimeline(ctx context.Context, id string) ([]PREvent, error) {
+	var resp struct {
+		Events []PREvent `json:"events"`
+	}
+	if err := c.call(ctx, prTimeline, url.Values{"pull_request_id": {id}}, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Events, nil
+}
+
+// ListPRs возвращает страницу PR по фильтру; выбор полей (fields) не поддерживается, PR всегда полные
+func (c *Client) ListPRs(ctx context.Context, opts PRListOptions) (*PullRequestPage, error) {
+	q := url.Values{}
+	opts.encode(q)
+	var page PullRequestPage
+	if err := c.call(ctx, prList, q, nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// RunPRBatch выполняет пакет операций. Отклонённый атомарный пакет - не ошибка:
+// отчёт возвращается с Failed > 0 и результатами по каждой операции.
+func (c *Client) RunPRBatch(ctx context.Context, batch *BatchRequest) (*BatchReport, error) {
+	var report BatchReport
+	if err := c.call(ctx, prBatch, nil, batch, &report, http.StatusUnprocessableEntity); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
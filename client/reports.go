@@ -0,0 +1,171 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+var (
+	statsGet          = route("GET", "/stats")
+	statsFairness     = route("GET", "/stats/fairness")
+	statsHistory      = route("GET", "/stats/history")
+	exportPRs         = route("GET", "/export/pull_requests")
+	exportAssignments = route("GET", "/export/assignments")
+	exportStats       = route("GET", "/export/stats")
+	auditList         = route("GET", "/audit")
+	auditExport       = route("GET", "/audit/export")
+)
+
+// Форматы выгрузок /export/*
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// StatsOptions - команда автора и интервал создания PR; пустые поля не ограничивают
+type StatsOptions struct {
+	TeamName string
+	From     time.Time
+	To       time.Time
+}
+
+func (o StatsOptions) encode(q url.Values) {
+	setString(q, "team_name", o.TeamName)
+	setTime(q, "from", o.From)
+	setTime(q, "to", o.To)
+}
+
+type FairnessOptions struct {
+	From time.Time
+	To   time.Time
+	// Tolerance - допустимое относительное отклонение; nil - значение сервера (0.25)
+	Tolerance *float64
+}
+
+// HistoryOptions - ряд метрик пользователя (UserID), команды (TeamName) или всего сервиса
+type HistoryOptions struct {
+	UserID   string
+	TeamName string
+	// Bucket - hour, day (по умолчанию) или week
+	Bucket string
+	From   time.Time
+	To     time.Time
+}
+
+type AssignmentExportOptions struct {
+	TeamName      string
+	ReviewerID    string
+	PullRequestID string
+	From          time.Time
+	To            time.Time
+}
+
+type AuditListOptions struct {
+	Actor      string
+	Action     string
+	TargetType string
+	TargetID   string
+	RequestID  string
+	From       time.Time
+	To         time.Time
+	ListOptions
+}
+
+func (o AuditListOptions) encode(q url.Values) {
+	setString(q, "actor", o.Actor)
+	setString(q, "action", o.Action)
+	setString(q, "target_type", o.TargetType)
+	setString(q, "target_id", o.TargetID)
+	setString(q, "request_id", o.RequestID)
+	setTime(q, "from", o.From)
+	setTime(q, "to", o.To)
+	o.ListOptions.encode(q)
+}
+
+func (c *Client) GetStats(ctx context.Context, opts StatsOptions) (*Stats, error) {
+	q := url.Values{}
+	opts.encode(q)
+	var stats Stats
+	if err := c.call(ctx, statsGet, q, nil, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// GetFairness показывает, насколько равномерно распределены ревью в команде
+func (c *Client) GetFairness(ctx context.Context, teamName string, opts FairnessOptions) (*FairnessReport, error) {
+	q := url.Values{"team_name": {teamName}}
+	setTime(q, "from", opts.From)
+	setTime(q, "to", opts.To)
+	if opts.Tolerance != nil {
+		q.Set("tolerance", strconv.FormatFloat(*opts.Tolerance, 'f', -1, 64))
+	}
+	var report FairnessReport
+	if err := c.call(ctx, statsFairness, q, nil, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (c *Client) GetStatsHistory(ctx context.Context, opts HistoryOptions) (*StatsSeries, error) {
+	q := url.Values{}
+	setString(q, "user_id", opts.UserID)
+	setString(q, "team_name", opts.TeamName)
+	setString(q, "bucket", opts.Bucket)
+	setTime(q, "from", opts.From)
+	setTime(q, "to", opts.To)
+	var series StatsSeries
+	if err := c.call(ctx, statsHistory, q, nil, &series); err != nil {
+		return nil, err
+	}
+	return &series, nil
+}
+
+// ExportPullRequests выгружает PR в FormatCSV или FormatNDJSON. Выгрузка потоковая: тело закрывает вызывающий.
+func (c *Client) ExportPullRequests(ctx context.Context, format string, opts PRListOptions) (io.ReadCloser, error) {
+	q := url.Values{}
+	setString(q, "format", format)
+	opts.encode(q)
+	return c.stream(ctx, exportPRs, q)
+}
+
+func (c *Client) ExportAssignments(ctx context.Context, format string, opts AssignmentExportOptions) (io.ReadCloser, error) {
+	q := url.Values{}
+	setString(q, "format", format)
+	setString(q, "team_name", opts.TeamName)
+	setString(q, "reviewer_id", opts.ReviewerID)
+	setString(q, "pull_request_id", opts.PullRequestID)
+	setTime(q, "from", opts.From)
+	setTime(q, "to", opts.To)
+	return c.stream(ctx, exportAssignments, q)
+}
+
+// ExportStats выгружает таблицу статистики: reviewers (по умолчанию), summary или open_age
+func (c *Client) ExportStats(ctx context.Context, format, table string, opts StatsOptions) (io.ReadCloser, error) {
+	q := url.Values{}
+	setString(q, "format", format)
+	setString(q, "table", table)
+	opts.encode(q)
+	return c.stream(ctx, exportStats, q)
+}
+
+// ListAudit возвращает страницу журнала аудита от новых записей к старым
+func (c *Client) ListAudit(ctx context.Context, opts AuditListOptions) (*AuditPage, error) {
+	q := url.Values{}
+	opts.encode(q)
+	var page AuditPage
+	if err := c.call(ctx, auditList, q, nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// ExportAudit выгружает подходящие записи журнала в NDJSON, без Limit - все
+func (c *Client) ExportAudit(ctx context.Context, opts AuditListOptions) (io.ReadCloser, error) {
+	q := url.Values{}
+	opts.encode(q)
+	return c.stream(ctx, auditExport, q)
+}
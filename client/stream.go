@@ -0,0 +1,180 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"pr-reviewer-service/internal/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var reviewStream = route("GET", "/users/reviews/stream")
+
+// Типы событий потока ревью
+const (
+	EventSnapshot   = "snapshot"
+	EventAssigned   = models.ReviewerAssigned
+	EventUnassigned = models.ReviewerUnassigned
+	EventMerged     = models.ReviewerPRMerged
+)
+
+// ReviewEvent - событие потока ревью пользователя
+type ReviewEvent struct {
+	ID   string
+	Type string
+	// Snapshot - открытые ревью на момент подключения, только для EventSnapshot
+	Snapshot []PullRequestShort
+	// Change - назначение, снятие или слияние PR для остальных типов
+	Change *ReviewStreamEvent
+}
+
+// ReviewStream читает /users/reviews/stream. При обрыве Next переподключается с Last-Event-ID,
+// поэтому события не теряются и snapshot повторно не приходит.
+type ReviewStream struct {
+	c      *Client
+	ctx    context.Context
+	cancel context.CancelFunc
+	userID string
+	lastID string
+	// retry - пауза перед переподключением, сервер присылает её в поле retry
+	retry time.Duration
+
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+}
+
+// WatchReviews подписывается на ревью пользователя. С пустым lastEventID поток начинается с EventSnapshot,
+// иначе продолжается после этого события. Поток работает, пока не отменён ctx или не вызван Close.
+func (c *Client) WatchReviews(ctx context.Context, userID, lastEventID string) (*ReviewStream, error) {
+	s := &ReviewStream{c: c, userID: userID, lastID: lastEventID, retry: 5 * time.Second}
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	if err := s.connect(); err != nil {
+		s.cancel()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *ReviewStream) connect() error {
+	req := &Request{Method: reviewStream.method, Path: reviewStream.path, Query: url.Values{"user_id": {s.userID}}}
+	if s.lastID != "" {
+		req.Header = http.Header{"Last-Event-ID": {s.lastID}}
+	}
+	resp, err := s.c.Do(s.ctx, req)
+	if err != nil {
+		return err
+	}
+	s.body = resp.Body
+	s.scanner = bufio.NewScanner(resp.Body)
+	s.scanner.Buffer(make([]byte, 64<<10), 4<<20)
+	return nil
+}
+
+// Next ждёт следующее событие. Ошибка - отмена контекста, Close или ответ сервиса 4xx при переподключении.
+func (s *ReviewStream) Next() (*ReviewEvent, error) {
+	for {
+		if s.body != nil {
+			if event, ok := s.read(); ok {
+				return event, nil
+			}
+			s.body.Close()
+			s.body = nil
+		}
+		if err := s.ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		timer := time.NewTimer(s.retry)
+		select {
+		case <-timer.C:
+		case <-s.ctx.Done():
+			timer.Stop()
+			return nil, s.ctx.Err()
+		}
+		if err := s.connect(); err != nil {
+			var apiErr *Error
+			if errors.As(err, &apiErr) && apiErr.StatusCode < 500 {
+				return nil, err
+			}
+			if s.ctx.Err() != nil {
+				return nil, s.ctx.Err()
+			}
+		}
+	}
+}
+
+// LastEventID - id последнего полученного события; с ним поток можно продолжить в другом процессе
+func (s *ReviewStream) LastEventID() string {
+	return s.lastID
+}
+
+// Close останавливает поток; его можно вызвать из другой горутины, тогда Next вернёт context.Canceled
+func (s *ReviewStream) Close() error {
+	s.cancel()
+	return nil
+}
+
+// read разбирает события SSE до следующего полного; false - поток закончился.
+// События, которые не удалось разобрать, пропускаются.
+func (s *ReviewStream) read() (*ReviewEvent, bool) {
+	var id, event string
+	var data []string
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+		if line == "" {
+			if len(data) == 0 {
+				continue
+			}
+			if e, err := decodeReviewEvent(id, event, strings.Join(data, "\n")); err == nil {
+				s.lastID = id
+				return e, true
+			}
+			id, event, data = "", "", nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			id = value
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms > 0 {
+				s.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+	return nil, false
+}
+
+func decodeReviewEvent(id, event, data string) (*ReviewEvent, error) {
+	e := &ReviewEvent{ID: id, Type: event}
+	if event == EventSnapshot {
+		var snapshot struct {
+			PullRequests []PullRequestShort `json:"pull_requests"`
+		}
+		if err := json.Unmarshal([]byte(data), &snapshot); err != nil {
+			return nil, fmt.Errorf("snapshot event %s: %w", id, err)
+		}
+		e.Snapshot = snapshot.PullRequests
+		return e, nil
+	}
+	e.Change = &ReviewStreamEvent{}
+	if err := json.Unmarshal([]byte(data), e.Change); err != nil {
+		return nil, fmt.Errorf("%s event %s: %w", event, id, err)
+	}
+	return e, nil
+}
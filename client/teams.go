@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"net/url"
+)
+
+var (
+	teamAdd    = route("POST", "/team/add")
+	teamGet    = route("GET", "/team/get")
+	teamList   = route("GET", "/team/list")
+	teamSLA    = route("GET", "/team/sla")
+	teamSLASet = route("POST", "/team/sla/set")
+	slaBreach  = route("GET", "/sla/breaches")
+)
+
+// ListOptions - страница списка; Cursor - NextCursor предыдущей страницы
+type ListOptions struct {
+	Limit  int
+	Cursor string
+}
+
+func (o ListOptions) encode(q url.Values) {
+	setInt(q, "limit", o.Limit)
+	setString(q, "cursor", o.Cursor)
+}
+
+// CreateTeam создаёт команду и её участников; существующие пользователи переходят в неё
+func (c *Client) CreateTeam(ctx context.Context, team *Team) (*Team, error) {
+	var resp struct {
+		Team *Team `json:"team"`
+	}
+	if err := c.call(ctx, teamAdd, nil, team, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Team, nil
+}
+
+func (c *Client) GetTeam(ctx context.Context, teamName string) (*Team, error) {
+	var team Team
+	if err := c.call(ctx, teamGet, url.Values{"team_name": {teamName}}, nil, &team); err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+func (c *Client) ListTeams(ctx context.Context, opts ListOptions) (*TeamPage, error) {
+	q := url.Values{}
+	opts.encode(q)
+	var page TeamPage
+	if err := c.call(ctx, teamList, q, nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+func (c *Client) GetTeamSLA(ctx context.Context, teamName string) (*TeamSLA, error) {
+	var resp struct {
+		SLA *TeamSLA `json:"sla"`
+	}
+	if err := c.call(ctx, teamSLA, url.Values{"team_name": {teamName}}, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.SLA, nil
+}
+
+func (c *Client) SetTeamSLA(ctx context.Context, sla *TeamSLA) (*TeamSLA, error) {
+	var resp struct {
+		SLA *TeamSLA `json:"sla"`
+	}
+	if err := c.call(ctx, teamSLASet, nil, sla, &resp); err != nil {
+		return nil, err
+	}
+	return resp.SLA, nil
+}
+
+// ListSLABreaches возвращает нарушения SLA; teamName может быть пустым, openOnly - только нерешённые
+func (c *Client) ListSLABreaches(ctx context.Context, teamName string, openOnly bool) ([]SLABreach, error) {
+	q := url.Values{}
+	setString(q, "team_name", teamName)
+	setBool(q, "open", openOnly)
+	var resp struct {
+		Breaches []SLABreach `json:"breaches"`
+	}
+	if err := c.call(ctx, slaBreach, q, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Breaches, nil
+}
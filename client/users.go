@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	userGet          = route("GET", "/users/get")
+	userList         = route("GET", "/users/list")
+	userSetActive    = route("POST", "/users/setIsActive")
+	userReviews      = route("GET", "/users/getReview")
+	userReminders    = route("GET", "/users/reminders")
+	userRemindersSet = route("POST", "/users/reminders/set")
+)
+
+type UserListOptions struct {
+	TeamName string
+	// IsActive - nil для всех пользователей
+	IsActive *bool
+	// UsernamePrefix - начало имени без учёта регистра
+	UsernamePrefix string
+	ListOptions
+}
+
+// ReviewListOptions - фильтр PR, где пользователь ревьювер
+type ReviewListOptions struct {
+	// Statuses - StatusOpen, StatusMerged; пусто - любые
+	Statuses      []string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	// Sort - created_at (по умолчанию) или age, Order - asc или desc
+	Sort  string
+	Order string
+	ListOptions
+}
+
+func (c *Client) GetUser(ctx context.Context, userID string) (*User, error) {
+	var resp struct {
+		User *User `json:"user"`
+	}
+	if err := c.call(ctx, userGet, url.Values{"user_id": {userID}}, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.User, nil
+}
+
+func (c *Client) ListUsers(ctx context.Context, opts UserListOptions) (*UserPage, error) {
+	q := url.Values{}
+	setString(q, "team_name", opts.TeamName)
+	if opts.IsActive != nil {
+		q.Set("is_active", strconv.FormatBool(*opts.IsActive))
+	}
+	setString(q, "username", opts.UsernamePrefix)
+	opts.encode(q)
+	var page UserPage
+	if err := c.call(ctx, userList, q, nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// SetUserActive включает или выключает назначение пользователя ревьювером
+func (c *Client) SetUserActive(ctx context.Context, userID string, active bool) (*User, error) {
+	body := map[string]interface{}{"user_id": userID, "is_active": active}
+	var resp struct {
+		User *User `json:"user"`
+	}
+	if err := c.call(ctx, userSetActive, nil, body, &resp); err != nil {
+		return nil, err
+	}
+	return resp.User, nil
+}
+
+// GetUserReviews возвращает страницу PR, где пользователь назначен ревьювером
+func (c *Client) GetUserReviews(ctx context.Context, userID string, opts ReviewListOptions) (*ReviewPage, error) {
+	q := url.Values{"user_id": {userID}}
+	setString(q, "status", strings.Join(opts.Statuses, ","))
+	setTime(q, "created_after", opts.CreatedAfter)
+	setTime(q, "created_before", opts.CreatedBefore)
+	setString(q, "sort", opts.Sort)
+	setString(q, "order", opts.Order)
+	opts.encode(q)
+	var page ReviewPage
+	if err := c.call(ctx, userReviews, q, nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+func (c *Client) GetReminders(ctx context.Context, userID string) (*ReminderSettings, error) {
+	var resp struct {
+		Reminders *ReminderSettings `json:"reminders"`
+	}
+	if err := c.call(ctx, userReminders, url.Values{"user_id": {userID}}, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Reminders, nil
+}
+
+func (c *Client) SetReminders(ctx context.Context, settings *ReminderSettings) (*ReminderSettings, error) {
+	var resp struct {
+		Reminders *ReminderSettings `json:"reminders"`
+	}
+	if err := c.call(ctx, userRemindersSet, nil, settings, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Reminders, nil
+}
+
+func setString(q url.Values, name, value string) {
+	if value != "" {
+		q.Set(name, value)
+	}
+}
+
+func setInt(q url.Values, name string, value int) {
+	if value > 0 {
+		q.Set(name, strconv.Itoa(value))
+	}
+}
+
+// setBool передаёт значение всегда: у флагов вроде open своё значение по умолчанию на сервере
+func setBool(q url.Values, name string, value bool) {
+	q.Set(name, strconv.FormatBool(value))
+}
+
+func setTime(q url.Values, name string, value time.Time) {
+	if !value.IsZero() {
+		q.Set(name, value.Format(time.RFC3339))
+	}
+}
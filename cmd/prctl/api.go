@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"pr-reviewer-service/client"
+)
+
+// conn - адрес сервиса и учётные данные выбранного профиля
+type conn struct {
+	server string
+	token  string
+	api    *client.Client
+}
+
+// do выполняет запрос через client: с его повторами, Idempotency-Key для POST и разбором ошибок в *client.Error
+func (c *conn) do(ctx context.Context, req *client.Request) (*http.Response, error) {
+	return c.api.Do(ctx, req)
+}
+
+func newConn(server, token, actor string) *conn {
+	return &conn{
+		server: server,
+		token:  token,
+		// Без общего таймаута: review watch держит поток сколько угодно, обычные запросы ограничены --timeout
+		api: client.New(server, client.WithToken(token), client.WithActor(actor)),
+	}
+}
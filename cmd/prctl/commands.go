@@ -0,0 +1,552 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"pr-reviewer-service/client"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type paramKind int
+
+const (
+	// kindString передаётся как есть
+	kindString paramKind = iota
+	// kindBool - булево поле JSON-тела, значение флага true или false
+	kindBool
+	// kindSwitch - флаг без значения, передаётся как true
+	kindSwitch
+)
+
+// param - флаг команды и куда он попадает в запросе: query, body (поле JSON) или header
+type param struct {
+	flag     string
+	name     string
+	in       string
+	kind     paramKind
+	required bool
+	help     string
+}
+
+type command struct {
+	name   string
+	help   string
+	method string
+	path   string
+	params []param
+	// arg - флаг, значение которого можно передать позиционно: prctl pr get PR-1
+	arg string
+	// file - тело запроса из -f FILE: YAML или JSON, для admin import также CSV
+	file bool
+	// table - колонки для -o table; без неё объект выводится парами поле/значение
+	table *tableSpec
+	// raw - ответ копируется в stdout как есть (CSV, NDJSON, архив состояния)
+	raw bool
+	// stream - поток Server-Sent Events
+	stream bool
+}
+
+func query(flag, name, help string) param {
+	return param{flag: flag, name: name, in: "query", help: help}
+}
+
+func body(flag, name, help string) param {
+	return param{flag: flag, name: name, in: "body", help: help}
+}
+
+func switchParam(flag, name, help string) param {
+	return param{flag: flag, name: name, in: "query", kind: kindSwitch, help: help}
+}
+
+func required(p param) param {
+	p.required = true
+	return p
+}
+
+var idempotencyKey = param{flag: "idempotency-key", name: "Idempotency-Key", in: "header", help: "repeat with the same key to get the stored response instead of a second change"}
+
+var (
+	prColumns     = []string{"pull_request_id", "pull_request_name", "author_id", "status", "assigned_reviewers", "createdAt", "mergedAt", "overdue"}
+	memberColumns = []string{"user_id", "username", "is_active"}
+	userColumns   = []string{"user_id", "username", "team_name", "is_active"}
+)
+
+var prFilters = []param{
+	query("team", "team_name", "author's team"),
+	query("author", "author_id", "author user_id"),
+	query("reviewer", "reviewer_id", "assigned reviewer user_id"),
+	query("name", "name", "substring of the PR name"),
+	query("search", "q", "full-text search in the PR name"),
+	query("status", "status", "OPEN, MERGED or both separated by comma"),
+	query("min-age", "min_age", "created at least this long ago, e.g. 48h"),
+	query("max-age", "max_age", "created at most this long ago"),
+	switchParam("no-reviewers", "no_reviewers", "only PRs without reviewers"),
+	switchParam("overdue", "overdue", "only PRs with an SLA breach"),
+	query("sort", "sort", "sort field"),
+	query("order", "order", "asc or desc"),
+}
+
+var auditFilters = []param{
+	query("actor", "actor", "who made the change"),
+	query("action", "action", "action, e.g. pr.reassign"),
+	query("target-type", "target_type", "team, user, pull_request, ..."),
+	query("target-id", "target_id", "target id"),
+	query("request-id", "request_id", "X-Request-ID of the change"),
+	query("from", "from", "RFC 3339 start"),
+	query("to", "to", "RFC 3339 end"),
+	query("limit", "limit", "page size"),
+	query("cursor", "cursor", "next_cursor of the previous page"),
+}
+
+func with(params []param, extra ...param) []param {
+	return append(append([]param{}, params...), extra...)
+}
+
+var commandList = []*command{
+	{name: "health", help: "check that the service is up", method: "GET", path: "/health", raw: true},
+
+	{name: "team add", help: "create a team with members from -f FILE", method: "POST", path: "/team/add", file: true,
+		table: &tableSpec{list: "team.members", columns: memberColumns}},
+	{name: "team get", help: "show a team and its members", method: "GET", path: "/team/get", arg: "team",
+		params: []param{required(query("team", "team_name", "team name"))},
+		table:  &tableSpec{list: "members", columns: memberColumns}},
+	{name: "team list", help: "list teams", method: "GET", path: "/team/list",
+		params: []param{query("limit", "limit", "page size"), query("cursor", "cursor", "next_cursor of the previous page")},
+		table:  &tableSpec{list: "teams", columns: []string{"team_name", "member_count", "active_count", "open_pr_count"}}},
+	{name: "team sla get", help: "show the team review SLA", method: "GET", path: "/team/sla", arg: "team",
+		params: []param{required(query("team", "team_name", "team name"))}},
+	{name: "team sla set", help: "set the team review SLA from -f FILE", method: "POST", path: "/team/sla/set", file: true},
+
+	{name: "user get", help: "show a user", method: "GET", path: "/users/get", arg: "user",
+		params: []param{required(query("user", "user_id", "user_id"))}},
+	{name: "user list", help: "search users", method: "GET", path: "/users/list",
+		params: []param{
+			query("team", "team_name", "team name"),
+			query("active", "is_active", "true or false"),
+			query("username", "username", "username prefix, case-insensitive"),
+			query("limit", "limit", "page size"),
+			query("cursor", "cursor", "next_cursor of the previous page"),
+		},
+		table: &tableSpec{list: "users", columns: userColumns}},
+	{name: "user set-active", help: "turn review assignments on or off for a user", method: "POST", path: "/users/setIsActive", arg: "user",
+		params: []param{
+			required(body("user", "user_id", "user_id")),
+			{flag: "active", name: "is_active", in: "body", kind: kindBool, required: true, help: "true or false"},
+		}},
+	{name: "user reminders get", help: "show daily digest settings", method: "GET", path: "/users/reminders", arg: "user",
+		params: []param{required(query("user", "user_id", "user_id"))}},
+	{name: "user reminders set", help: "change daily digest settings from -f FILE", method: "POST", path: "/users/reminders/set", file: true},
+
+	{name: "pr create", help: "create a PR and assign reviewers", method: "POST", path: "/pullRequest/create",
+		params: []param{
+			required(body("id", "pull_request_id", "PR id")),
+			required(body("name", "pull_request_name", "PR name")),
+			required(body("author", "author_id", "author user_id")),
+			idempotencyKey,
+		},
+		table: &tableSpec{list: "pr", columns: prColumns}},
+	{name: "pr merge", help: "mark a PR as merged", method: "POST", path: "/pullRequest/merge", arg: "id",
+		params: []param{required(body("id", "pull_request_id", "PR id")), idempotencyKey},
+		table:  &tableSpec{list: "pr", columns: prColumns}},
+	{name: "pr reassign", help: "replace a reviewer with another member of their team", method: "POST", path: "/pullRequest/reassign", arg: "id",
+		params: []param{
+			required(body("id", "pull_request_id", "PR id")),
+			required(body("old", "old_user_id", "reviewer to replace")),
+			idempotencyKey,
+		}},
+	{name: "pr get", help: "show a PR", method: "GET", path: "/pullRequest/get", arg: "id",
+		params: []param{required(query("id", "pull_request_id", "PR id"))},
+		table:  &tableSpec{list: "pr", columns: prColumns}},
+	{name: "pr timeline", help: "show the PR history", method: "GET", path: "/pullRequest/timeline", arg: "id",
+		params: []param{required(query("id", "pull_request_id", "PR id"))},
+		table:  &tableSpec{list: "events", columns: []string{"id", "type", "actor", "reviewer_id", "previous_reviewer_id", "reason", "created_at"}}},
+	{name: "pr list", help: "search PRs", method: "GET", path: "/pullRequest/list",
+		params: with(prFilters, query("limit", "limit", "page size"), query("cursor", "cursor", "next_cursor of the previous page")),
+		table:  &tableSpec{list: "pull_requests", columns: prColumns}},
+	{name: "pr batch", help: "run create/merge/reassign operations from -f FILE", method: "POST", path: "/pullRequest/batch", file: true,
+		params: []param{idempotencyKey},
+		table:  &tableSpec{list: "results", columns: []string{"index", "op", "pull_request_id", "status", "replaced_by", "error.code", "error.message"}}},
+
+	{name: "review list", help: "PRs where the user is a reviewer", method: "GET", path: "/users/getReview", arg: "user",
+		params: []param{
+			required(query("user", "user_id", "reviewer user_id")),
+			query("status", "status", "OPEN, MERGED or both separated by comma"),
+			query("created-after", "created_after", "RFC 3339"),
+			query("created-before", "created_before", "RFC 3339"),
+			query("sort", "sort", "sort field"),
+			query("order", "order", "asc or desc"),
+			query("limit", "limit", "page size"),
+			query("cursor", "cursor", "next_cursor of the previous page"),
+		},
+		table: &tableSpec{list: "pull_requests", columns: []string{"pull_request_id", "pull_request_name", "author_id", "status", "createdAt", "overdue"}}},
+	{name: "review watch", help: "follow review assignments of a user until interrupted", method: "GET", path: "/users/reviews/stream", arg: "user",
+		params: []param{
+			required(query("user", "user_id", "reviewer user_id")),
+			{flag: "last-event-id", name: "Last-Event-ID", in: "header", help: "resume after this event instead of starting with the current list"},
+		},
+		stream: true},
+
+	{name: "stats", help: "service statistics", method: "GET", path: "/stats",
+		params: []param{query("team", "team_name", "team name"), query("from", "from", "RFC 3339"), query("to", "to", "RFC 3339")}},
+	{name: "stats fairness", help: "how evenly reviews are spread in a team", method: "GET", path: "/stats/fairness", arg: "team",
+		params: []param{
+			required(query("team", "team_name", "team name")),
+			query("from", "from", "RFC 3339"),
+			query("to", "to", "RFC 3339"),
+			query("tolerance", "tolerance", "allowed relative deviation"),
+		},
+		table: &tableSpec{list: "members", columns: []string{"user_id", "is_active", "assignments", "expected_assignments", "deviation", "flag"}}},
+	{name: "stats history", help: "metric history from snapshots", method: "GET", path: "/stats/history",
+		params: []param{
+			query("user", "user_id", "user_id"),
+			query("team", "team_name", "team name"),
+			query("bucket", "bucket", "hour, day or week"),
+			query("from", "from", "RFC 3339"),
+			query("to", "to", "RFC 3339"),
+		},
+		table: &tableSpec{list: "points", columns: []string{"time", "open_prs", "open_reviews", "assignments", "merges"}}},
+
+	{name: "export pull-requests", help: "export PRs as CSV or NDJSON", method: "GET", path: "/export/pull_requests", raw: true,
+		params: with(prFilters, query("format", "format", "csv or ndjson"), query("cursor", "cursor", "start after this cursor"))},
+	{name: "export assignments", help: "export reviewer assignments", method: "GET", path: "/export/assignments", raw: true,
+		params: []param{
+			query("format", "format", "csv or ndjson"),
+			query("team", "team_name", "team name"),
+			query("reviewer", "reviewer_id", "reviewer user_id"),
+			query("id", "pull_request_id", "PR id"),
+			query("from", "from", "RFC 3339"),
+			query("to", "to", "RFC 3339"),
+		}},
+	{name: "export stats", help: "export statistics tables", method: "GET", path: "/export/stats", raw: true,
+		params: []param{
+			query("format", "format", "csv or ndjson"),
+			query("table", "table", "statistics table"),
+			query("team", "team_name", "team name"),
+			query("from", "from", "RFC 3339"),
+			query("to", "to", "RFC 3339"),
+		}},
+
+	{name: "sla breaches", help: "list review SLA breaches", method: "GET", path: "/sla/breaches",
+		params: []param{query("team", "team_name", "team name"), switchParam("open", "open", "only unresolved breaches")},
+		table:  &tableSpec{list: "breaches", columns: []string{"id", "pull_request_id", "reviewer_id", "team_name", "assigned_at", "detected_at", "resolved_at"}}},
+
+	{name: "audit list", help: "search the audit log", method: "GET", path: "/audit", params: auditFilters,
+		table: &tableSpec{list: "entries", columns: []string{"id", "created_at", "actor", "action", "target_type", "target_id", "request_id"}}},
+	{name: "audit export", help: "export the audit log as NDJSON", method: "GET", path: "/audit/export", params: auditFilters, raw: true},
+
+	{name: "admin key create", help: "issue an API key (shown once)", method: "POST", path: "/admin/apiKeys/create",
+		params: []param{
+			required(body("name", "name", "unique key name")),
+			required(body("role", "role", "admin, team-lead, member or bot")),
+			body("user", "user_id", "user the key belongs to"),
+			body("team", "team_name", "team of a team-lead key"),
+		}},
+	{name: "admin key list", help: "list API keys", method: "GET", path: "/admin/apiKeys/list",
+		table: &tableSpec{list: "api_keys", columns: []string{"id", "name", "prefix", "role", "user_id", "team_name", "created_at", "last_used_at", "revoked_at"}}},
+	{name: "admin key revoke", help: "revoke an API key", method: "POST", path: "/admin/apiKeys/revoke", arg: "name",
+		params: []param{required(body("name", "name", "key name"))}},
+	{name: "admin chat link", help: "link a chat user to a service user", method: "POST", path: "/admin/chatIdentities/link",
+		params: []param{required(body("chat-user", "chat_user_id", "chat user id")), required(body("user", "user_id", "user_id"))}},
+	{name: "admin import", help: "import teams, users and PRs from -f FILE (JSON bundle or CSV with --section)", method: "POST", path: "/admin/import", file: true,
+		params: []param{switchParam("dry-run", "dry_run", "validate without applying"), query("section", "section", "teams, users or pull_requests for a CSV file")}},
+	{name: "admin state export", help: "download a full state archive", method: "GET", path: "/admin/state/export", raw: true},
+	{name: "admin state import", help: "restore a state archive from -f FILE into an empty database", method: "POST", path: "/admin/state/import", file: true,
+		params: []param{switchParam("dry-run", "dry_run", "validate without applying")}},
+}
+
+var commandIndex = func() map[string]*command {
+	index := make(map[string]*command, len(commandList))
+	for _, c := range commandList {
+		index[c.name] = c
+	}
+	return index
+}()
+
+// flagValues - значения флагов команды после разбора
+type flagValues struct {
+	strings  map[string]*string
+	switches map[string]*bool
+	file     *string
+}
+
+func (c *command) register(fs *flag.FlagSet) *flagValues {
+	v := &flagValues{strings: make(map[string]*string), switches: make(map[string]*bool)}
+	for _, p := range c.params {
+		if p.kind == kindSwitch {
+			v.switches[p.flag] = fs.Bool(p.flag, false, p.help)
+		} else {
+			v.strings[p.flag] = fs.String(p.flag, "", p.help)
+		}
+	}
+	if c.file {
+		v.file = fs.String("f", "", "request body file: YAML or JSON, - for stdin")
+	}
+	return v
+}
+
+func (c *command) synopsis() string {
+	var parts []string
+	if c.arg != "" {
+		parts = append(parts, strings.ToUpper(strings.ReplaceAll(c.arg, "-", "_")))
+	}
+	if c.file {
+		parts = append(parts, "-f FILE")
+	}
+	for _, p := range c.params {
+		if p.flag == c.arg {
+			continue
+		}
+		s := "--" + p.flag
+		if p.kind != kindSwitch {
+			s += " " + strings.ToUpper(strings.ReplaceAll(p.flag, "-", "_"))
+		}
+		if !p.required {
+			s = "[" + s + "]"
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, " ")
+}
+
+// request собирает запрос из флагов и позиционного аргумента
+func (c *command) request(v *flagValues, args []string) (*client.Request, error) {
+	if len(args) > 0 {
+		if c.arg == "" || len(args) > 1 {
+			return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(args, " "))
+		}
+		if *v.strings[c.arg] != "" && *v.strings[c.arg] != args[0] {
+			return nil, fmt.Errorf("%s given both as argument and --%s", args[0], c.arg)
+		}
+		*v.strings[c.arg] = args[0]
+	}
+
+	req := &client.Request{Method: c.method, Path: c.path, Query: url.Values{}, Header: http.Header{}}
+	fields := make(map[string]interface{})
+	for _, p := range c.params {
+		var value string
+		if p.kind == kindSwitch {
+			if *v.switches[p.flag] {
+				value = "true"
+			}
+		} else {
+			value = *v.strings[p.flag]
+		}
+		if value == "" {
+			if p.required {
+				return nil, fmt.Errorf("--%s is required", p.flag)
+			}
+			continue
+		}
+
+		switch p.in {
+		case "query":
+			req.Query.Set(p.name, value)
+		case "header":
+			req.Header.Set(p.name, value)
+		case "body":
+			if p.kind != kindBool {
+				fields[p.name] = value
+				break
+			}
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("--%s must be true or false", p.flag)
+			}
+			fields[p.name] = b
+		}
+	}
+
+	switch {
+	case c.file:
+		if *v.file == "" {
+			return nil, fmt.Errorf("-f FILE is required")
+		}
+		var err error
+		if req.Body, req.ContentType, err = readBody(*v.file); err != nil {
+			return nil, err
+		}
+	case len(fields) > 0:
+		req.Body, _ = json.Marshal(fields)
+		req.ContentType = "application/json"
+	}
+	return req, nil
+}
+
+// readBody читает тело из файла; YAML переводится в JSON, CSV отправляется как есть
+func readBody(path string) ([]byte, string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return data, "text/csv", nil
+	case ".json":
+		return data, "application/json", nil
+	}
+	// YAML - надмножество JSON, поэтому stdin и файлы без расширения тоже читаются как YAML
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, "", fmt.Errorf("%s: %v", path, err)
+	}
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %v", path, err)
+	}
+	return out, "application/json", nil
+}
+
+func (c *command) execute(ctx context.Context, conn *conn, req *client.Request, format string, stdout, stderr io.Writer) error {
+	if c.stream {
+		return watch(ctx, conn, req, format, stdout, stderr)
+	}
+
+	resp, err := conn.do(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if c.raw {
+		_, err := io.Copy(stdout, resp.Body)
+		return err
+	}
+
+	var result interface{}
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&result); err != nil {
+		return fmt.Errorf("unexpected response: %v", err)
+	}
+	if err := formatters[format](stdout, result, c.table); err != nil {
+		return err
+	}
+	if next, _ := lookupPath(result, "next_cursor").(string); next != "" && format == "table" {
+		fmt.Fprintf(stderr, "more results: --cursor %s\n", next)
+	}
+	return nil
+}
+
+// watch печатает события SSE и переподключается с Last-Event-ID, пока его не прервут
+func watch(ctx context.Context, conn *conn, req *client.Request, format string, stdout, stderr io.Writer) error {
+	retry := 5 * time.Second
+	for {
+		resp, err := conn.do(ctx, req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			var apiErr *client.Error
+			if errors.As(err, &apiErr) {
+				return err
+			}
+			fmt.Fprintf(stderr, "stream: %v, reconnecting in %s\n", err, retry)
+		} else {
+			lastID, r := readEvents(resp.Body, format, stdout)
+			resp.Body.Close()
+			if lastID != "" {
+				req.Header.Set("Last-Event-ID", lastID)
+			}
+			if r > 0 {
+				retry = r
+			}
+			if ctx.Err() != nil {
+				return nil
+			}
+			fmt.Fprintf(stderr, "stream closed, reconnecting in %s\n", retry)
+		}
+
+		select {
+		case <-time.After(retry):
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// readEvents разбирает поток до его конца; возвращает id последнего события и интервал retry от сервера
+func readEvents(r io.Reader, format string, stdout io.Writer) (string, time.Duration) {
+	var lastID, id, event string
+	var data []string
+	var retry time.Duration
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), 4<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if len(data) > 0 {
+				printEvent(stdout, format, id, event, strings.Join(data, "\n"))
+				lastID = id
+			}
+			id, event, data = "", "", nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			id = value
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil {
+				retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+	return lastID, retry
+}
+
+func printEvent(w io.Writer, format, id, event, data string) {
+	var payload interface{}
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.UseNumber()
+	if dec.Decode(&payload) != nil {
+		payload = data
+	}
+
+	switch format {
+	case "json":
+		line, _ := json.Marshal(map[string]interface{}{"id": id, "event": event, "data": payload})
+		fmt.Fprintf(w, "%s\n", line)
+	case "yaml":
+		fmt.Fprintln(w, "---")
+		writeYAML(w, map[string]interface{}{"id": id, "event": event, "data": payload}, nil)
+	default:
+		if event == "snapshot" {
+			prs, _ := lookupPath(payload, "pull_requests").([]interface{})
+			fmt.Fprintf(w, "%s\tsnapshot\t%d open reviews\n", id, len(prs))
+			for _, pr := range prs {
+				fmt.Fprintf(w, "\t\t%s\t%s\n", cell(lookupPath(pr, "pull_request_id")), cell(lookupPath(pr, "pull_request_name")))
+			}
+			return
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", id, event, cell(lookupPath(payload, "pull_request_id")), cell(lookupPath(payload, "actor")))
+	}
+}
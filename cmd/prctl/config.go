@@ -0,0 +1,236 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+const defaultServer = "http://localhost:8080"
+
+// profile - окружение сервиса (local, staging, prod)
+type profile struct {
+	Server string `yaml:"server"`
+	Token  string `yaml:"token,omitempty"`
+	Actor  string `yaml:"actor,omitempty"`
+	Output string `yaml:"output,omitempty"`
+}
+
+type profiles struct {
+	Current  string              `yaml:"current,omitempty"`
+	Profiles map[string]*profile `yaml:"profiles"`
+}
+
+func configPath(g *globals) (string, error) {
+	if g.configPath != "" {
+		return g.configPath, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "prctl", "config.yaml"), nil
+}
+
+// loadProfiles читает файл профилей; отсутствующий файл - пустой набор
+func loadProfiles(path string) (*profiles, error) {
+	cfg := &profiles{Profiles: make(map[string]*profile)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = make(map[string]*profile)
+	}
+	return cfg, nil
+}
+
+// save пишет файл с правами 0600: в нём лежат токены
+func (p *profiles) save(path string) error {
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// connect собирает подключение: флаги и PRCTL_* важнее профиля, профиль важнее значений по умолчанию
+func connect(g *globals) (*conn, string, error) {
+	path, err := configPath(g)
+	if err != nil {
+		return nil, "", err
+	}
+	cfg, err := loadProfiles(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	p := &profile{}
+	name := g.profile
+	if name == "" {
+		name = cfg.Current
+	}
+	if name != "" {
+		found, ok := cfg.Profiles[name]
+		if !ok {
+			return nil, "", fmt.Errorf("profile %q is not defined in %s", name, path)
+		}
+		p = found
+	}
+
+	server := first(g.server, p.Server, defaultServer)
+	format := first(g.output, p.Output, "table")
+	if _, ok := formatters[format]; !ok {
+		return nil, "", fmt.Errorf("unknown output format %q, use table, json or yaml", format)
+	}
+	return newConn(server, first(g.token, p.Token), first(g.actor, p.Actor)), format, nil
+}
+
+func first(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+const configUsage = `usage: prctl config COMMAND
+
+commands:
+  list                                   list profiles
+  set NAME [--server URL] [--token TOKEN] [--act-as NAME] [--output FORMAT]
+                                         create or update a profile; the first profile becomes current
+  use NAME                               make NAME the current profile
+  delete NAME                            delete a profile
+  show                                   print the effective connection settings`
+
+func runConfig(g *globals, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" {
+		fmt.Fprintln(stdout, configUsage)
+		return exitOK
+	}
+
+	path, err := configPath(g)
+	if err != nil {
+		fmt.Fprintf(stderr, "prctl config: %v\n", err)
+		return exitUsage
+	}
+	cfg, err := loadProfiles(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "prctl config: %v\n", err)
+		return exitUsage
+	}
+
+	if err := configCommand(g, cfg, path, args, stdout); err != nil {
+		fmt.Fprintf(stderr, "prctl config %s: %v\n", args[0], err)
+		return exitUsage
+	}
+	return exitOK
+}
+
+func configCommand(g *globals, cfg *profiles, path string, args []string, stdout io.Writer) error {
+	switch args[0] {
+	case "list":
+		names := make([]string, 0, len(cfg.Profiles))
+		for name := range cfg.Profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "CURRENT\tNAME\tSERVER\tTOKEN\tOUTPUT")
+		for _, name := range names {
+			p := cfg.Profiles[name]
+			current, token := "", ""
+			if name == cfg.Current {
+				current = "*"
+			}
+			if p.Token != "" {
+				token = "set"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", current, name, p.Server, token, p.Output)
+		}
+		return tw.Flush()
+
+	case "set":
+		fs := flag.NewFlagSet("prctl config set", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		server := fs.String("server", "", "service base URL")
+		token := fs.String("token", "", "API key or JWT")
+		actor := fs.String("act-as", "", "X-Actor for services without authentication")
+		output := fs.String("output", "", "default output format")
+		if len(args) < 2 {
+			return fmt.Errorf("profile name is required")
+		}
+		if err := fs.Parse(args[2:]); err != nil {
+			return err
+		}
+		if *output != "" {
+			if _, ok := formatters[*output]; !ok {
+				return fmt.Errorf("unknown output format %q", *output)
+			}
+		}
+
+		p, ok := cfg.Profiles[args[1]]
+		if !ok {
+			p = &profile{Server: defaultServer}
+			cfg.Profiles[args[1]] = p
+		}
+		p.Server = first(*server, p.Server)
+		p.Token = first(*token, p.Token)
+		p.Actor = first(*actor, p.Actor)
+		p.Output = first(*output, p.Output)
+		if cfg.Current == "" {
+			cfg.Current = args[1]
+		}
+		return cfg.save(path)
+
+	case "use", "delete":
+		if len(args) != 2 {
+			return fmt.Errorf("profile name is required")
+		}
+		if _, ok := cfg.Profiles[args[1]]; !ok {
+			return fmt.Errorf("profile %q is not defined in %s", args[1], path)
+		}
+		if args[0] == "use" {
+			cfg.Current = args[1]
+		} else {
+			delete(cfg.Profiles, args[1])
+			if cfg.Current == args[1] {
+				cfg.Current = ""
+			}
+		}
+		return cfg.save(path)
+
+	case "show":
+		c, format, err := connect(g)
+		if err != nil {
+			return err
+		}
+		token := "(none)"
+		if c.token != "" {
+			token = "(set)"
+		}
+		fmt.Fprintf(stdout, "config:  %s\nprofile: %s\nserver:  %s\ntoken:   %s\noutput:  %s\n",
+			path, first(g.profile, cfg.Current, "(none)"), c.server, token, format)
+		return nil
+	}
+	return fmt.Errorf("unknown command, see prctl config help")
+}
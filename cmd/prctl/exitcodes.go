@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"pr-reviewer-service/client"
+	"strings"
+)
+
+const (
+	exitOK = iota
+	exitError
+	exitUsage
+	exitNotFound
+	exitAuth
+	exitInvalid
+	exitConflict
+	exitPrecondition
+	exitUnavailable
+)
+
+var exitCodeHelp = []struct {
+	code int
+	help string
+}{
+	{exitOK, "success"},
+	{exitError, "internal service error or unexpected response"},
+	{exitUsage, "bad command line or config"},
+	{exitNotFound, "NOT_FOUND"},
+	{exitAuth, "UNAUTHORIZED, FORBIDDEN"},
+	{exitInvalid, "VALIDATION_ERROR, INVALID_* and other request errors"},
+	{exitConflict, "TEAM_EXISTS, PR_EXISTS, KEY_EXISTS, NOT_EMPTY, PR_MODIFIED, IDEMPOTENCY_KEY_REUSED, IDEMPOTENCY_IN_PROGRESS"},
+	{exitPrecondition, "PR_MERGED, NOT_ASSIGNED, NO_CANDIDATE"},
+	{exitUnavailable, "service unreachable or timed out"},
+}
+
+// exitCodes - код выхода для кода ошибки сервиса; коды INVALID_* обрабатываются в exitCode
+var exitCodes = map[string]int{
+	client.CodeNotFound:              exitNotFound,
+	client.CodeUnauthorized:          exitAuth,
+	client.CodeForbidden:             exitAuth,
+	client.CodeValidation:            exitInvalid,
+	client.CodeTeamRequired:          exitInvalid,
+	client.CodeRequestTooLarge:       exitInvalid,
+	client.CodeIdempotencyDisabled:   exitInvalid,
+	client.CodeNotAcceptable:         exitInvalid,
+	client.CodeTeamExists:            exitConflict,
+	client.CodePRExists:              exitConflict,
+	client.CodeKeyExists:             exitConflict,
+	client.CodeNotEmpty:              exitConflict,
+	client.CodeIdempotencyKeyReused:  exitConflict,
+	client.CodeIdempotencyInProgress: exitConflict,
+	client.CodePRModified:            exitConflict,
+	client.CodePRMerged:              exitPrecondition,
+	client.CodeNotAssigned:           exitPrecondition,
+	client.CodeNoCandidate:           exitPrecondition,
+	client.CodeInternal:              exitError,
+}
+
+func exitCode(err error) int {
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) {
+		var urlErr *url.Error
+		if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &urlErr) {
+			return exitUnavailable
+		}
+		return exitError
+	}
+
+	if code, ok := exitCodes[apiErr.Code]; ok {
+		return code
+	}
+	if strings.HasPrefix(apiErr.Code, "INVALID_") {
+		return exitInvalid
+	}
+	// Старые ответы {"error": "сообщение"} без кода
+	switch {
+	case apiErr.StatusCode == 404:
+		return exitNotFound
+	case apiErr.StatusCode == 401 || apiErr.StatusCode == 403:
+		return exitAuth
+	case apiErr.StatusCode == 409:
+		return exitConflict
+	case apiErr.StatusCode == 502 || apiErr.StatusCode == 503 || apiErr.StatusCode == 504:
+		return exitUnavailable
+	case apiErr.StatusCode >= 400 && apiErr.StatusCode < 500:
+		return exitInvalid
+	}
+	return exitError
+}
@@ -0,0 +1,184 @@
+// prctl - консольный клиент HTTP API сервиса: prctl team add -f team.yaml, prctl pr reassign PR --old USER,
+// prctl review list --user U, prctl stats. Адрес и токен берутся из профиля (~/.config/prctl/config.yaml),
+// переменных PRCTL_SERVER/PRCTL_TOKEN или флагов. Код выхода отражает код ошибки сервиса, см. exitCodes.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+)
+
+// globals - флаги, общие для всех команд; их можно указывать до и после имени команды
+type globals struct {
+	profile    string
+	configPath string
+	server     string
+	token      string
+	actor      string
+	output     string
+	timeout    time.Duration
+}
+
+func (g *globals) register(fs *flag.FlagSet) {
+	fs.StringVar(&g.profile, "profile", g.profile, "config profile (default: current profile from the config file)")
+	fs.StringVar(&g.configPath, "config", g.configPath, "config file")
+	fs.StringVar(&g.server, "server", g.server, "service base URL, overrides the profile")
+	fs.StringVar(&g.token, "token", g.token, "API key or JWT, overrides the profile")
+	fs.StringVar(&g.actor, "act-as", g.actor, "X-Actor for the audit log when the service runs without authentication")
+	fs.StringVar(&g.output, "o", g.output, "output format: table, json or yaml")
+	fs.StringVar(&g.output, "output", g.output, "output format: table, json or yaml")
+	fs.DurationVar(&g.timeout, "timeout", g.timeout, "request timeout, 0 for none (review watch ignores it)")
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	g := &globals{
+		profile:    os.Getenv("PRCTL_PROFILE"),
+		configPath: os.Getenv("PRCTL_CONFIG"),
+		server:     os.Getenv("PRCTL_SERVER"),
+		token:      os.Getenv("PRCTL_TOKEN"),
+		timeout:    time.Minute,
+	}
+	top := flag.NewFlagSet("prctl", flag.ContinueOnError)
+	top.SetOutput(stderr)
+	g.register(top)
+	top.Usage = func() { printUsage(stderr) }
+	if err := top.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	args = top.Args()
+
+	if len(args) == 0 || args[0] == "help" {
+		printUsage(stdout)
+		return exitOK
+	}
+	if args[0] == "config" {
+		return runConfig(g, args[1:], stdout, stderr)
+	}
+
+	cmd, rest := lookup(args)
+	if cmd == nil {
+		fmt.Fprintf(stderr, "prctl: unknown command %q\n\n", strings.Join(args, " "))
+		printUsage(stderr)
+		return exitUsage
+	}
+
+	fs := flag.NewFlagSet("prctl "+cmd.name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	g.register(fs)
+	values := cmd.register(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: prctl %s %s\n\n%s\n\nflags:\n", cmd.name, cmd.synopsis(), cmd.help)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(interleave(fs, rest)); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	req, err := cmd.request(values, fs.Args())
+	if err != nil {
+		fmt.Fprintf(stderr, "prctl %s: %v\nusage: prctl %s %s\n", cmd.name, err, cmd.name, cmd.synopsis())
+		return exitUsage
+	}
+
+	conn, format, err := connect(g)
+	if err != nil {
+		fmt.Fprintf(stderr, "prctl: %v\n", err)
+		return exitUsage
+	}
+
+	if g.timeout > 0 && !cmd.stream {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.timeout)
+		defer cancel()
+	}
+	if err := cmd.execute(ctx, conn, req, format, stdout, stderr); err != nil {
+		fmt.Fprintf(stderr, "prctl %s: %v\n", cmd.name, err)
+		return exitCode(err)
+	}
+	return exitOK
+}
+
+// lookup находит команду по самому длинному совпадению слов: "team sla get" раньше, чем "team"
+func lookup(args []string) (*command, []string) {
+	words := 0
+	for words < len(args) && !strings.HasPrefix(args[words], "-") {
+		words++
+	}
+	for n := words; n > 0; n-- {
+		if cmd, ok := commandIndex[strings.Join(args[:n], " ")]; ok {
+			return cmd, args[n:]
+		}
+	}
+	return nil, nil
+}
+
+// interleave переносит позиционные аргументы в конец, чтобы флаги можно было писать и после них:
+// prctl pr get PR-1 -o json
+func interleave(fs *flag.FlagSet, args []string) []string {
+	var flags, positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			positional = append(positional, args[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			positional = append(positional, arg)
+			continue
+		}
+		flags = append(flags, arg)
+		name := strings.TrimLeft(arg, "-")
+		if strings.Contains(name, "=") {
+			continue
+		}
+		if f := fs.Lookup(name); f != nil && !isBoolFlag(f) && i+1 < len(args) {
+			i++
+			flags = append(flags, args[i])
+		}
+	}
+	return append(flags, positional...)
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: prctl [--profile NAME] [--server URL] [--token TOKEN] [-o table|json|yaml] COMMAND [flags]")
+	fmt.Fprintln(w, "\ncommands:")
+	names := make([]string, 0, len(commandIndex))
+	for name := range commandIndex {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-26s %s\n", name, commandIndex[name].help)
+	}
+	fmt.Fprintf(w, "  %-26s %s\n", "config", "manage connection profiles (prctl config help)")
+	fmt.Fprintln(w, "\nexit codes:")
+	for _, c := range exitCodeHelp {
+		fmt.Fprintf(w, "  %d  %s\n", c.code, c.help)
+	}
+}
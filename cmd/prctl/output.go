@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// tableSpec - как показать ответ таблицей: list - путь к массиву строк, columns - поля строки (через точку для вложенных)
+type tableSpec struct {
+	list    string
+	columns []string
+}
+
+var formatters = map[string]func(w io.Writer, v interface{}, spec *tableSpec) error{
+	"json":  writeJSON,
+	"yaml":  writeYAML,
+	"table": writeTable,
+}
+
+func writeJSON(w io.Writer, v interface{}, _ *tableSpec) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func writeYAML(w io.Writer, v interface{}, _ *tableSpec) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(plainNumbers(v)); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// plainNumbers заменяет json.Number на int64 или float64, иначе YAML выведет числа строками
+func plainNumbers(v interface{}) interface{} {
+	switch t := v.(type) {
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		f, _ := t.Float64()
+		return f
+	case map[string]interface{}:
+		for k, item := range t {
+			t[k] = plainNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range t {
+			t[i] = plainNumbers(item)
+		}
+	}
+	return v
+}
+
+// writeTable выводит массив spec.list с колонками spec.columns, а объект без spec - парами поле/значение
+func writeTable(w io.Writer, v interface{}, spec *tableSpec) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	if spec != nil {
+		rows, ok := lookupPath(v, spec.list).([]interface{})
+		if !ok {
+			if obj, isObj := lookupPath(v, spec.list).(map[string]interface{}); isObj {
+				rows = []interface{}{obj}
+			}
+		}
+		headers := make([]string, len(spec.columns))
+		for i, c := range spec.columns {
+			headers[i] = strings.ToUpper(strings.ReplaceAll(c, ".", "_"))
+		}
+		fmt.Fprintln(tw, strings.Join(headers, "\t"))
+		for _, row := range rows {
+			cells := make([]string, len(spec.columns))
+			for i, c := range spec.columns {
+				cells[i] = cell(lookupPath(row, c))
+			}
+			fmt.Fprintln(tw, strings.Join(cells, "\t"))
+		}
+		return tw.Flush()
+	}
+
+	pairs := flatten("", v, nil)
+	for _, p := range pairs {
+		fmt.Fprintf(tw, "%s\t%s\n", p[0], p[1])
+	}
+	return tw.Flush()
+}
+
+func lookupPath(v interface{}, path string) interface{} {
+	if path == "" {
+		return v
+	}
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = obj[key]
+	}
+	return v
+}
+
+// flatten раскладывает вложенные объекты и массивы объектов в пары "a.b" / "a.0.b" - значение
+func flatten(prefix string, v interface{}, out [][2]string) [][2]string {
+	if list, ok := v.([]interface{}); ok && len(list) > 0 {
+		if _, isObj := list[0].(map[string]interface{}); isObj {
+			for i, item := range list {
+				out = flatten(fmt.Sprintf("%s.%d", prefix, i), item, out)
+			}
+			return out
+		}
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return append(out, [2]string{prefix, cell(v)})
+	}
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		name := k
+		if prefix != "" {
+			name = prefix + "." + k
+		}
+		out = flatten(name, obj[k], out)
+	}
+	return out
+}
+
+func cell(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case []interface{}:
+		parts := make([]string, 0, len(t))
+		for _, item := range t {
+			if _, isObj := item.(map[string]interface{}); isObj {
+				return fmt.Sprintf("[%d items]", len(t))
+			}
+			parts = append(parts, cell(item))
+		}
+		return strings.Join(parts, ",")
+	case map[string]interface{}:
+		data, _ := json.Marshal(t)
+		return string(data)
+	}
+	return fmt.Sprint(v)
+}
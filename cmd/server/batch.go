@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"pr-reviewer-service/internal/auth"
+	"pr-reviewer-service/internal/models"
+	"strings"
+)
+
+// batchErrorMessages - тексты ошибок одиночных эндпоинтов /pullRequest/create, /merge и /reassign
+var batchErrorMessages = map[string]string{
+	"PR_EXISTS":    "PR id already exists",
+	"NOT_FOUND":    "resource not found",
+	"PR_MERGED":    "cannot reassign on merged PR",
+	"NOT_ASSIGNED": "reviewer is not assigned to this PR",
+	"NO_CANDIDATE": "no active replacement candidate in team",
+	"PR_MODIFIED":  "PR was changed concurrently, retry",
+}
+
+// handlePRBatch выполняет пакет операций create/merge/reassign; результат по каждой операции
+// содержит те же коды ошибок, что и одиночные эндпоинты. Отклонённый пакет atomic - 422.
+func (s *Server) handlePRBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.PRBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Замена ревьювера доступна только людям, как и /pullRequest/reassign
+	if principal := auth.FromContext(r.Context()); principal != nil && principal.Role == auth.RoleBot {
+		for _, op := range req.Operations {
+			if op.Op == models.BatchOpReassign {
+				sendErrorResponse(w, "FORBIDDEN", "role is not allowed to reassign reviewers", http.StatusForbidden)
+				return
+			}
+		}
+	}
+
+	report, err := s.service.RunPRBatch(r.Context(), &req)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "INVALID_BATCH") {
+			sendErrorResponse(w, "INVALID_BATCH", strings.TrimPrefix(err.Error(), "INVALID_BATCH: "), http.StatusBadRequest)
+			return
+		}
+		sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for i := range report.Results {
+		result := &report.Results[i]
+		if result.Err == nil {
+			continue
+		}
+		code := result.Err.Error()
+		message, ok := batchErrorMessages[code]
+		if detail, found := strings.CutPrefix(code, "VALIDATION_ERROR: "); found {
+			code, message, ok = "VALIDATION_ERROR", detail, true
+		}
+		if !ok {
+			code, message = "INTERNAL", result.Err.Error()
+		}
+		result.Error = &models.BatchItemErrorBody{Code: code, Message: message}
+	}
+
+	status := http.StatusOK
+	if report.Mode == models.BatchAtomic && report.Failed > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"pr-reviewer-service/internal/audit"
+	"pr-reviewer-service/internal/importer"
+	"pr-reviewer-service/internal/models"
+	"pr-reviewer-service/internal/service"
+	"sort"
+	"strings"
+)
+
+// command - подкоманда сервиса, выполняемая вместо запуска HTTP-сервера.
+// Команды с offline не подключаются к БД и получают svc = nil.
+type command struct {
+	usage   string
+	run     func(ctx context.Context, svc *service.PRService, args []string) error
+	offline bool
+}
+
+const importUsage = "import [--dry-run] [--actor NAME] [--teams FILE.csv] [--users FILE.csv] [--pull-requests FILE.csv] [BUNDLE.json]"
+
+const (
+	exportStateUsage  = "export-state [--output FILE]"
+	importStateUsage  = "import-state [--dry-run] [--actor NAME] ARCHIVE.json"
+	checkOpenAPIUsage = "check-openapi"
+)
+
+var commands = map[string]command{
+	"import":        {usage: importUsage, run: runImport},
+	"export-state":  {usage: exportStateUsage, run: runExportState},
+	"import-state":  {usage: importStateUsage, run: runImportState},
+	"check-openapi": {usage: checkOpenAPIUsage, run: runCheckOpenAPI, offline: true},
+}
+
+func lookupCommand(args []string) (command, error) {
+	cmd, ok := commands[args[0]]
+	if !ok {
+		var usages []string
+		for _, c := range commands {
+			usages = append(usages, "  "+c.usage)
+		}
+		sort.Strings(usages)
+		return command{}, fmt.Errorf("unknown command %q, available commands:\n%s", args[0], strings.Join(usages, "\n"))
+	}
+	return cmd, nil
+}
+
+// runImport импортирует набор из JSON-файла и/или CSV-файлов разделов и печатает отчёт в stdout
+func runImport(ctx context.Context, svc *service.PRService, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "validate the bundle without applying it")
+	actor := fs.String("actor", "cli", "actor recorded in the audit log and PR history")
+	files := map[string]*string{
+		importer.SectionTeams:        fs.String("teams", "", "teams CSV file"),
+		importer.SectionUsers:        fs.String("users", "", "users CSV file"),
+		importer.SectionPullRequests: fs.String("pull-requests", "", "pull requests CSV file"),
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("usage: %s", importUsage)
+	}
+
+	bundle := &models.ImportBundle{}
+	if fs.NArg() == 1 {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		bundle, err = importer.DecodeJSON(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %v", fs.Arg(0), err)
+		}
+	}
+
+	found := fs.NArg() == 1
+	for _, section := range []string{importer.SectionTeams, importer.SectionUsers, importer.SectionPullRequests} {
+		path := *files[section]
+		if path == "" {
+			continue
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		err = importer.DecodeCSV(bundle, section, f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		found = true
+	}
+	if !found {
+		return fmt.Errorf("usage: %s", importUsage)
+	}
+
+	report, err := svc.Import(audit.WithActor(ctx, *actor), bundle, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(report)
+
+	if len(report.Errors) > 0 {
+		return fmt.Errorf("import rejected: %d errors, nothing was applied", len(report.Errors))
+	}
+	return nil
+}
+
+// runExportState пишет архив состояния в файл или stdout
+func runExportState(ctx context.Context, svc *service.PRService, args []string) error {
+	fs := flag.NewFlagSet("export-state", flag.ContinueOnError)
+	output := fs.String("output", "", "archive file (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("usage: %s", exportStateUsage)
+	}
+
+	archive, err := svc.ExportState(ctx)
+	if err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	if err := json.NewEncoder(out).Encode(archive); err != nil {
+		return err
+	}
+	if out != os.Stdout {
+		return out.Close()
+	}
+	return nil
+}
+
+// runImportState проверяет архив и восстанавливает его в пустую БД, печатая отчёт в stdout
+func runImportState(ctx context.Context, svc *service.PRService, args []string) error {
+	fs := flag.NewFlagSet("import-state", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "verify the archive without restoring it")
+	actor := fs.String("actor", "cli", "actor recorded in the audit log")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: %s", importStateUsage)
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	var archive models.StateArchive
+	err = json.NewDecoder(f).Decode(&archive)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("%s: %v", fs.Arg(0), err)
+	}
+
+	report, err := svc.ImportState(audit.WithActor(ctx, *actor), &archive, *dryRun)
+	if err != nil {
+		if err.Error() == "NOT_EMPTY" {
+			return fmt.Errorf("state can only be restored into an empty database")
+		}
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"pr-reviewer-service/internal/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	formatCSV    = "csv"
+	formatNDJSON = "ndjson"
+)
+
+// exportFormat выбирает формат выгрузки: параметр format важнее заголовка Accept, по умолчанию NDJSON.
+// Возвращает пустую строку, если Accept не допускает ни один из форматов.
+func exportFormat(r *http.Request) (string, error) {
+	switch f := r.URL.Query().Get("format"); f {
+	case formatCSV, formatNDJSON:
+		return f, nil
+	case "":
+	default:
+		return "", fmt.Errorf("format must be csv or ndjson")
+	}
+
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return formatNDJSON, nil
+	}
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case "text/csv":
+			return formatCSV, nil
+		case "application/x-ndjson", "application/ndjson", "*/*", "application/*":
+			return formatNDJSON, nil
+		case "text/*":
+			return formatCSV, nil
+		}
+	}
+	return "", nil
+}
+
+// exportWriter пишет строки выгрузки в CSV или NDJSON и периодически сбрасывает их клиенту
+type exportWriter struct {
+	csv     *csv.Writer
+	json    *json.Encoder
+	flusher http.Flusher
+	rows    int
+}
+
+func newExportWriter(w http.ResponseWriter, format, name string, columns []string) *exportWriter {
+	e := &exportWriter{}
+	e.flusher, _ = w.(http.Flusher)
+
+	if format == formatCSV {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, name))
+		e.csv = csv.NewWriter(w)
+		e.csv.Write(columns)
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.ndjson"`, name))
+		e.json = json.NewEncoder(w)
+	}
+	return e
+}
+
+// Write выводит v как JSON-объект или record как строку CSV
+func (e *exportWriter) Write(v interface{}, record []string) error {
+	if e.csv != nil {
+		if err := e.csv.Write(record); err != nil {
+			return err
+		}
+	} else if err := e.json.Encode(v); err != nil {
+		return err
+	}
+
+	e.rows++
+	if e.rows%100 == 0 {
+		e.flush()
+	}
+	return nil
+}
+
+func (e *exportWriter) flush() {
+	if e.csv != nil {
+		e.csv.Flush()
+	}
+	if e.flusher != nil {
+		e.flusher.Flush()
+	}
+}
+
+// finish завершает выгрузку. Ошибку до первой строки ещё можно вернуть клиентом как ответ,
+// после начала потока остаётся только записать её в лог.
+func (e *exportWriter) finish(w http.ResponseWriter, name string, err error) {
+	if err == nil {
+		e.flush()
+		return
+	}
+	if e.rows > 0 {
+		e.flush()
+		log.Printf("export %s: interrupted after %d rows: %v", name, e.rows, err)
+		return
+	}
+	sendExportError(w, err)
+}
+
+func sendExportError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case "INVALID_CURSOR":
+		sendErrorResponse(w, "INVALID_CURSOR", "cursor does not match this query", http.StatusBadRequest)
+	case "INVALID_RANGE":
+		sendErrorResponse(w, "INVALID_PARAM", "from must be before to", http.StatusBadRequest)
+	case "NOT_FOUND":
+		sendErrorResponse(w, "NOT_FOUND", "resource not found", http.StatusNotFound)
+	default:
+		sendError(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// negotiateExport разбирает формат и отвечает ошибкой, если он не подходит
+func negotiateExport(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return "", false
+	}
+
+	format, err := exportFormat(r)
+	if err != nil {
+		sendErrorResponse(w, "INVALID_PARAM", err.Error(), http.StatusBadRequest)
+		return "", false
+	}
+	if format == "" {
+		sendErrorResponse(w, "NOT_ACCEPTABLE", "supported formats are text/csv and application/x-ndjson", http.StatusNotAcceptable)
+		return "", false
+	}
+	return format, true
+}
+
+var prExportColumns = []string{
+	"pull_request_id", "pull_request_name", "author_id", "status",
+	"assigned_reviewers", "created_at", "merged_at", "overdue",
+}
+
+func (s *Server) handleExportPRs(w http.ResponseWriter, r *http.Request) {
+	format, ok := negotiateExport(w, r)
+	if !ok {
+		return
+	}
+
+	filter, err := parsePRListFilter(r.URL.Query())
+	if err != nil {
+		sendErrorResponse(w, "INVALID_PARAM", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	out := newExportWriter(w, format, "pull_requests", prExportColumns)
+	err = s.service.StreamPRs(filter, func(pr *models.PullRequest) error {
+		return out.Write(pr, []string{
+			csvText(pr.PullRequestID),
+			csvText(pr.PullRequestName),
+			csvText(pr.AuthorID),
+			pr.Status,
+			csvText(strings.Join(pr.AssignedReviewers, ";")),
+			csvTime(pr.CreatedAt),
+			csvTime(pr.MergedAt),
+			strconv.FormatBool(pr.Overdue),
+		})
+	})
+	out.finish(w, "pull_requests", err)
+}
+
+var assignmentExportColumns = []string{
+	"assigned_at", "pull_request_id", "pull_request_name", "author_id", "team_name",
+	"reviewer_id", "previous_reviewer_id", "reason", "actor", "pr_status",
+}
+
+func (s *Server) handleExportAssignments(w http.ResponseWriter, r *http.Request) {
+	format, ok := negotiateExport(w, r)
+	if !ok {
+		return
+	}
+
+	q := r.URL.Query()
+	filter := models.AssignmentFilter{
+		TeamName:      q.Get("team_name"),
+		ReviewerID:    q.Get("reviewer_id"),
+		PullRequestID: q.Get("pull_request_id"),
+	}
+	var err error
+	if filter.From, err = parseTime(q, "from"); err != nil {
+		sendErrorResponse(w, "INVALID_PARAM", err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.To, err = parseTime(q, "to"); err != nil {
+		sendErrorResponse(w, "INVALID_PARAM", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	out := newExportWriter(w, format, "assignments", assignmentExportColumns)
+	err = s.service.StreamAssignments(filter, func(a *models.Assignment) error {
+		return out.Write(a, []string{
+			csvTime(&a.AssignedAt),
+			csvText(a.PullRequestID),
+			csvText(a.PullRequestName),
+			csvText(a.AuthorID),
+			csvText(a.TeamName),
+			csvText(a.ReviewerID),
+			csvText(a.PreviousReviewerID),
+			a.Reason,
+			csvText(a.Actor),
+			a.PRStatus,
+		})
+	})
+	out.finish(w, "assignments", err)
+}
+
+// handleExportStats выгружает одну из таблиц /stats: reviewers (по умолчанию), summary или open_age
+func (s *Server) handleExportStats(w http.ResponseWriter, r *http.Request) {
+	format, ok := negotiateExport(w, r)
+	if !ok {
+		return
+	}
+
+	table := r.URL.Query().Get("table")
+	if table == "" {
+		table = "reviewers"
+	}
+	if table != "reviewers" && table != "summary" && table != "open_age" {
+		sendErrorResponse(w, "INVALID_PARAM", "table must be reviewers, summary or open_age", http.StatusBadRequest)
+		return
+	}
+
+	filter, err := parseStatsFilter(r.URL.Query())
+	if err != nil {
+		sendErrorResponse(w, "INVALID_PARAM", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stats, err := s.service.GetStats(filter)
+	if err != nil {
+		sendExportError(w, err)
+		return
+	}
+
+	name := "stats_" + table
+	switch table {
+	case "reviewers":
+		out := newExportWriter(w, format, name, []string{"user_id", "assignment_count", "open_reviews", "reassigned_away"})
+		for i := range stats.ReviewerLoad {
+			rs := &stats.ReviewerLoad[i]
+			err = out.Write(rs, []string{
+				csvText(rs.UserID),
+				strconv.Itoa(rs.AssignmentCount),
+				strconv.Itoa(rs.OpenReviews),
+				strconv.Itoa(rs.ReassignedAway),
+			})
+			if err != nil {
+				break
+			}
+		}
+		out.finish(w, name, err)
+
+	case "open_age":
+		out := newExportWriter(w, format, name, []string{"label", "min_hours", "max_hours", "count"})
+		for i := range stats.OpenPRAge {
+			b := &stats.OpenPRAge[i]
+			maxHours := ""
+			if b.MaxHours > 0 {
+				maxHours = strconv.Itoa(b.MaxHours)
+			}
+			err = out.Write(b, []string{b.Label, strconv.Itoa(b.MinHours), maxHours, strconv.Itoa(b.Count)})
+			if err != nil {
+				break
+			}
+		}
+		out.finish(w, name, err)
+
+	case "summary":
+		out := newExportWriter(w, format, name, []string{"metric", "value"})
+		for _, m := range statsSummary(stats) {
+			if err = out.Write(m, []string{m.Metric, m.Value}); err != nil {
+				break
+			}
+		}
+		out.finish(w, name, err)
+	}
+}
+
+type summaryMetric struct {
+	Metric string `json:"metric"`
+	Value  string `json:"value"`
+}
+
+// statsSummary разворачивает скалярные показатели /stats в пары метрика-значение
+func statsSummary(stats *models.Stats) []summaryMetric {
+	optional := func(v *float64) string {
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*v, 'f', 0, 64)
+	}
+
+	metrics := []summaryMetric{
+		{"total_teams", strconv.Itoa(stats.TotalTeams)},
+		{"total_users", strconv.Itoa(stats.TotalUsers)},
+		{"total_prs", strconv.Itoa(stats.TotalPRs)},
+		{"open_prs", strconv.Itoa(stats.OpenPRs)},
+		{"merged_prs", strconv.Itoa(stats.MergedPRs)},
+		{"time_to_merge_count", strconv.Itoa(stats.TimeToMerge.Count)},
+		{"time_to_merge_median_seconds", optional(stats.TimeToMerge.MedianSeconds)},
+		{"time_to_merge_p90_seconds", optional(stats.TimeToMerge.P90Seconds)},
+		{"reassignments_total", strconv.Itoa(stats.Reassignments.Total)},
+		{"prs_reassigned", strconv.Itoa(stats.Reassignments.PRsReassigned)},
+		{"reassignment_rate", strconv.FormatFloat(stats.Reassignments.Rate, 'f', 4, 64)},
+	}
+	for _, b := range stats.OpenPRAge {
+		metrics = append(metrics, summaryMetric{"open_prs_age_" + b.Label, strconv.Itoa(b.Count)})
+	}
+	return metrics
+}
+
+// csvText защищает от формул: табличные редакторы выполняют ячейки, начинающиеся с = + - @
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func csvTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"log"
+	"net"
+	pb "pr-reviewer-service/api/prreviewer/v1"
+	"pr-reviewer-service/internal/auth"
+	"pr-reviewer-service/internal/grpcapi"
+	"pr-reviewer-service/internal/service"
+)
+
+// grpcPolicies - кому разрешён каждый метод gRPC; роли совпадают с соответствующими маршрутами HTTP
+var grpcPolicies = map[string]auth.Policy{
+	pb.PRReviewerService_AddTeam_FullMethodName:   {Roles: adminOnly},
+	pb.PRReviewerService_GetTeam_FullMethodName:   {Roles: allRoles},
+	pb.PRReviewerService_ListTeams_FullMethodName: {Roles: allRoles},
+
+	pb.PRReviewerService_GetUser_FullMethodName:       {Roles: allRoles},
+	pb.PRReviewerService_ListUsers_FullMethodName:     {Roles: allRoles},
+	pb.PRReviewerService_SetUserActive_FullMethodName: {Roles: leadsAndAdmin},
+
+	pb.PRReviewerService_CreatePullRequest_FullMethodName: {Roles: allRoles},
+	pb.PRReviewerService_MergePullRequest_FullMethodName:  {Roles: allRoles},
+	pb.PRReviewerService_ReassignReviewer_FullMethodName:  {Roles: humans},
+	pb.PRReviewerService_GetPullRequest_FullMethodName:    {Roles: allRoles},
+
+	pb.PRReviewerService_GetUserReviews_FullMethodName:       {Roles: allRoles},
+	pb.PRReviewerService_GetStats_FullMethodName:             {Roles: allRoles},
+	pb.PRReviewerService_SubscribeAssignments_FullMethodName: {Roles: allRoles},
+}
+
+func init() {
+	// Описание API через reflection открыто, как /openapi.json
+	for _, method := range grpcapi.ReflectionMethods {
+		grpcPolicies[method] = auth.Policy{Public: true}
+	}
+}
+
+// serveGRPC запускает gRPC API; authenticator равен nil при выключенной аутентификации
+func serveGRPC(svc *service.PRService, authenticator *auth.Authenticator, port string, reflection bool) {
+	lis, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		log.Fatal("Failed to listen for gRPC:", err)
+	}
+
+	server := grpcapi.NewServer(svc, grpcapi.Options{
+		Auth:       authenticator,
+		Policies:   grpcPolicies,
+		Reflection: reflection,
+	})
+	log.Printf("gRPC server starting on port %s", port)
+	log.Fatal(server.Serve(lis))
+}
@@ -1,25 +1,54 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"pr-reviewer-service/internal/audit"
+	"pr-reviewer-service/internal/auth"
+	"pr-reviewer-service/internal/chatops"
+	"pr-reviewer-service/internal/config"
+	"pr-reviewer-service/internal/handlers"
+	"pr-reviewer-service/internal/idempotency"
 	"pr-reviewer-service/internal/models"
+	"pr-reviewer-service/internal/notify"
+	"pr-reviewer-service/internal/openapi"
+	"pr-reviewer-service/internal/scheduler"
 	"pr-reviewer-service/internal/service"
 	"pr-reviewer-service/internal/storage"
+	"pr-reviewer-service/internal/ui"
+	"strconv"
+	"strings"
 	"time"
+	_ "time/tzdata"
 
 	_ "github.com/lib/pq"
 )
 
 type Server struct {
-	service *service.PRService
+	service   *service.PRService
+	admin     *handlers.AdminHandler
+	audit     *handlers.AuditHandler
+	sla       *handlers.SLAHandler
+	reminders *handlers.ReminderHandler
+	ui        *ui.Handler
+	// chatops равен nil, если не задан секрет подписи
+	chatops http.Handler
 }
 
-func NewServer(service *service.PRService) *Server {
-	return &Server{service: service}
+// NewServer собирает обработчики; authenticator равен nil при выключенной аутентификации,
+// тогда и страницы /ui открыты без входа
+func NewServer(service *service.PRService, authenticator *auth.Authenticator) *Server {
+	return &Server{
+		service:   service,
+		admin:     handlers.NewAdminHandler(service),
+		audit:     handlers.NewAuditHandler(service),
+		sla:       handlers.NewSLAHandler(service),
+		reminders: handlers.NewReminderHandler(service),
+		ui:        ui.NewHandler(service, authenticator),
+	}
 }
 
 func (s *Server) handleTeamAdd(w http.ResponseWriter, r *http.Request) {
@@ -34,7 +63,7 @@ func (s *Server) handleTeamAdd(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.service.CreateTeam(&team); err != nil {
+	if err := s.service.CreateTeam(r.Context(), &team); err != nil {
 		if err.Error() == "TEAM_EXISTS" {
 			sendErrorResponse(w, "TEAM_EXISTS", "team_name already exists", http.StatusBadRequest)
 			return
@@ -74,6 +103,88 @@ func (s *Server) handleTeamGet(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(team)
 }
 
+func (s *Server) handleTeamList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	limit, err := parseLimit(q)
+	if err != nil {
+		sendErrorResponse(w, "INVALID_PARAM", err.Error(), http.StatusBadRequest)
+		return
+	}
+	cursor, err := parseCursor(q)
+	if err != nil {
+		sendErrorResponse(w, "INVALID_PARAM", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	teams, nextCursor, err := s.service.ListTeams(cursor, limit)
+	if err != nil {
+		sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"teams":       teams,
+		"next_cursor": nextCursor,
+	})
+}
+
+func (s *Server) handleGetUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		sendError(w, "user_id is required", http.StatusBadRequest)
+		return
+	}
+
+	user, err := s.service.GetUser(userID)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			sendErrorResponse(w, "NOT_FOUND", "resource not found", http.StatusNotFound)
+			return
+		}
+		sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"user": user})
+}
+
+func (s *Server) handleListUsers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	filter, err := parseUserFilter(r.URL.Query())
+	if err != nil {
+		sendErrorResponse(w, "INVALID_PARAM", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	users, nextCursor, err := s.service.ListUsers(filter)
+	if err != nil {
+		sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"users":       users,
+		"next_cursor": nextCursor,
+	})
+}
+
 func (s *Server) handleSetUserActive(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -90,7 +201,24 @@ func (s *Server) handleSetUserActive(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := s.service.SetUserActive(req.UserID, req.IsActive)
+	// Тимлид может менять активность только участников своей команды
+	if principal := auth.FromContext(r.Context()); principal != nil && principal.Role == auth.RoleTeamLead {
+		userTeam, err := s.service.GetUserTeam(req.UserID)
+		if err != nil {
+			if err.Error() == "NOT_FOUND" {
+				sendErrorResponse(w, "NOT_FOUND", "resource not found", http.StatusNotFound)
+				return
+			}
+			sendError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if userTeam != principal.TeamName {
+			sendErrorResponse(w, "FORBIDDEN", "team lead can only change members of own team", http.StatusForbidden)
+			return
+		}
+	}
+
+	user, err := s.service.SetUserActive(r.Context(), req.UserID, req.IsActive)
 	if err != nil {
 		if err.Error() == "NOT_FOUND" {
 			sendErrorResponse(w, "NOT_FOUND", "resource not found", http.StatusNotFound)
@@ -121,7 +249,7 @@ func (s *Server) handleCreatePR(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pr, err := s.service.CreatePR(req.PullRequestID, req.PullRequestName, req.AuthorID)
+	pr, err := s.service.CreatePR(r.Context(), req.PullRequestID, req.PullRequestName, req.AuthorID)
 	if err != nil {
 		errMsg := err.Error()
 		if errMsg == "PR_EXISTS" {
@@ -154,7 +282,7 @@ func (s *Server) handleMergePR(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pr, err := s.service.MergePR(req.PullRequestID)
+	pr, err := s.service.MergePR(r.Context(), req.PullRequestID)
 	if err != nil {
 		if err.Error() == "NOT_FOUND" {
 			sendErrorResponse(w, "NOT_FOUND", "resource not found", http.StatusNotFound)
@@ -184,7 +312,7 @@ func (s *Server) handleReassignReviewer(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	pr, newUserID, err := s.service.ReassignReviewer(req.PullRequestID, req.OldUserID)
+	pr, newUserID, err := s.service.ReassignReviewer(r.Context(), req.PullRequestID, req.OldUserID)
 	if err != nil {
 		errMsg := err.Error()
 		if errMsg == "NOT_FOUND" {
@@ -195,6 +323,8 @@ func (s *Server) handleReassignReviewer(w http.ResponseWriter, r *http.Request)
 			sendErrorResponse(w, "NOT_ASSIGNED", "reviewer is not assigned to this PR", http.StatusConflict)
 		} else if errMsg == "NO_CANDIDATE" {
 			sendErrorResponse(w, "NO_CANDIDATE", "no active replacement candidate in team", http.StatusConflict)
+		} else if errMsg == "PR_MODIFIED" {
+			sendErrorResponse(w, "PR_MODIFIED", "PR was changed concurrently, retry", http.StatusConflict)
 		} else {
 			sendError(w, err.Error(), http.StatusInternalServerError)
 		}
@@ -208,6 +338,61 @@ func (s *Server) handleReassignReviewer(w http.ResponseWriter, r *http.Request)
 	})
 }
 
+func (s *Server) handleGetPR(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	prID := r.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		sendError(w, "pull_request_id is required", http.StatusBadRequest)
+		return
+	}
+
+	pr, err := s.service.GetPR(prID)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			sendErrorResponse(w, "NOT_FOUND", "resource not found", http.StatusNotFound)
+			return
+		}
+		sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"pr": pr})
+}
+
+func (s *Server) handlePRTimeline(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	prID := r.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		sendError(w, "pull_request_id is required", http.StatusBadRequest)
+		return
+	}
+
+	events, err := s.service.GetPRTimeline(prID)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			sendErrorResponse(w, "NOT_FOUND", "resource not found", http.StatusNotFound)
+			return
+		}
+		sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"pull_request_id": prID,
+		"events":          events,
+	})
+}
+
 func (s *Server) handleGetUserReviewPRs(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "GET" {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -220,8 +405,19 @@ func (s *Server) handleGetUserReviewPRs(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	prs, err := s.service.GetUserReviewPRs(userID)
+	filter, err := parseReviewFilter(r.URL.Query())
+	if err != nil {
+		sendErrorResponse(w, "INVALID_PARAM", err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.UserID = userID
+
+	page, err := s.service.GetUserReviewPRs(filter)
 	if err != nil {
+		if err.Error() == "INVALID_CURSOR" {
+			sendErrorResponse(w, "INVALID_CURSOR", "cursor does not match this query", http.StatusBadRequest)
+			return
+		}
 		sendError(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -229,7 +425,50 @@ func (s *Server) handleGetUserReviewPRs(w http.ResponseWriter, r *http.Request)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"user_id":       userID,
+		"pull_requests": page.PullRequests,
+		"next_cursor":   page.NextCursor,
+		"total":         page.Total,
+	})
+}
+
+func (s *Server) handleListPRs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	filter, err := parsePRListFilter(r.URL.Query())
+	if err != nil {
+		sendErrorResponse(w, "INVALID_PARAM", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	fields, err := parseFields(r.URL.Query())
+	if err != nil {
+		sendErrorResponse(w, "INVALID_PARAM", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := s.service.ListPRs(filter)
+	if err != nil {
+		if err.Error() == "INVALID_CURSOR" {
+			sendErrorResponse(w, "INVALID_CURSOR", "cursor does not match this query", http.StatusBadRequest)
+			return
+		}
+		sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var prs interface{} = page.PullRequests
+	if len(fields) > 0 {
+		prs = selectFields(page.PullRequests, fields)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
 		"pull_requests": prs,
+		"next_cursor":   page.NextCursor,
+		"total":         page.Total,
 	})
 }
 
@@ -239,9 +478,22 @@ func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	stats, err := s.service.GetStats()
+	filter, err := parseStatsFilter(r.URL.Query())
 	if err != nil {
-		sendError(w, err.Error(), http.StatusInternalServerError)
+		sendErrorResponse(w, "INVALID_PARAM", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stats, err := s.service.GetStats(filter)
+	if err != nil {
+		switch err.Error() {
+		case "NOT_FOUND":
+			sendErrorResponse(w, "NOT_FOUND", "team not found", http.StatusNotFound)
+		case "INVALID_RANGE":
+			sendErrorResponse(w, "INVALID_PARAM", "from must be before to", http.StatusBadRequest)
+		default:
+			sendError(w, err.Error(), http.StatusInternalServerError)
+		}
 		return
 	}
 
@@ -249,6 +501,95 @@ func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(stats)
 }
 
+func (s *Server) handleFairness(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	teamName := q.Get("team_name")
+	if teamName == "" {
+		sendErrorResponse(w, "INVALID_PARAM", "team_name is required", http.StatusBadRequest)
+		return
+	}
+
+	from, to, err := parseWindow(q, service.DefaultFairnessWindow)
+	if err != nil {
+		sendErrorResponse(w, "INVALID_PARAM", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tolerance := 0.25
+	if v := q.Get("tolerance"); v != "" {
+		tolerance, err = strconv.ParseFloat(v, 64)
+		if err != nil || tolerance < 0 {
+			sendErrorResponse(w, "INVALID_PARAM", "tolerance must be a non-negative number", http.StatusBadRequest)
+			return
+		}
+	}
+
+	report, err := s.service.FairnessReport(teamName, from, to, tolerance)
+	if err != nil {
+		switch err.Error() {
+		case "NOT_FOUND":
+			sendErrorResponse(w, "NOT_FOUND", "team not found", http.StatusNotFound)
+		case "INVALID_RANGE":
+			sendErrorResponse(w, "INVALID_PARAM", "from must be before to", http.StatusBadRequest)
+		default:
+			sendError(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+func (s *Server) handleStatsHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	scope, subject := models.ScopeGlobal, ""
+	switch {
+	case q.Get("user_id") != "":
+		scope, subject = models.ScopeUser, q.Get("user_id")
+	case q.Get("team_name") != "":
+		scope, subject = models.ScopeTeam, q.Get("team_name")
+	}
+
+	bucket := q.Get("bucket")
+	if bucket == "" {
+		bucket = "day"
+	}
+
+	from, to, err := parseWindow(q, service.DefaultHistoryWindow)
+	if err != nil {
+		sendErrorResponse(w, "INVALID_PARAM", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	series, err := s.service.GetStatsHistory(scope, subject, bucket, from, to)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			sendErrorResponse(w, "NOT_FOUND", "resource not found", http.StatusNotFound)
+			return
+		}
+		if strings.HasPrefix(err.Error(), "INVALID_HISTORY") {
+			sendErrorResponse(w, "INVALID_PARAM", strings.TrimPrefix(err.Error(), "INVALID_HISTORY: "), http.StatusBadRequest)
+			return
+		}
+		sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(series)
+}
+
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "GET" {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -263,17 +604,135 @@ func (s *Server) SetupRoutes() *http.ServeMux {
 
 	mux.HandleFunc("/team/add", s.handleTeamAdd)
 	mux.HandleFunc("/team/get", s.handleTeamGet)
+	mux.HandleFunc("/team/list", s.handleTeamList)
 	mux.HandleFunc("/users/setIsActive", s.handleSetUserActive)
 	mux.HandleFunc("/users/getReview", s.handleGetUserReviewPRs)
+	mux.HandleFunc("/users/get", s.handleGetUser)
+	mux.HandleFunc("/users/list", s.handleListUsers)
+	mux.HandleFunc("/users/reviews/stream", s.handleReviewStream)
 	mux.HandleFunc("/pullRequest/create", s.handleCreatePR)
 	mux.HandleFunc("/pullRequest/merge", s.handleMergePR)
 	mux.HandleFunc("/pullRequest/reassign", s.handleReassignReviewer)
+	mux.HandleFunc("/pullRequest/get", s.handleGetPR)
+	mux.HandleFunc("/pullRequest/timeline", s.handlePRTimeline)
+	mux.HandleFunc("/pullRequest/list", s.handleListPRs)
+	mux.HandleFunc("/pullRequest/batch", s.handlePRBatch)
 	mux.HandleFunc("/stats", s.handleStats)
+	mux.HandleFunc("/stats/fairness", s.handleFairness)
+	mux.HandleFunc("/stats/history", s.handleStatsHistory)
+	mux.HandleFunc("/export/pull_requests", s.handleExportPRs)
+	mux.HandleFunc("/export/assignments", s.handleExportAssignments)
+	mux.HandleFunc("/export/stats", s.handleExportStats)
 	mux.HandleFunc("/health", s.handleHealth)
+	mux.HandleFunc("/openapi.json", apiSpec.ServeJSON)
+	mux.HandleFunc("/docs", openapi.ServeDocs)
+
+	mux.HandleFunc("/users/reminders", s.reminders.Get)
+	mux.HandleFunc("/users/reminders/set", s.reminders.Set)
+
+	mux.HandleFunc("/team/sla", s.sla.Get)
+	mux.HandleFunc("/team/sla/set", s.sla.Set)
+	mux.HandleFunc("/sla/breaches", s.sla.Breaches)
+
+	mux.HandleFunc("/audit", s.audit.List)
+	mux.HandleFunc("/audit/export", s.audit.Export)
+
+	mux.HandleFunc("/admin/apiKeys/create", s.admin.CreateAPIKey)
+	mux.HandleFunc("/admin/apiKeys/list", s.admin.ListAPIKeys)
+	mux.HandleFunc("/admin/apiKeys/revoke", s.admin.RevokeAPIKey)
+	mux.HandleFunc("/admin/chatIdentities/link", s.admin.LinkChatIdentity)
+	mux.HandleFunc("/admin/import", s.admin.Import)
+	mux.HandleFunc("/admin/state/export", s.admin.ExportState)
+	mux.HandleFunc("/admin/state/import", s.admin.ImportState)
+
+	mux.HandleFunc("/ui", s.ui.Dashboard)
+	mux.HandleFunc("/ui/team", s.ui.Team)
+	mux.HandleFunc("/ui/queue", s.ui.Queue)
+	mux.HandleFunc("/ui/pulls", s.ui.Pulls)
+	mux.HandleFunc("/ui/users/setIsActive", s.ui.SetActive)
+	mux.HandleFunc("/ui/pullRequest/reassign", s.ui.Reassign)
+	mux.HandleFunc("/ui/login", s.ui.Login)
+	mux.HandleFunc("/ui/logout", s.ui.Logout)
+	mux.HandleFunc("/ui/assets/style.css", s.ui.Style)
+
+	if s.chatops != nil {
+		mux.Handle("/chatops/command", s.chatops)
+	}
 
 	return mux
 }
 
+var (
+	allRoles      = []auth.Role{auth.RoleAdmin, auth.RoleTeamLead, auth.RoleMember, auth.RoleBot}
+	adminOnly     = []auth.Role{auth.RoleAdmin}
+	leadsAndAdmin = []auth.Role{auth.RoleAdmin, auth.RoleTeamLead}
+	humans        = []auth.Role{auth.RoleAdmin, auth.RoleTeamLead, auth.RoleMember}
+)
+
+// routePolicies - кому разрешён каждый маршрут при включённой аутентификации.
+// Маршрут, отсутствующий здесь, будет недоступен.
+var routePolicies = map[string]auth.Policy{
+	"/health":       {Public: true},
+	"/openapi.json": {Public: true},
+	"/docs":         {Public: true},
+
+	"/team/add":             {Roles: adminOnly},
+	"/team/get":             {Roles: allRoles},
+	"/team/list":            {Roles: allRoles},
+	"/users/setIsActive":    {Roles: leadsAndAdmin},
+	"/users/getReview":      {Roles: allRoles},
+	"/users/get":            {Roles: allRoles},
+	"/users/list":           {Roles: allRoles},
+	"/users/reviews/stream": {Roles: allRoles},
+	"/pullRequest/create":   {Roles: allRoles},
+	"/pullRequest/merge":    {Roles: allRoles},
+	"/pullRequest/reassign": {Roles: humans},
+	"/pullRequest/get":      {Roles: allRoles},
+	"/pullRequest/timeline": {Roles: allRoles},
+	"/pullRequest/list":     {Roles: allRoles},
+	"/pullRequest/batch":    {Roles: allRoles},
+	"/stats":                {Roles: allRoles},
+	"/stats/fairness":       {Roles: allRoles},
+	"/stats/history":        {Roles: allRoles},
+
+	"/export/pull_requests": {Roles: humans},
+	"/export/assignments":   {Roles: humans},
+	"/export/stats":         {Roles: humans},
+
+	"/users/reminders":     {Roles: humans},
+	"/users/reminders/set": {Roles: humans},
+
+	"/team/sla":     {Roles: allRoles},
+	"/team/sla/set": {Roles: leadsAndAdmin},
+	"/sla/breaches": {Roles: allRoles},
+
+	"/audit":        {Roles: adminOnly},
+	"/audit/export": {Roles: adminOnly},
+
+	"/admin/apiKeys/create": {Roles: adminOnly},
+	"/admin/apiKeys/list":   {Roles: adminOnly},
+	"/admin/apiKeys/revoke": {Roles: adminOnly},
+
+	"/admin/chatIdentities/link": {Roles: adminOnly},
+	"/admin/import":              {Roles: adminOnly},
+	"/admin/state/export":        {Roles: adminOnly},
+	"/admin/state/import":        {Roles: adminOnly},
+
+	// Запрос подписан секретом чата, учётные данные сервиса не нужны
+	"/chatops/command": {Public: true},
+
+	// Браузер передаёт ключ в cookie сессии, панель сама проверяет его и роль
+	"/ui":                      {Public: true},
+	"/ui/team":                 {Public: true},
+	"/ui/queue":                {Public: true},
+	"/ui/pulls":                {Public: true},
+	"/ui/users/setIsActive":    {Public: true},
+	"/ui/pullRequest/reassign": {Public: true},
+	"/ui/login":                {Public: true},
+	"/ui/logout":               {Public: true},
+	"/ui/assets/style.css":     {Public: true},
+}
+
 func sendError(w http.ResponseWriter, message string, statusCode int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
@@ -291,30 +750,64 @@ func sendErrorResponse(w http.ResponseWriter, code, message string, statusCode i
 	json.NewEncoder(w).Encode(errorResp)
 }
 
-func main() {
+// connectStorage подключается к БД с повторными попытками и настраивает пул соединений
+func connectStorage(cfg *config.Config) (*storage.PostgresStorage, error) {
 	var dbStorage *storage.PostgresStorage
 	var err error
 
-	maxRetries := 5
+	maxRetries := cfg.Database.ConnectRetries
+	retryDelay := time.Duration(cfg.Database.ConnectRetryDelay)
 	for i := 0; i < maxRetries; i++ {
-		connStr := fmt.Sprintf(
-			"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-			getEnv("DB_HOST", "localhost"),
-			getEnv("DB_PORT", "5432"),
-			getEnv("DB_USER", "postgres"),
-			getEnv("DB_PASSWORD", "password"),
-			getEnv("DB_NAME", "pr_reviewer"),
-		)
-
-		dbStorage, err = storage.NewPostgresStorage(connStr)
+		dbStorage, err = storage.NewPostgresStorage(cfg.Database.ConnString())
 		if err == nil {
 			break
 		}
 
 		log.Printf("Failed to connect to database (attempt %d/%d): %v", i+1, maxRetries, err)
-		time.Sleep(3 * time.Second)
+		if i < maxRetries-1 {
+			time.Sleep(retryDelay)
+		}
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	dbStorage.SetPoolLimits(
+		cfg.Database.MaxOpenConns,
+		cfg.Database.MaxIdleConns,
+		time.Duration(cfg.Database.ConnMaxLifetime),
+	)
+	return dbStorage, nil
+}
 
+func main() {
+	opts, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+	cfg := opts.Config
+
+	if opts.PrintConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	var cmd command
+	if len(opts.Args) > 0 {
+		if cmd, err = lookupCommand(opts.Args); err != nil {
+			log.Fatal(err)
+		}
+		if cmd.offline {
+			if err := cmd.run(context.Background(), nil, opts.Args[1:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+	}
+
+	dbStorage, err := connectStorage(cfg)
 	if err != nil {
 		log.Fatal("Failed to connect to database after retries:", err)
 	}
@@ -322,18 +815,88 @@ func main() {
 
 	prService := service.NewPRService(dbStorage)
 
-	server := NewServer(prService)
+	if len(opts.Args) > 0 {
+		if err := cmd.run(context.Background(), prService, opts.Args[1:]); err != nil {
+			dbStorage.Close()
+			log.Fatal(err)
+		}
+		return
+	}
 
-	router := server.SetupRoutes()
+	if cfg.Reminders.SMTP.Host != "" {
+		prService.RegisterNotifier(service.ChannelEmail, notify.NewSMTPNotifier(notify.SMTPOptions{
+			Host:     cfg.Reminders.SMTP.Host,
+			Port:     cfg.Reminders.SMTP.Port,
+			Username: cfg.Reminders.SMTP.Username,
+			Password: cfg.Reminders.SMTP.Password,
+			From:     cfg.Reminders.SMTP.From,
+		}))
+	}
+	prService.RegisterNotifier(service.ChannelWebhook, notify.NewWebhookNotifier(
+		cfg.Reminders.Webhook.URL,
+		time.Duration(cfg.Reminders.Webhook.Timeout),
+	))
 
-	port := getEnv("PORT", "8080")
-	log.Printf("Server starting on port %s", port)
-	log.Fatal(http.ListenAndServe(":"+port, router))
-}
+	if cfg.SLA.Enabled {
+		go scheduler.Every(context.Background(), "sla", time.Duration(cfg.SLA.CheckInterval), prService.CheckSLA)
+	}
+	if cfg.Reminders.Enabled {
+		go scheduler.Every(context.Background(), "reminders", time.Duration(cfg.Reminders.CheckInterval), prService.SendDigests)
+	}
+
+	if cfg.Stats.SnapshotEnabled {
+		rawRetention, retention := time.Duration(cfg.Stats.RawRetention), time.Duration(cfg.Stats.Retention)
+		go scheduler.Every(context.Background(), "stats-snapshots", time.Duration(cfg.Stats.SnapshotInterval), func(ctx context.Context) error {
+			return prService.SnapshotStats(ctx, rawRetention, retention)
+		})
+	}
+
+	var authenticator *auth.Authenticator
+	if cfg.Auth.Enabled {
+		authOpts := auth.Options{BootstrapAPIKey: cfg.Auth.BootstrapAPIKey}
+		if cfg.Auth.JWTSecret != "" || cfg.Auth.JWKSFile != "" {
+			authOpts.JWT, err = auth.NewJWTVerifier(auth.JWTOptions{
+				Secret:   cfg.Auth.JWTSecret,
+				JWKSFile: cfg.Auth.JWKSFile,
+				Issuer:   cfg.Auth.JWTIssuer,
+				Audience: cfg.Auth.JWTAudience,
+				Leeway:   time.Duration(cfg.Auth.JWTLeeway),
+			})
+			if err != nil {
+				log.Fatal("Failed to configure JWT authentication:", err)
+			}
+		}
+		authenticator = auth.NewAuthenticator(prService, authOpts)
+	} else {
+		log.Printf("WARNING: authentication is disabled, all endpoints are open")
+	}
+
+	server := NewServer(prService, authenticator)
+	if cfg.ChatOps.SigningSecret != "" {
+		server.chatops = chatops.NewHandler(prService, cfg.ChatOps.SigningSecret, time.Duration(cfg.ChatOps.MaxSkew))
+	}
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+	for _, problem := range openAPIDrift() {
+		log.Printf("WARNING: openapi: %s", problem)
 	}
-	return defaultValue
+
+	var handler http.Handler = server.SetupRoutes()
+	if cfg.Idempotency.Enabled {
+		handler = idempotency.Middleware(prService, time.Duration(cfg.Idempotency.TTL), handler)
+		go scheduler.Every(context.Background(), "idempotency-keys", time.Hour, prService.PruneIdempotencyKeys)
+	} else {
+		handler = idempotency.Disabled(handler)
+	}
+	handler = audit.Middleware(apiSpec.Middleware(handler))
+	if authenticator != nil {
+		handler = authenticator.Middleware(routePolicies, handler)
+	}
+
+	if cfg.GRPC.Enabled {
+		go serveGRPC(prService, authenticator, cfg.GRPC.Port, cfg.GRPC.Reflection)
+	}
+
+	port := cfg.Server.Port
+	log.Printf("Server starting on port %s", port)
+	log.Fatal(http.ListenAndServe(":"+port, handler))
 }
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"pr-reviewer-service/client"
+	"pr-reviewer-service/internal/openapi"
+	"pr-reviewer-service/internal/service"
+	"sort"
+	"strings"
+)
+
+// apiSpec - встроенная спецификация OpenAPI; по ней проверяются запросы
+var apiSpec = openapi.MustLoad()
+
+// openAPIDrift сверяет спецификацию с SetupRoutes, routePolicies и методами пакета client
+func openAPIDrift() []string {
+	server := NewServer(nil, nil)
+	// Маршрут чат-команд регистрируется только при настроенном секрете
+	server.chatops = http.NotFoundHandler()
+
+	routes := make([]string, 0, len(routePolicies))
+	for route := range routePolicies {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+	problems := apiSpec.CheckRoutes(server.SetupRoutes(), routes)
+	return append(problems, apiSpec.CheckClient(client.Endpoints())...)
+}
+
+// runCheckOpenAPI завершается с ошибкой, если спецификация разошлась с маршрутами; для CI
+func runCheckOpenAPI(ctx context.Context, svc *service.PRService, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("usage: %s", checkOpenAPIUsage)
+	}
+	if problems := openAPIDrift(); len(problems) > 0 {
+		return fmt.Errorf("openapi spec drift:\n  %s", strings.Join(problems, "\n  "))
+	}
+	fmt.Println("openapi spec matches routes and client")
+	return nil
+}
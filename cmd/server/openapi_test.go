@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"pr-reviewer-service/internal/chatops"
+	"pr-reviewer-service/internal/service"
+	"pr-reviewer-service/internal/storage"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	for _, problem := range openAPIDrift() {
+		t.Error(problem)
+	}
+}
+
+// registeredRoutes - пути, которые SetupRoutes передаёт в mux.HandleFunc и mux.Handle.
+// ServeMux не перечисляет свои маршруты, поэтому они берутся из исходника.
+func registeredRoutes(t *testing.T) []string {
+	t.Helper()
+	file, err := parser.ParseFile(token.NewFileSet(), "main.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var routes []string
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Name.Name != "SetupRoutes" {
+			continue
+		}
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) != 2 {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || (sel.Sel.Name != "HandleFunc" && sel.Sel.Name != "Handle") {
+				return true
+			}
+			lit, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				t.Errorf("route registered with a non-literal path at offset %d", call.Pos())
+				return true
+			}
+			route, _ := strconv.Unquote(lit.Value)
+			routes = append(routes, route)
+			return true
+		})
+	}
+	if len(routes) == 0 {
+		t.Fatal("no routes found in SetupRoutes")
+	}
+	return routes
+}
+
+func TestRegisteredRoutesAreDescribed(t *testing.T) {
+	for _, route := range registeredRoutes(t) {
+		if len(apiSpec.Methods(route)) == 0 {
+			t.Errorf("SetupRoutes registers %s, which is not in the spec", route)
+		}
+		if _, ok := routePolicies[route]; !ok {
+			t.Errorf("SetupRoutes registers %s, which has no route policy", route)
+		}
+	}
+}
+
+// unreachableService - сервис над пулом, который не может подключиться: обращения к хранилищу сразу
+// возвращают ошибку, поэтому обработчик отвечает обычным статусом, не падая и не зависая
+func unreachableService(t *testing.T) *service.PRService {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Порт только что освободился, подключение к нему будет отклонено
+	addr := ln.Addr().(*net.TCPAddr)
+	ln.Close()
+
+	db, err := sql.Open("postgres", fmt.Sprintf("host=127.0.0.1 port=%d user=test dbname=test sslmode=disable connect_timeout=1", addr.Port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return service.NewPRService(storage.NewPostgresStorageFromDB(db))
+}
+
+// status возвращает статус ответа обработчика на запрос без тела
+func status(t *testing.T, handler http.Handler, method, path string) int {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req := httptest.NewRequest(method, path, nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if ctx.Err() != nil {
+		t.Errorf("%s %s did not finish in time", method, path)
+	}
+	return rec.Code
+}
+
+func TestRouteMethodsMatchSpec(t *testing.T) {
+	svc := unreachableService(t)
+	server := NewServer(svc, nil)
+	server.chatops = chatops.NewHandler(svc, "test-secret", time.Minute)
+	mux := server.SetupRoutes()
+
+	for _, path := range apiSpec.Paths() {
+		described := map[string]bool{}
+		for _, method := range apiSpec.Methods(path) {
+			described[method] = true
+		}
+		for _, method := range []string{"GET", "POST", "PUT", "PATCH", "DELETE"} {
+			code := status(t, mux, method, path)
+			if described[method] && code == http.StatusMethodNotAllowed {
+				t.Errorf("%s %s is in the spec, but the handler rejects it with 405", method, path)
+			}
+			if !described[method] && code != http.StatusMethodNotAllowed {
+				t.Errorf("%s %s is not in the spec, but the handler answers %d instead of 405", method, path, code)
+			}
+		}
+	}
+}
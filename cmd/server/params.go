@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"pr-reviewer-service/internal/models"
+	"pr-reviewer-service/internal/pagination"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseReviewFilter разбирает параметры /users/getReview: status, created_after, created_before,
+// sort, order, limit и cursor
+func parseReviewFilter(q url.Values) (models.ReviewFilter, error) {
+	var filter models.ReviewFilter
+	var err error
+
+	if filter.Statuses, err = parseStatuses(q); err != nil {
+		return filter, err
+	}
+	if filter.CreatedAfter, err = parseTime(q, "created_after"); err != nil {
+		return filter, err
+	}
+	if filter.CreatedBefore, err = parseTime(q, "created_before"); err != nil {
+		return filter, err
+	}
+	if filter.SortDesc, err = parseSortDesc(q); err != nil {
+		return filter, err
+	}
+	if filter.Limit, err = parseLimit(q); err != nil {
+		return filter, err
+	}
+	if filter.After, err = parseCursor(q); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+// parsePRListFilter разбирает параметры /pullRequest/list
+func parsePRListFilter(q url.Values) (models.PRListFilter, error) {
+	filter := models.PRListFilter{
+		TeamName:     q.Get("team_name"),
+		AuthorID:     q.Get("author_id"),
+		ReviewerID:   q.Get("reviewer_id"),
+		NameContains: q.Get("name"),
+		Search:       q.Get("q"),
+	}
+	var err error
+
+	if filter.Statuses, err = parseStatuses(q); err != nil {
+		return filter, err
+	}
+	if filter.MinAge, err = parseDuration(q, "min_age"); err != nil {
+		return filter, err
+	}
+	if filter.MaxAge, err = parseDuration(q, "max_age"); err != nil {
+		return filter, err
+	}
+	if v := q.Get("no_reviewers"); v != "" {
+		if filter.NoReviewers, err = strconv.ParseBool(v); err != nil {
+			return filter, fmt.Errorf("no_reviewers must be true or false")
+		}
+	}
+	if v := q.Get("overdue"); v != "" {
+		if filter.OverdueOnly, err = strconv.ParseBool(v); err != nil {
+			return filter, fmt.Errorf("overdue must be true or false")
+		}
+	}
+	if filter.SortDesc, err = parseSortDesc(q); err != nil {
+		return filter, err
+	}
+	if filter.Limit, err = parseLimit(q); err != nil {
+		return filter, err
+	}
+	if filter.After, err = parseCursor(q); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+// parseStatsFilter разбирает параметры /stats: team_name, from и to (RFC3339)
+func parseStatsFilter(q url.Values) (models.StatsFilter, error) {
+	filter := models.StatsFilter{TeamName: q.Get("team_name")}
+	var err error
+
+	if filter.From, err = parseTime(q, "from"); err != nil {
+		return filter, err
+	}
+	if filter.To, err = parseTime(q, "to"); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+// parseWindow разбирает from и to (RFC3339); по умолчанию окно длины def, заканчивающееся сейчас
+func parseWindow(q url.Values, def time.Duration) (time.Time, time.Time, error) {
+	to, err := parseTime(q, "to")
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	from, err := parseTime(q, "from")
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	end := time.Now()
+	if to != nil {
+		end = *to
+	}
+	start := end.Add(-def)
+	if from != nil {
+		start = *from
+	}
+	return start, end, nil
+}
+
+// parseStatuses разбирает status: OPEN, MERGED или оба через запятую
+func parseStatuses(q url.Values) ([]string, error) {
+	v := q.Get("status")
+	if v == "" {
+		return nil, nil
+	}
+
+	var statuses []string
+	for _, status := range strings.Split(v, ",") {
+		status = strings.ToUpper(strings.TrimSpace(status))
+		if status != "OPEN" && status != "MERGED" {
+			return nil, fmt.Errorf("status must be OPEN or MERGED")
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func parseTime(q url.Values, name string) (*time.Time, error) {
+	v := q.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC3339 timestamp", name)
+	}
+	return &t, nil
+}
+
+// parseDuration принимает длительности Go (72h, 90m) и дни (7d)
+func parseDuration(q url.Values, name string) (*time.Duration, error) {
+	v := q.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	if days, ok := strings.CutSuffix(v, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("%s must be a duration like 72h or 3d", name)
+		}
+		d := time.Duration(n) * 24 * time.Hour
+		return &d, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return nil, fmt.Errorf("%s must be a duration like 72h or 3d", name)
+	}
+	return &d, nil
+}
+
+// parseSortDesc разбирает sort (created_at или age) и order (asc или desc).
+// created_at по умолчанию - сначала новые, age по умолчанию - сначала самые старые.
+func parseSortDesc(q url.Values) (bool, error) {
+	order := q.Get("order")
+	if order != "" && order != "asc" && order != "desc" {
+		return false, fmt.Errorf("order must be asc or desc")
+	}
+
+	switch q.Get("sort") {
+	case "", "created_at":
+		return order != "asc", nil
+	case "age":
+		return order == "asc", nil
+	}
+	return false, fmt.Errorf("sort must be created_at or age")
+}
+
+func parseLimit(q url.Values) (int, error) {
+	v := q.Get("limit")
+	if v == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(v)
+	if err != nil || limit <= 0 {
+		return 0, fmt.Errorf("limit must be a positive integer")
+	}
+	return limit, nil
+}
+
+func parseCursor(q url.Values) (*models.PageCursor, error) {
+	v := q.Get("cursor")
+	if v == "" {
+		return nil, nil
+	}
+	var cursor models.PageCursor
+	if err := pagination.Decode(v, &cursor); err != nil {
+		return nil, fmt.Errorf("cursor is invalid")
+	}
+	return &cursor, nil
+}
+
+// prFields - поля PR, которые можно запросить через fields
+var prFields = map[string]bool{
+	"pull_request_id":    true,
+	"pull_request_name":  true,
+	"author_id":          true,
+	"status":             true,
+	"assigned_reviewers": true,
+	"createdAt":          true,
+	"mergedAt":           true,
+	"overdue":            true,
+}
+
+// parseFields разбирает fields - список полей PR через запятую
+func parseFields(q url.Values) ([]string, error) {
+	v := q.Get("fields")
+	if v == "" {
+		return nil, nil
+	}
+
+	var fields []string
+	for _, field := range strings.Split(v, ",") {
+		field = strings.TrimSpace(field)
+		if !prFields[field] {
+			return nil, fmt.Errorf("unknown field %q", field)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// selectFields оставляет в каждом PR только запрошенные поля
+func selectFields(prs []models.PullRequest, fields []string) []map[string]json.RawMessage {
+	out := make([]map[string]json.RawMessage, 0, len(prs))
+	for _, pr := range prs {
+		data, _ := json.Marshal(pr)
+		var all map[string]json.RawMessage
+		json.Unmarshal(data, &all)
+
+		selected := make(map[string]json.RawMessage, len(fields))
+		for _, field := range fields {
+			if value, ok := all[field]; ok {
+				selected[field] = value
+			}
+		}
+		out = append(out, selected)
+	}
+	return out
+}
+
+// parseUserFilter разбирает параметры /users/list: team_name, is_active, username (префикс), limit и cursor
+func parseUserFilter(q url.Values) (models.UserFilter, error) {
+	filter := models.UserFilter{
+		TeamName:       q.Get("team_name"),
+		UsernamePrefix: q.Get("username"),
+	}
+	var err error
+
+	if v := q.Get("is_active"); v != "" {
+		isActive, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, fmt.Errorf("is_active must be true or false")
+		}
+		filter.IsActive = &isActive
+	}
+	if filter.Limit, err = parseLimit(q); err != nil {
+		return filter, err
+	}
+	cursor, err := parseCursor(q)
+	if err != nil {
+		return filter, err
+	}
+	if cursor != nil {
+		filter.AfterID = cursor.ID
+	}
+
+	return filter, nil
+}
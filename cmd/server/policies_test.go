@@ -0,0 +1,171 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	pb "pr-reviewer-service/api/prreviewer/v1"
+	"pr-reviewer-service/internal/auth"
+	"pr-reviewer-service/internal/grpcapi"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestRoutePoliciesMatrix(t *testing.T) {
+	// Кому открыт маршрут: A - admin, L - team-lead, M - member, B - bot, * - без аутентификации
+	matrix := map[string]string{
+		"/health":       "*",
+		"/openapi.json": "*",
+		"/docs":         "*",
+
+		"/team/add":             "A",
+		"/team/get":             "ALMB",
+		"/users/setIsActive":    "AL",
+		"/users/reviews/stream": "ALMB",
+		"/pullRequest/create":   "ALMB",
+		"/pullRequest/reassign": "ALM",
+		"/pullRequest/batch":    "ALMB",
+		"/stats/history":        "ALMB",
+
+		"/export/pull_requests": "ALM",
+		"/users/reminders/set":  "ALM",
+		"/team/sla/set":         "AL",
+
+		"/audit":                     "A",
+		"/audit/export":              "A",
+		"/admin/apiKeys/create":      "A",
+		"/admin/apiKeys/list":        "A",
+		"/admin/apiKeys/revoke":      "A",
+		"/admin/chatIdentities/link": "A",
+		"/admin/import":              "A",
+		"/admin/state/export":        "A",
+		"/admin/state/import":        "A",
+
+		"/chatops/command":         "*",
+		"/ui/pullRequest/reassign": "*",
+	}
+	letters := map[auth.Role]string{auth.RoleAdmin: "A", auth.RoleTeamLead: "L", auth.RoleMember: "M", auth.RoleBot: "B"}
+
+	for route, want := range matrix {
+		policy, ok := routePolicies[route]
+		if !ok {
+			t.Errorf("%s: no policy", route)
+			continue
+		}
+		got := ""
+		if policy.Public {
+			got = "*"
+		}
+		for _, role := range []auth.Role{auth.RoleAdmin, auth.RoleTeamLead, auth.RoleMember, auth.RoleBot} {
+			if policy.Allows(role) {
+				got += letters[role]
+			}
+		}
+		if got != want {
+			t.Errorf("%s: allowed %q, want %q", route, got, want)
+		}
+	}
+}
+
+func TestRoutePoliciesPublic(t *testing.T) {
+	// Публичными могут быть только служебные маршруты, панель с собственной сессией и подписанные slash-команды
+	var public []string
+	for route, policy := range routePolicies {
+		if !policy.Public {
+			continue
+		}
+		switch {
+		case route == "/health", route == "/openapi.json", route == "/docs", route == "/chatops/command":
+		case route == "/ui", strings.HasPrefix(route, "/ui/"):
+		default:
+			public = append(public, route)
+		}
+		if len(policy.Roles) > 0 {
+			t.Errorf("%s: public route also lists roles", route)
+		}
+	}
+	sort.Strings(public)
+	if len(public) > 0 {
+		t.Errorf("unexpected public routes: %v", public)
+	}
+
+	for route, policy := range routePolicies {
+		if !policy.Public && len(policy.Roles) == 0 {
+			t.Errorf("%s: no role is allowed", route)
+		}
+	}
+}
+
+func TestUIRequiresLogin(t *testing.T) {
+	// /ui публичен для middleware, поэтому вход проверяет сама панель: NewServer передаёт ей authenticator
+	authenticator := auth.NewAuthenticator(nil, auth.Options{BootstrapAPIKey: "bootstrap"})
+	mux := NewServer(nil, authenticator).SetupRoutes()
+
+	for _, path := range []string{"/ui", "/ui/queue", "/ui/pulls"} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/ui/login" {
+			t.Errorf("GET %s without a session: status %d, Location %q", path, rec.Code, rec.Header().Get("Location"))
+		}
+	}
+}
+
+func TestGRPCPoliciesMatchRoutes(t *testing.T) {
+	// Метод gRPC доступен тем же ролям, что и маршрут HTTP с той же операцией
+	routes := map[string]string{
+		"AddTeam":              "/team/add",
+		"GetTeam":              "/team/get",
+		"ListTeams":            "/team/list",
+		"GetUser":              "/users/get",
+		"ListUsers":            "/users/list",
+		"SetUserActive":        "/users/setIsActive",
+		"CreatePullRequest":    "/pullRequest/create",
+		"MergePullRequest":     "/pullRequest/merge",
+		"ReassignReviewer":     "/pullRequest/reassign",
+		"GetPullRequest":       "/pullRequest/get",
+		"GetUserReviews":       "/users/getReview",
+		"GetStats":             "/stats",
+		"SubscribeAssignments": "/users/reviews/stream",
+	}
+
+	desc := pb.PRReviewerService_ServiceDesc
+	var names []string
+	for _, m := range desc.Methods {
+		names = append(names, m.MethodName)
+	}
+	for _, s := range desc.Streams {
+		names = append(names, s.StreamName)
+	}
+
+	known := make(map[string]bool)
+	for _, name := range names {
+		method := "/" + desc.ServiceName + "/" + name
+		known[method] = true
+		route, ok := routes[name]
+		if !ok {
+			t.Errorf("%s: no matching HTTP route in the test", method)
+			continue
+		}
+		policy, ok := grpcPolicies[method]
+		if !ok {
+			t.Errorf("%s: no policy", method)
+			continue
+		}
+		if want := routePolicies[route]; policy.Public || !reflect.DeepEqual(policy.Roles, want.Roles) {
+			t.Errorf("%s: roles %v, %s allows %v", method, policy.Roles, route, want.Roles)
+		}
+	}
+
+	for _, method := range grpcapi.ReflectionMethods {
+		known[method] = true
+		if !grpcPolicies[method].Public {
+			t.Errorf("%s: reflection is not public", method)
+		}
+	}
+	for method := range grpcPolicies {
+		if !known[method] {
+			t.Errorf("%s: policy for an unknown method", method)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"pr-reviewer-service/internal/models"
+	"strconv"
+	"time"
+)
+
+const (
+	// sseHeartbeat - интервал комментариев-пингов, чтобы прокси не закрывали простаивающий поток
+	sseHeartbeat = 15 * time.Second
+	// sseRetry - через сколько EventSource переподключается после обрыва
+	sseRetry = 5 * time.Second
+)
+
+// handleReviewStream отдаёт по SSE список открытых ревью пользователя (событие snapshot),
+// затем события assigned, unassigned и merged из журнала PR. id события - id записи журнала,
+// поэтому при переподключении с Last-Event-ID поток продолжается без snapshot. Пропуск отдельных
+// событий не исключён (см. reviewerEventSettle), полный список ревью даёт только snapshot.
+func (s *Server) handleReviewStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	userID := q.Get("user_id")
+	if userID == "" {
+		sendError(w, "user_id is required", http.StatusBadRequest)
+		return
+	}
+
+	// EventSource сам передаёт Last-Event-ID при переподключении; last_event_id - для клиентов без заголовков
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = q.Get("last_event_id")
+	}
+	var afterID int64
+	resume := lastEventID != ""
+	if resume {
+		id, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || id < 0 {
+			sendErrorResponse(w, "INVALID_PARAM", "Last-Event-ID must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		afterID = id
+	}
+
+	if _, err := s.service.GetUser(userID); err != nil {
+		if err.Error() == "NOT_FOUND" {
+			sendErrorResponse(w, "NOT_FOUND", "resource not found", http.StatusNotFound)
+			return
+		}
+		sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Позиция журнала берётся до чтения списка: событие между ними придёт ещё и в потоке, но не потеряется
+	var snapshot []models.PullRequestShort
+	if !resume {
+		var err error
+		if afterID, err = s.service.ReviewerEventsHead(); err != nil {
+			sendError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if snapshot, err = s.service.OpenReviews(userID); err != nil {
+			sendError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetry.Milliseconds())
+	if !resume {
+		writeSSE(w, afterID, "snapshot", map[string]interface{}{
+			"user_id":       userID,
+			"pull_requests": snapshot,
+		})
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	events := make(chan *models.PREvent)
+	done := make(chan error, 1)
+	go func() {
+		done <- s.service.WatchReviewerEvents(ctx, userID, afterID, func(e *models.PREvent) error {
+			select {
+			case events <- e:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case e := <-events:
+			event := models.ReviewStreamEvent{
+				EventID:       e.ID,
+				Kind:          e.ReviewerKind(userID),
+				PullRequestID: e.PullRequestID,
+				UserID:        userID,
+				Actor:         e.Actor,
+				Reason:        e.Reason,
+				CreatedAt:     e.CreatedAt,
+			}
+			if event.Kind == models.ReviewerUnassigned {
+				event.ReviewerID = e.ReviewerID
+			}
+			if pr, err := s.service.GetPR(e.PullRequestID); err == nil {
+				event.PR = pr
+			}
+			writeSSE(w, e.ID, event.Kind, event)
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+		case err := <-done:
+			if err != nil && ctx.Err() == nil {
+				log.Printf("review stream for %s: %v", userID, err)
+			}
+			return
+		case <-ctx.Done():
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeSSE(w http.ResponseWriter, id int64, event string, data interface{}) {
+	payload, _ := json.Marshal(data)
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", id, event, payload)
+}
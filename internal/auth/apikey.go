@@ -0,0 +1,26 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// APIKeyPrefix отличает API-ключи от JWT в заголовке Authorization: Bearer
+const APIKeyPrefix = "prk_"
+
+// GenerateAPIKey возвращает открытый ключ (показывается один раз) и его хеш для хранения.
+// Ключи имеют 256 бит энтропии, поэтому достаточно SHA-256 без соли.
+func GenerateAPIKey() (key, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	key = APIKeyPrefix + hex.EncodeToString(buf)
+	return key, HashAPIKey(key), nil
+}
+
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
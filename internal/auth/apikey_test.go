@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"pr-reviewer-service/internal/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memoryKeys - KeyStore в памяти, ключи по открытому значению
+type memoryKeys map[string]*models.APIKey
+
+func (m memoryKeys) GetAPIKeyByHash(hash string) (*models.APIKey, error) {
+	for key, apiKey := range m {
+		if HashAPIKey(key) == hash {
+			return apiKey, nil
+		}
+	}
+	return nil, fmt.Errorf("NOT_FOUND")
+}
+
+type failingKeys struct{}
+
+func (failingKeys) GetAPIKeyByHash(string) (*models.APIKey, error) {
+	return nil, fmt.Errorf("connection refused")
+}
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, hash, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(key, APIKeyPrefix) || len(key) != len(APIKeyPrefix)+64 {
+		t.Errorf("key = %q", key)
+	}
+	if hash != HashAPIKey(key) || hash == key {
+		t.Errorf("hash = %q", hash)
+	}
+
+	other, _, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == key {
+		t.Error("GenerateAPIKey() returned the same key twice")
+	}
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	revokedAt := time.Now()
+	keys := memoryKeys{
+		"prk_member":  {Name: "ci", Role: "bot"},
+		"prk_lead":    {Name: "lead", Role: "team-lead", UserID: "u1", TeamName: "backend"},
+		"prk_revoked": {Name: "old", Role: "admin", RevokedAt: &revokedAt},
+		"prk_role":    {Name: "broken", Role: "root"},
+	}
+	a := NewAuthenticator(keys, Options{BootstrapAPIKey: "bootstrap-secret"})
+
+	tests := []struct {
+		name    string
+		header  http.Header
+		subject string
+		role    Role
+		err     string
+	}{
+		{"X-API-Key", http.Header{"X-Api-Key": {"prk_member"}}, "ci", RoleBot, ""},
+		{"Bearer API key", http.Header{"Authorization": {"Bearer prk_lead"}}, "lead", RoleTeamLead, ""},
+		{"ApiKey scheme", http.Header{"Authorization": {"ApiKey prk_member"}}, "ci", RoleBot, ""},
+		{"bootstrap key", http.Header{"X-Api-Key": {"bootstrap-secret"}}, "bootstrap", RoleAdmin, ""},
+		{"revoked key", http.Header{"X-Api-Key": {"prk_revoked"}}, "", "", "UNAUTHORIZED"},
+		{"unknown key", http.Header{"X-Api-Key": {"prk_unknown"}}, "", "", "UNAUTHORIZED"},
+		{"unknown role", http.Header{"X-Api-Key": {"prk_role"}}, "", "", "UNAUTHORIZED"},
+		{"bootstrap prefix", http.Header{"X-Api-Key": {"bootstrap-secret-x"}}, "", "", "UNAUTHORIZED"},
+		{"no credentials", http.Header{}, "", "", "UNAUTHORIZED"},
+		{"unknown scheme", http.Header{"Authorization": {"Basic dTpw"}}, "", "", "UNAUTHORIZED"},
+		{"bearer JWT without verifier", http.Header{"Authorization": {"Bearer a.b.c"}}, "", "", "UNAUTHORIZED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := a.AuthenticateHeader(tt.header)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("AuthenticateHeader() = %+v, %v; want %s", p, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AuthenticateHeader() error = %v", err)
+			}
+			if p.Subject != tt.subject || p.Role != tt.role || p.Method != "api_key" {
+				t.Errorf("AuthenticateHeader() = %+v", p)
+			}
+		})
+	}
+
+	lead, err := a.AuthenticateHeader(http.Header{"X-Api-Key": {"prk_lead"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lead.UserID != "u1" || lead.TeamName != "backend" {
+		t.Errorf("team-lead principal = %+v", lead)
+	}
+}
+
+func TestAuthenticateStoreError(t *testing.T) {
+	a := NewAuthenticator(failingKeys{}, Options{})
+	_, err := a.AuthenticateHeader(http.Header{"X-Api-Key": {"prk_any"}})
+	if err == nil || err.Error() == "UNAUTHORIZED" {
+		t.Fatalf("store error = %v, want it passed through", err)
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/team/get", nil)
+	req.Header.Set("X-API-Key", "prk_any")
+	a.Middleware(map[string]Policy{"/team/get": {Roles: []Role{RoleAdmin}}}, okHandler()).ServeHTTP(rec, req)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", rec.Code)
+	}
+}
+
+func okHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p := FromContext(r.Context()); p != nil {
+			w.Header().Set("X-Subject", p.Subject)
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func TestMiddlewarePolicies(t *testing.T) {
+	keys := memoryKeys{
+		"prk_admin":  {Name: "admin", Role: "admin"},
+		"prk_lead":   {Name: "lead", Role: "team-lead"},
+		"prk_member": {Name: "member", Role: "member"},
+		"prk_bot":    {Name: "bot", Role: "bot"},
+	}
+	policies := map[string]Policy{
+		"/health":        {Public: true},
+		"/admin":         {Roles: []Role{RoleAdmin}},
+		"/leads":         {Roles: []Role{RoleAdmin, RoleTeamLead}},
+		"/humans":        {Roles: []Role{RoleAdmin, RoleTeamLead, RoleMember}},
+		"/everyone":      {Roles: []Role{RoleAdmin, RoleTeamLead, RoleMember, RoleBot}},
+		"/misconfigured": {},
+	}
+	handler := NewAuthenticator(keys, Options{}).Middleware(policies, okHandler())
+
+	// Ожидаемый статус для каждого ключа: admin, lead, member, bot, без ключа
+	matrix := map[string][5]int{
+		"/health":        {200, 200, 200, 200, 200},
+		"/admin":         {200, 403, 403, 403, 401},
+		"/leads":         {200, 200, 403, 403, 401},
+		"/humans":        {200, 200, 200, 403, 401},
+		"/everyone":      {200, 200, 200, 200, 401},
+		"/misconfigured": {403, 403, 403, 403, 401},
+		"/unregistered":  {403, 403, 403, 403, 401},
+	}
+	callers := []string{"prk_admin", "prk_lead", "prk_member", "prk_bot", ""}
+
+	for path, want := range matrix {
+		for i, key := range callers {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			if key != "" {
+				req.Header.Set("X-API-Key", key)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != want[i] {
+				t.Errorf("%s with %q: status = %d, want %d", path, key, rec.Code, want[i])
+			}
+			if rec.Code == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Errorf("%s with %q: 401 without WWW-Authenticate", path, key)
+			}
+			if rec.Code == http.StatusOK && key != "" && !policies[path].Public && rec.Header().Get("X-Subject") == "" {
+				t.Errorf("%s with %q: principal is not in request context", path, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"pr-reviewer-service/internal/models"
+	"strings"
+)
+
+type Role string
+
+const (
+	RoleAdmin    Role = "admin"
+	RoleTeamLead Role = "team-lead"
+	RoleMember   Role = "member"
+	RoleBot      Role = "bot"
+)
+
+func ParseRole(s string) (Role, error) {
+	switch Role(s) {
+	case RoleAdmin, RoleTeamLead, RoleMember, RoleBot:
+		return Role(s), nil
+	}
+	return "", fmt.Errorf("INVALID_ROLE")
+}
+
+// Principal - аутентифицированный вызывающий
+type Principal struct {
+	// Subject - user_id из JWT или имя API-ключа
+	Subject  string `json:"subject"`
+	UserID   string `json:"user_id,omitempty"`
+	TeamName string `json:"team_name,omitempty"`
+	Role     Role   `json:"role"`
+	Method   string `json:"method"`
+}
+
+type contextKey struct{}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext возвращает nil, если запрос не аутентифицирован (например, аутентификация выключена)
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(contextKey{}).(*Principal)
+	return p
+}
+
+// Policy описывает, кому разрешён маршрут
+type Policy struct {
+	Public bool
+	Roles  []Role
+}
+
+func (p Policy) Allows(role Role) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// KeyStore ищет API-ключи по хешу
+type KeyStore interface {
+	GetAPIKeyByHash(hash string) (*models.APIKey, error)
+}
+
+type Options struct {
+	BootstrapAPIKey string
+	JWT             *JWTVerifier
+}
+
+type Authenticator struct {
+	keys      KeyStore
+	bootstrap string
+	jwt       *JWTVerifier
+}
+
+func NewAuthenticator(keys KeyStore, opts Options) *Authenticator {
+	return &Authenticator{
+		keys:      keys,
+		bootstrap: opts.BootstrapAPIKey,
+		jwt:       opts.JWT,
+	}
+}
+
+// Authenticate извлекает учётные данные из заголовков X-API-Key или Authorization
+func (a *Authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	return a.AuthenticateHeader(r.Header)
+}
+
+// AuthenticateHeader - то же для заголовков не из HTTP-запроса (метаданные gRPC)
+func (a *Authenticator) AuthenticateHeader(h http.Header) (*Principal, error) {
+	if key := h.Get("X-API-Key"); key != "" {
+		return a.authenticateAPIKey(key)
+	}
+
+	header := h.Get("Authorization")
+	if header == "" {
+		return nil, fmt.Errorf("UNAUTHORIZED")
+	}
+
+	scheme, credentials, ok := strings.Cut(header, " ")
+	if !ok {
+		return nil, fmt.Errorf("UNAUTHORIZED")
+	}
+	credentials = strings.TrimSpace(credentials)
+
+	switch strings.ToLower(scheme) {
+	case "apikey":
+		return a.authenticateAPIKey(credentials)
+	case "bearer":
+		if strings.HasPrefix(credentials, APIKeyPrefix) {
+			return a.authenticateAPIKey(credentials)
+		}
+		if a.jwt == nil {
+			return nil, fmt.Errorf("UNAUTHORIZED")
+		}
+		return a.jwt.Verify(credentials)
+	}
+	return nil, fmt.Errorf("UNAUTHORIZED")
+}
+
+func (a *Authenticator) authenticateAPIKey(key string) (*Principal, error) {
+	if a.bootstrap != "" && subtle.ConstantTimeCompare([]byte(key), []byte(a.bootstrap)) == 1 {
+		return &Principal{Subject: "bootstrap", Role: RoleAdmin, Method: "api_key"}, nil
+	}
+
+	apiKey, err := a.keys.GetAPIKeyByHash(HashAPIKey(key))
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			return nil, fmt.Errorf("UNAUTHORIZED")
+		}
+		return nil, err
+	}
+	if apiKey.RevokedAt != nil {
+		return nil, fmt.Errorf("UNAUTHORIZED")
+	}
+
+	role, err := ParseRole(apiKey.Role)
+	if err != nil {
+		return nil, fmt.Errorf("UNAUTHORIZED")
+	}
+
+	return &Principal{
+		Subject:  apiKey.Name,
+		UserID:   apiKey.UserID,
+		TeamName: apiKey.TeamName,
+		Role:     role,
+		Method:   "api_key",
+	}, nil
+}
+
+// Middleware проверяет учётные данные и политику маршрута.
+// Маршруты без политики запрещены.
+func (a *Authenticator) Middleware(policies map[string]Policy, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		policy, ok := policies[r.URL.Path]
+		if ok && policy.Public {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		principal, err := a.Authenticate(r)
+		if err != nil {
+			if err.Error() == "UNAUTHORIZED" {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="pr-reviewer-service"`)
+				sendErrorResponse(w, "UNAUTHORIZED", "missing or invalid credentials", http.StatusUnauthorized)
+				return
+			}
+			sendErrorResponse(w, "INTERNAL", err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if !ok || !policy.Allows(principal.Role) {
+			sendErrorResponse(w, "FORBIDDEN", "role is not allowed to call this endpoint", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+	})
+}
+
+func sendErrorResponse(w http.ResponseWriter, code, message string, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	errorResp := models.ErrorResponse{}
+	errorResp.Error.Code = code
+	errorResp.Error.Message = message
+
+	json.NewEncoder(w).Encode(errorResp)
+}
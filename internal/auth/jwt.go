@@ -0,0 +1,302 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+// JWTVerifier проверяет bearer-токены: HS* по общему секрету, RS*/ES* по ключам из JWKS-файла.
+//
+// Ожидаемые claims: sub (user_id), role, необязательный team_name, exp, nbf, iss, aud.
+type JWTVerifier struct {
+	secret   []byte
+	keys     map[string]crypto.PublicKey
+	issuer   string
+	audience string
+	leeway   time.Duration
+	now      func() time.Time
+}
+
+type JWTOptions struct {
+	Secret   string
+	JWKSFile string
+	Issuer   string
+	Audience string
+	Leeway   time.Duration
+}
+
+func NewJWTVerifier(opts JWTOptions) (*JWTVerifier, error) {
+	v := &JWTVerifier{
+		secret:   []byte(opts.Secret),
+		keys:     make(map[string]crypto.PublicKey),
+		issuer:   opts.Issuer,
+		audience: opts.Audience,
+		leeway:   opts.Leeway,
+		now:      time.Now,
+	}
+
+	if opts.JWKSFile != "" {
+		data, err := os.ReadFile(opts.JWKSFile)
+		if err != nil {
+			return nil, fmt.Errorf("read jwks: %w", err)
+		}
+		if err := v.loadJWKS(data); err != nil {
+			return nil, fmt.Errorf("parse jwks: %w", err)
+		}
+	}
+
+	if len(v.secret) == 0 && len(v.keys) == 0 {
+		return nil, fmt.Errorf("jwt: neither secret nor JWKS keys configured")
+	}
+	return v, nil
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (v *JWTVerifier) loadJWKS(data []byte) error {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return err
+	}
+
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		switch k.Kty {
+		case "RSA":
+			n, err := decodeBigInt(k.N)
+			if err != nil {
+				return fmt.Errorf("key %q: n: %w", k.Kid, err)
+			}
+			e, err := decodeBigInt(k.E)
+			if err != nil {
+				return fmt.Errorf("key %q: e: %w", k.Kid, err)
+			}
+			v.keys[k.Kid] = &rsa.PublicKey{N: n, E: int(e.Int64())}
+		case "EC":
+			var curve elliptic.Curve
+			switch k.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			case "P-521":
+				curve = elliptic.P521()
+			default:
+				return fmt.Errorf("key %q: unsupported curve %q", k.Kid, k.Crv)
+			}
+			x, err := decodeBigInt(k.X)
+			if err != nil {
+				return fmt.Errorf("key %q: x: %w", k.Kid, err)
+			}
+			y, err := decodeBigInt(k.Y)
+			if err != nil {
+				return fmt.Errorf("key %q: y: %w", k.Kid, err)
+			}
+			if !curve.IsOnCurve(x, y) {
+				return fmt.Errorf("key %q: point is not on curve", k.Kid)
+			}
+			v.keys[k.Kid] = &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		default:
+			return fmt.Errorf("key %q: unsupported kty %q", k.Kid, k.Kty)
+		}
+	}
+	return nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type jwtClaims struct {
+	Subject   string          `json:"sub"`
+	Role      string          `json:"role"`
+	TeamName  string          `json:"team_name"`
+	Issuer    string          `json:"iss"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt *int64          `json:"exp"`
+	NotBefore *int64          `json:"nbf"`
+}
+
+// Verify возвращает Principal для валидного токена или ошибку UNAUTHORIZED
+func (v *JWTVerifier) Verify(token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("UNAUTHORIZED")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("UNAUTHORIZED")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("UNAUTHORIZED")
+	}
+	if !v.verifySignature(header, []byte(parts[0]+"."+parts[1]), signature) {
+		return nil, fmt.Errorf("UNAUTHORIZED")
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("UNAUTHORIZED")
+	}
+	if err := v.validateClaims(&claims); err != nil {
+		return nil, err
+	}
+
+	role, err := ParseRole(claims.Role)
+	if err != nil {
+		return nil, fmt.Errorf("UNAUTHORIZED")
+	}
+
+	return &Principal{
+		Subject:  claims.Subject,
+		UserID:   claims.Subject,
+		TeamName: claims.TeamName,
+		Role:     role,
+		Method:   "jwt",
+	}, nil
+}
+
+func decodeSegment(seg string, dst interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
+
+// esCurves - кривая, которую требует каждый ES-алгоритм (RFC 7518, 3.4)
+var esCurves = map[string]elliptic.Curve{
+	"ES256": elliptic.P256(),
+	"ES384": elliptic.P384(),
+	"ES512": elliptic.P521(),
+}
+
+func (v *JWTVerifier) verifySignature(header jwtHeader, signed, signature []byte) bool {
+	if len(header.Alg) != 5 {
+		return false
+	}
+
+	var newHash func() hash.Hash
+	var cryptoHash crypto.Hash
+	switch header.Alg[2:] {
+	case "256":
+		newHash, cryptoHash = sha256.New, crypto.SHA256
+	case "384":
+		newHash, cryptoHash = sha512.New384, crypto.SHA384
+	case "512":
+		newHash, cryptoHash = sha512.New, crypto.SHA512
+	default:
+		return false
+	}
+
+	switch header.Alg {
+	case "HS256", "HS384", "HS512":
+		if len(v.secret) == 0 {
+			return false
+		}
+		mac := hmac.New(newHash, v.secret)
+		mac.Write(signed)
+		return hmac.Equal(mac.Sum(nil), signature)
+
+	case "RS256", "RS384", "RS512":
+		key, ok := v.keys[header.Kid].(*rsa.PublicKey)
+		if !ok {
+			return false
+		}
+		h := newHash()
+		h.Write(signed)
+		return rsa.VerifyPKCS1v15(key, cryptoHash, h.Sum(nil), signature) == nil
+
+	case "ES256", "ES384", "ES512":
+		key, ok := v.keys[header.Kid].(*ecdsa.PublicKey)
+		if !ok || key.Curve != esCurves[header.Alg] {
+			return false
+		}
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		h := newHash()
+		h.Write(signed)
+		return ecdsa.Verify(key, h.Sum(nil), r, s)
+	}
+	return false
+}
+
+func (v *JWTVerifier) validateClaims(c *jwtClaims) error {
+	now := v.now()
+
+	if c.Subject == "" {
+		return fmt.Errorf("UNAUTHORIZED")
+	}
+	if c.ExpiresAt == nil || now.After(time.Unix(*c.ExpiresAt, 0).Add(v.leeway)) {
+		return fmt.Errorf("UNAUTHORIZED")
+	}
+	if c.NotBefore != nil && now.Add(v.leeway).Before(time.Unix(*c.NotBefore, 0)) {
+		return fmt.Errorf("UNAUTHORIZED")
+	}
+	if v.issuer != "" && c.Issuer != v.issuer {
+		return fmt.Errorf("UNAUTHORIZED")
+	}
+	if v.audience != "" && !audienceContains(c.Audience, v.audience) {
+		return fmt.Errorf("UNAUTHORIZED")
+	}
+	return nil
+}
+
+// aud может быть строкой или массивом строк
+func audienceContains(raw json.RawMessage, audience string) bool {
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return single == audience
+	}
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		for _, a := range list {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
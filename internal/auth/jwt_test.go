@@ -0,0 +1,260 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+const testSecret = "test-secret-0123456789"
+
+type testKeys struct {
+	rsa  *rsa.PrivateKey
+	p256 *ecdsa.PrivateKey
+	p384 *ecdsa.PrivateKey
+}
+
+func newTestKeys(t *testing.T) *testKeys {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testKeys{rsa: rsaKey, p256: p256, p384: p384}
+}
+
+// writeJWKS сохраняет открытые ключи под kid "rsa", "p256" и "p384"
+func (k *testKeys) writeJWKS(t *testing.T) string {
+	t.Helper()
+	b64 := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	ecKey := func(kid, crv string, key *ecdsa.PrivateKey) map[string]string {
+		return map[string]string{"kty": "EC", "kid": kid, "use": "sig", "crv": crv,
+			"x": b64(key.X.Bytes()), "y": b64(key.Y.Bytes())}
+	}
+	set := map[string]interface{}{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa", "use": "sig",
+			"n": b64(k.rsa.N.Bytes()), "e": b64(big.NewInt(int64(k.rsa.E)).Bytes())},
+		ecKey("p256", "P-256", k.p256),
+		ecKey("p384", "P-384", k.p384),
+	}}
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func newTestVerifier(t *testing.T, opts JWTOptions) *JWTVerifier {
+	t.Helper()
+	v, err := NewJWTVerifier(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.now = func() time.Time { return testNow }
+	return v
+}
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub":  "u1",
+		"role": "member",
+		"exp":  testNow.Add(time.Hour).Unix(),
+	}
+}
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// sign собирает токен; key - []byte для HS*, *rsa.PrivateKey для RS*, *ecdsa.PrivateKey для ES*, nil - без подписи
+func sign(t *testing.T, header map[string]string, claims map[string]interface{}, key interface{}) string {
+	t.Helper()
+	signed := encodeSegment(t, header) + "." + encodeSegment(t, claims)
+
+	hashes := map[string]crypto.Hash{"256": crypto.SHA256, "384": crypto.SHA384, "512": crypto.SHA512}
+	h := hashes[header["alg"][len(header["alg"])-3:]]
+	digest := func() []byte {
+		d := h.New()
+		d.Write([]byte(signed))
+		return d.Sum(nil)
+	}
+
+	var signature []byte
+	switch key := key.(type) {
+	case nil:
+	case []byte:
+		mac := hmac.New(h.New, key)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		var err error
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, h, digest())
+		if err != nil {
+			t.Fatal(err)
+		}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest())
+		if err != nil {
+			t.Fatal(err)
+		}
+		size := (key.Curve.Params().BitSize + 7) / 8
+		signature = make([]byte, 2*size)
+		r.FillBytes(signature[:size])
+		s.FillBytes(signature[size:])
+	default:
+		t.Fatalf("unsupported key %T", key)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestJWTVerify(t *testing.T) {
+	keys := newTestKeys(t)
+	v := newTestVerifier(t, JWTOptions{
+		Secret:   testSecret,
+		JWKSFile: keys.writeJWKS(t),
+		Issuer:   "https://sso.example.com",
+		Audience: "pr-reviewer-service",
+		Leeway:   30 * time.Second,
+	})
+
+	rsaPublic, err := x509.MarshalPKIXPublicKey(&keys.rsa.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	with := func(changes map[string]interface{}) map[string]interface{} {
+		c := validClaims()
+		c["iss"] = "https://sso.example.com"
+		c["aud"] = []string{"other", "pr-reviewer-service"}
+		for k, val := range changes {
+			if val == nil {
+				delete(c, k)
+				continue
+			}
+			c[k] = val
+		}
+		return c
+	}
+
+	tests := []struct {
+		name   string
+		header map[string]string
+		claims map[string]interface{}
+		key    interface{}
+		ok     bool
+	}{
+		{"HS256", map[string]string{"alg": "HS256"}, with(nil), []byte(testSecret), true},
+		{"HS512", map[string]string{"alg": "HS512"}, with(nil), []byte(testSecret), true},
+		{"RS256", map[string]string{"alg": "RS256", "kid": "rsa"}, with(nil), keys.rsa, true},
+		{"ES256 on P-256", map[string]string{"alg": "ES256", "kid": "p256"}, with(nil), keys.p256, true},
+		{"ES384 on P-384", map[string]string{"alg": "ES384", "kid": "p384"}, with(nil), keys.p384, true},
+
+		{"alg none", map[string]string{"alg": "none"}, with(nil), nil, false},
+		{"alg None without signature", map[string]string{"alg": "None"}, with(nil), nil, false},
+		{"wrong HS secret", map[string]string{"alg": "HS256"}, with(nil), []byte("other"), false},
+		{"HS256 signed with RSA public key", map[string]string{"alg": "HS256", "kid": "rsa"}, with(nil), rsaPublic, false},
+		{"RS256 with EC key", map[string]string{"alg": "RS256", "kid": "p256"}, with(nil), keys.rsa, false},
+		{"ES256 with RSA key", map[string]string{"alg": "ES256", "kid": "rsa"}, with(nil), keys.p256, false},
+		{"ES256 on P-384 key", map[string]string{"alg": "ES256", "kid": "p384"}, with(nil), keys.p384, false},
+		{"ES384 on P-256 key", map[string]string{"alg": "ES384", "kid": "p256"}, with(nil), keys.p256, false},
+		{"unknown kid", map[string]string{"alg": "RS256", "kid": "rotated"}, with(nil), keys.rsa, false},
+		{"missing kid", map[string]string{"alg": "RS256"}, with(nil), keys.rsa, false},
+
+		{"expired within leeway", map[string]string{"alg": "HS256"}, with(map[string]interface{}{"exp": testNow.Add(-20 * time.Second).Unix()}), []byte(testSecret), true},
+		{"expired beyond leeway", map[string]string{"alg": "HS256"}, with(map[string]interface{}{"exp": testNow.Add(-time.Minute).Unix()}), []byte(testSecret), false},
+		{"no exp", map[string]string{"alg": "HS256"}, with(map[string]interface{}{"exp": nil}), []byte(testSecret), false},
+		{"nbf within leeway", map[string]string{"alg": "HS256"}, with(map[string]interface{}{"nbf": testNow.Add(20 * time.Second).Unix()}), []byte(testSecret), true},
+		{"nbf beyond leeway", map[string]string{"alg": "HS256"}, with(map[string]interface{}{"nbf": testNow.Add(time.Minute).Unix()}), []byte(testSecret), false},
+		{"wrong issuer", map[string]string{"alg": "HS256"}, with(map[string]interface{}{"iss": "https://evil.example.com"}), []byte(testSecret), false},
+		{"audience string", map[string]string{"alg": "HS256"}, with(map[string]interface{}{"aud": "pr-reviewer-service"}), []byte(testSecret), true},
+		{"wrong audience", map[string]string{"alg": "HS256"}, with(map[string]interface{}{"aud": "other"}), []byte(testSecret), false},
+		{"no subject", map[string]string{"alg": "HS256"}, with(map[string]interface{}{"sub": ""}), []byte(testSecret), false},
+		{"unknown role", map[string]string{"alg": "HS256"}, with(map[string]interface{}{"role": "root"}), []byte(testSecret), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := sign(t, tt.header, tt.claims, tt.key)
+			p, err := v.Verify(token)
+			if !tt.ok {
+				if err == nil || err.Error() != "UNAUTHORIZED" {
+					t.Fatalf("Verify() = %+v, %v; want UNAUTHORIZED", p, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if p.UserID != "u1" || p.Role != RoleMember || p.Method != "jwt" {
+				t.Errorf("Verify() = %+v", p)
+			}
+		})
+	}
+}
+
+func TestJWTVerifyMalformed(t *testing.T) {
+	v := newTestVerifier(t, JWTOptions{Secret: testSecret})
+	valid := sign(t, map[string]string{"alg": "HS256"}, validClaims(), []byte(testSecret))
+
+	for _, token := range []string{
+		"",
+		"a.b",
+		"a.b.c.d",
+		"!!.!!.!!",
+		valid + "x",
+		valid[:len(valid)-4],
+	} {
+		if _, err := v.Verify(token); err == nil {
+			t.Errorf("Verify(%q) accepted malformed token", token)
+		}
+	}
+}
+
+func TestJWTVerifierWithoutSecretRejectsHS(t *testing.T) {
+	keys := newTestKeys(t)
+	v := newTestVerifier(t, JWTOptions{JWKSFile: keys.writeJWKS(t)})
+
+	// Пустой секрет не должен превращаться в известный атакующему ключ HMAC
+	token := sign(t, map[string]string{"alg": "HS256"}, validClaims(), []byte{})
+	if _, err := v.Verify(token); err == nil {
+		t.Fatal("HS256 token accepted without configured secret")
+	}
+}
+
+func TestNewJWTVerifierRequiresKeys(t *testing.T) {
+	if _, err := NewJWTVerifier(JWTOptions{}); err == nil {
+		t.Fatal("NewJWTVerifier() without secret and JWKS succeeded")
+	}
+}
@@ -0,0 +1,258 @@
+package chatops
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"pr-reviewer-service/internal/audit"
+	"pr-reviewer-service/internal/models"
+	"pr-reviewer-service/internal/service"
+	"strings"
+	"time"
+)
+
+const maxBodySize = 64 << 10
+
+// Service - методы сервиса, которые вызывают команды; реализуется *service.PRService
+type Service interface {
+	ResolveChatUser(chatUserID string) (string, error)
+	GetUserReviewPRs(filter models.ReviewFilter) (*models.ReviewPage, error)
+	ReassignReviewer(ctx context.Context, prID, oldUserID string) (*models.PullRequest, string, error)
+	SetUserActive(ctx context.Context, userID string, isActive bool) (*models.User, error)
+	GetTeamSummary(teamName string) (*models.TeamSummary, error)
+	GetTeamReviewLoad(teamName string) ([]models.ReviewerLoad, error)
+}
+
+var _ Service = (*service.PRService)(nil)
+
+// Handler принимает slash-команды /review из Slack или Mattermost:
+//
+//	/review mine
+//	/review reassign <pr> <user>
+//	/review ooo on|off
+//	/review stats <team>
+type Handler struct {
+	service Service
+	secret  []byte
+	maxSkew time.Duration
+	// Now подменяется при проверке на записанных запросах со старой меткой времени
+	Now func() time.Time
+}
+
+func NewHandler(service Service, signingSecret string, maxSkew time.Duration) *Handler {
+	return &Handler{
+		service: service,
+		secret:  []byte(signingSecret),
+		maxSkew: maxSkew,
+		Now:     time.Now,
+	}
+}
+
+// Response - ответ в формате Slack; Mattermost использует поле text
+type Response struct {
+	ResponseType string  `json:"response_type"`
+	Text         string  `json:"text"`
+	Blocks       []Block `json:"blocks,omitempty"`
+}
+
+type Block struct {
+	Type string     `json:"type"`
+	Text *BlockText `json:"text,omitempty"`
+}
+
+type BlockText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+	if err != nil || len(body) > maxBodySize {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err = verifySignature(h.secret, r.Header.Get("X-Slack-Request-Timestamp"),
+		r.Header.Get("X-Slack-Signature"), body, h.Now(), h.maxSkew)
+	if err != nil {
+		log.Printf("chatops: rejected request: %v", err)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	resp := h.execute(r.Context(), form.Get("user_id"), form.Get("text"))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) execute(ctx context.Context, chatUserID, text string) Response {
+	userID, err := h.service.ResolveChatUser(chatUserID)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			return reply("Your chat account is not linked to a reviewer. Ask an admin to link it.")
+		}
+		return internalError(err)
+	}
+	ctx = audit.WithActor(ctx, userID)
+
+	args := strings.Fields(text)
+	if len(args) == 0 {
+		return reply(usage)
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "mine":
+		return h.mine(userID)
+	case "reassign":
+		if len(args) != 3 {
+			return reply("Usage: `/review reassign <pr> <user>`")
+		}
+		return h.reassign(ctx, args[1], args[2])
+	case "ooo":
+		if len(args) != 2 || (args[1] != "on" && args[1] != "off") {
+			return reply("Usage: `/review ooo on|off`")
+		}
+		return h.ooo(ctx, userID, args[1] == "on")
+	case "stats":
+		if len(args) != 2 {
+			return reply("Usage: `/review stats <team>`")
+		}
+		return h.stats(args[1])
+	}
+	return reply(usage)
+}
+
+const usage = "Commands:\n" +
+	"`/review mine` - your open review queue\n" +
+	"`/review reassign <pr> <user>` - replace a reviewer\n" +
+	"`/review ooo on|off` - pause or resume review assignments\n" +
+	"`/review stats <team>` - team review load"
+
+func (h *Handler) mine(userID string) Response {
+	page, err := h.service.GetUserReviewPRs(models.ReviewFilter{
+		UserID:   userID,
+		Statuses: []string{"OPEN"},
+		Limit:    20,
+	})
+	if err != nil {
+		return internalError(err)
+	}
+	if len(page.PullRequests) == 0 {
+		return reply("No open pull requests are waiting for your review :tada:")
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "*%d open pull requests waiting for your review* (oldest first)\n", page.Total)
+	now := h.Now()
+	for _, pr := range page.PullRequests {
+		fmt.Fprintf(&b, "• `%s` %s by %s, open %s", pr.PullRequestID, pr.PullRequestName, pr.AuthorID, age(now, pr.CreatedAt))
+		if pr.Overdue {
+			b.WriteString(" :warning: overdue")
+		}
+		b.WriteString("\n")
+	}
+	if page.Total > len(page.PullRequests) {
+		fmt.Fprintf(&b, "_...and %d more_", page.Total-len(page.PullRequests))
+	}
+	return reply(b.String())
+}
+
+func (h *Handler) reassign(ctx context.Context, prID, oldUserID string) Response {
+	_, newUserID, err := h.service.ReassignReviewer(ctx, prID, oldUserID)
+	if err != nil {
+		switch err.Error() {
+		case "NOT_FOUND":
+			return reply(fmt.Sprintf("Pull request `%s` or user `%s` not found.", prID, oldUserID))
+		case "PR_MERGED":
+			return reply(fmt.Sprintf("`%s` is already merged, reviewers can't be changed.", prID))
+		case "NOT_ASSIGNED":
+			return reply(fmt.Sprintf("`%s` is not a reviewer of `%s`.", oldUserID, prID))
+		case "NO_CANDIDATE":
+			return reply("No active replacement candidate in the team.")
+		case "PR_MODIFIED":
+			return reply(fmt.Sprintf("`%s` was just changed by someone else, please try again.", prID))
+		}
+		return internalError(err)
+	}
+	return reply(fmt.Sprintf("`%s`: reviewer `%s` replaced by `%s`.", prID, oldUserID, newUserID))
+}
+
+func (h *Handler) ooo(ctx context.Context, userID string, on bool) Response {
+	if _, err := h.service.SetUserActive(ctx, userID, !on); err != nil {
+		return internalError(err)
+	}
+	if on {
+		return reply("You are out of office and won't get new review assignments.")
+	}
+	return reply("Welcome back! You will get review assignments again.")
+}
+
+func (h *Handler) stats(teamName string) Response {
+	team, err := h.service.GetTeamSummary(teamName)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			return reply(fmt.Sprintf("Team `%s` not found.", teamName))
+		}
+		return internalError(err)
+	}
+	load, err := h.service.GetTeamReviewLoad(teamName)
+	if err != nil {
+		return internalError(err)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "*Team %s*: %d members (%d active), %d open pull requests\n",
+		team.TeamName, team.MemberCount, team.ActiveCount, team.OpenPRCount)
+	for _, l := range load {
+		status := ""
+		if !l.IsActive {
+			status = " (inactive)"
+		}
+		fmt.Fprintf(&b, "• `%s`%s: %d open reviews\n", l.UserID, status, l.OpenReviews)
+	}
+	return reply(b.String())
+}
+
+func reply(text string) Response {
+	return Response{
+		ResponseType: "ephemeral",
+		Text:         text,
+		Blocks: []Block{{
+			Type: "section",
+			Text: &BlockText{Type: "mrkdwn", Text: text},
+		}},
+	}
+}
+
+func internalError(err error) Response {
+	log.Printf("chatops: %v", err)
+	return reply("Something went wrong, please try again later.")
+}
+
+func age(now time.Time, createdAt *time.Time) string {
+	if createdAt == nil {
+		return "n/a"
+	}
+	d := now.Sub(*createdAt)
+	if days := int(d.Hours()) / 24; days > 0 {
+		return fmt.Sprintf("%dd %dh", days, int(d.Hours())%24)
+	}
+	return fmt.Sprintf("%dh %dm", int(d.Hours()), int(d.Minutes())%60)
+}
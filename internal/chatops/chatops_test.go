@@ -0,0 +1,286 @@
+package chatops
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"pr-reviewer-service/internal/audit"
+	"pr-reviewer-service/internal/models"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Запрос из документации Slack по проверке подписи: секрет, метка времени, тело и подпись как есть
+const (
+	recordedSecret    = "8f742231b10e8888abcd99yyyzzz85a5"
+	recordedTimestamp = "1531420618"
+	recordedSignature = "v0=a2114d57b48eac39b9ad189dd8316235a7b4a8d21a10bd27519666489c69b503"
+	recordedBody      = "token=xyzz0WbapA4vBCDEFasx0q6G&team_id=T1DC2JH3J&team_domain=testteamnow" +
+		"&channel_id=G8PSS9T3V&channel_name=foobar&user_id=U2CERLKJA&user_name=roadrunner" +
+		"&command=%2Fwebhook-collect&text=" +
+		"&response_url=https%3A%2F%2Fhooks.slack.com%2Fcommands%2FT1DC2JH3J%2F397700885554%2F96rGlfmibIGlgcZRskXaIFfN" +
+		"&trigger_id=398738663015.47445629121.803a0bc887a14d10d2c447fce8b6703c"
+)
+
+var recordedTime = time.Unix(1531420618, 0)
+
+// fakeService отвечает как сервис с одним связанным пользователем чата U2CERLKJA -> u1
+type fakeService struct {
+	reassigned []string
+	active     map[string]bool
+	actors     []string
+}
+
+func (f *fakeService) ResolveChatUser(chatUserID string) (string, error) {
+	if chatUserID == "U2CERLKJA" {
+		return "u1", nil
+	}
+	return "", fmt.Errorf("NOT_FOUND")
+}
+
+func (f *fakeService) GetUserReviewPRs(filter models.ReviewFilter) (*models.ReviewPage, error) {
+	if filter.UserID != "u1" || len(filter.Statuses) != 1 || filter.Statuses[0] != "OPEN" {
+		return nil, fmt.Errorf("unexpected filter %+v", filter)
+	}
+	created := recordedTime.Add(-(2*24 + 3) * time.Hour)
+	recent := recordedTime.Add(-90 * time.Minute)
+	return &models.ReviewPage{
+		PullRequests: []models.PullRequestShort{
+			{PullRequestID: "pr-1", PullRequestName: "Add search", AuthorID: "u2", Status: "OPEN", CreatedAt: &created, Overdue: true},
+			{PullRequestID: "pr-2", PullRequestName: "Fix login", AuthorID: "u3", Status: "OPEN", CreatedAt: &recent},
+		},
+		Total: 3,
+	}, nil
+}
+
+func (f *fakeService) ReassignReviewer(ctx context.Context, prID, oldUserID string) (*models.PullRequest, string, error) {
+	f.actors = append(f.actors, audit.Actor(ctx))
+	switch {
+	case prID == "pr-merged":
+		return nil, "", fmt.Errorf("PR_MERGED")
+	case oldUserID == "u9":
+		return nil, "", fmt.Errorf("NOT_ASSIGNED")
+	}
+	f.reassigned = append(f.reassigned, prID+":"+oldUserID)
+	return &models.PullRequest{PullRequestID: prID}, "u4", nil
+}
+
+func (f *fakeService) SetUserActive(ctx context.Context, userID string, isActive bool) (*models.User, error) {
+	f.actors = append(f.actors, audit.Actor(ctx))
+	if f.active == nil {
+		f.active = map[string]bool{}
+	}
+	f.active[userID] = isActive
+	return &models.User{UserID: userID, IsActive: isActive}, nil
+}
+
+func (f *fakeService) GetTeamSummary(teamName string) (*models.TeamSummary, error) {
+	if teamName != "backend" {
+		return nil, fmt.Errorf("NOT_FOUND")
+	}
+	return &models.TeamSummary{TeamName: "backend", MemberCount: 3, ActiveCount: 2, OpenPRCount: 4}, nil
+}
+
+func (f *fakeService) GetTeamReviewLoad(teamName string) ([]models.ReviewerLoad, error) {
+	return []models.ReviewerLoad{
+		{UserID: "u1", IsActive: true, OpenReviews: 3},
+		{UserID: "u2", IsActive: false, OpenReviews: 1},
+	}, nil
+}
+
+func newTestHandler(svc Service) *Handler {
+	h := NewHandler(svc, recordedSecret, 5*time.Minute)
+	h.Now = func() time.Time { return recordedTime }
+	return h
+}
+
+func sign(timestamp, body string) string {
+	mac := hmac.New(sha256.New, []byte(recordedSecret))
+	fmt.Fprintf(mac, "v0:%s:%s", timestamp, body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// withText - записанный запрос с другим текстом команды
+func withText(text string) string {
+	form, _ := url.ParseQuery(recordedBody)
+	form.Set("text", text)
+	return form.Encode()
+}
+
+func post(h http.Handler, timestamp, signature, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/chatops/command", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if timestamp != "" {
+		req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	}
+	if signature != "" {
+		req.Header.Set("X-Slack-Signature", signature)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func decodeResponse(t *testing.T, rec *httptest.ResponseRecorder) Response {
+	t.Helper()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	var resp Response
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.ResponseType != "ephemeral" || len(resp.Blocks) != 1 || resp.Blocks[0].Type != "section" ||
+		resp.Blocks[0].Text == nil || resp.Blocks[0].Text.Type != "mrkdwn" || resp.Blocks[0].Text.Text != resp.Text {
+		t.Errorf("response is not a single ephemeral mrkdwn section: %+v", resp)
+	}
+	return resp
+}
+
+func TestRecordedRequestAccepted(t *testing.T) {
+	rec := post(newTestHandler(&fakeService{}), recordedTimestamp, recordedSignature, recordedBody)
+	resp := decodeResponse(t, rec)
+	if resp.Text != usage {
+		t.Errorf("empty command text = %q, want usage", resp.Text)
+	}
+}
+
+func TestSignatureRejected(t *testing.T) {
+	ts := recordedTimestamp
+	tampered := strings.Replace(recordedBody, "user_name=roadrunner", "user_name=coyote", 1)
+	stale := strconv.FormatInt(recordedTime.Add(-6*time.Minute).Unix(), 10)
+	future := strconv.FormatInt(recordedTime.Add(6*time.Minute).Unix(), 10)
+
+	tests := []struct {
+		name      string
+		timestamp string
+		signature string
+		body      string
+	}{
+		{"tampered body", ts, recordedSignature, tampered},
+		{"wrong secret", ts, "v0=" + strings.Repeat("0", 64), recordedBody},
+		{"v1 scheme", ts, strings.Replace(recordedSignature, "v0=", "v1=", 1), recordedBody},
+		{"signature for other timestamp", stale, recordedSignature, recordedBody},
+		{"stale timestamp", stale, sign(stale, recordedBody), recordedBody},
+		{"timestamp from the future", future, sign(future, recordedBody), recordedBody},
+		{"invalid timestamp", "yesterday", sign("yesterday", recordedBody), recordedBody},
+		{"missing signature", ts, "", recordedBody},
+		{"missing timestamp", "", recordedSignature, recordedBody},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &fakeService{}
+			rec := post(newTestHandler(svc), tt.timestamp, tt.signature, tt.body)
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("status = %d, want 401", rec.Code)
+			}
+		})
+	}
+
+	// Метка времени на границе допустимого сдвига ещё принимается
+	edge := strconv.FormatInt(recordedTime.Add(-5*time.Minute).Unix(), 10)
+	if rec := post(newTestHandler(&fakeService{}), edge, sign(edge, recordedBody), recordedBody); rec.Code != http.StatusOK {
+		t.Errorf("timestamp within max skew: status = %d", rec.Code)
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/chatops/command", nil)
+	rec := httptest.NewRecorder()
+	newTestHandler(&fakeService{}).ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want 405", rec.Code)
+	}
+}
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"mine", []string{
+			"*3 open pull requests waiting for your review* (oldest first)\n",
+			"• `pr-1` Add search by u2, open 2d 3h :warning: overdue\n",
+			"• `pr-2` Fix login by u3, open 1h 30m\n",
+			"_...and 1 more_",
+		}},
+		{"  MINE  ", []string{"*3 open pull requests"}},
+		{"reassign pr-1 u2", []string{"`pr-1`: reviewer `u2` replaced by `u4`."}},
+		{"reassign pr-merged u2", []string{"`pr-merged` is already merged"}},
+		{"reassign pr-1 u9", []string{"`u9` is not a reviewer of `pr-1`."}},
+		{"reassign pr-1", []string{"Usage: `/review reassign <pr> <user>`"}},
+		{"ooo on", []string{"You are out of office"}},
+		{"ooo off", []string{"Welcome back!"}},
+		{"ooo maybe", []string{"Usage: `/review ooo on|off`"}},
+		{"stats backend", []string{
+			"*Team backend*: 3 members (2 active), 4 open pull requests\n",
+			"• `u1`: 3 open reviews\n",
+			"• `u2` (inactive): 1 open reviews\n",
+		}},
+		{"stats frontend", []string{"Team `frontend` not found."}},
+		{"stats", []string{"Usage: `/review stats <team>`"}},
+		{"deploy prod", []string{usage}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			body := withText(tt.text)
+			resp := decodeResponse(t, post(newTestHandler(&fakeService{}), recordedTimestamp, sign(recordedTimestamp, body), body))
+			for _, part := range tt.want {
+				if !strings.Contains(resp.Text, part) {
+					t.Errorf("response %q does not contain %q", resp.Text, part)
+				}
+			}
+		})
+	}
+}
+
+func TestCommandSideEffects(t *testing.T) {
+	svc := &fakeService{}
+	h := newTestHandler(svc)
+	for _, text := range []string{"reassign pr-7 u3", "ooo on"} {
+		body := withText(text)
+		decodeResponse(t, post(h, recordedTimestamp, sign(recordedTimestamp, body), body))
+	}
+
+	if len(svc.reassigned) != 1 || svc.reassigned[0] != "pr-7:u3" {
+		t.Errorf("reassigned = %v", svc.reassigned)
+	}
+	// ooo on выключает назначения самому вызвавшему
+	if active, ok := svc.active["u1"]; !ok || active {
+		t.Errorf("active = %v", svc.active)
+	}
+	// Изменения попадают в аудит от имени связанного пользователя сервиса
+	for _, actor := range svc.actors {
+		if actor != "u1" {
+			t.Errorf("audit actor = %q, want u1", actor)
+		}
+	}
+}
+
+func TestUnlinkedChatUser(t *testing.T) {
+	form, _ := url.ParseQuery(recordedBody)
+	form.Set("user_id", "U0STRANGER")
+	form.Set("text", "ooo on")
+	body := form.Encode()
+
+	svc := &fakeService{}
+	resp := decodeResponse(t, post(newTestHandler(svc), recordedTimestamp, sign(recordedTimestamp, body), body))
+	if !strings.Contains(resp.Text, "not linked") {
+		t.Errorf("response = %q", resp.Text)
+	}
+	if len(svc.active) != 0 {
+		t.Errorf("unlinked user changed state: %v", svc.active)
+	}
+}
@@ -0,0 +1,703 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Duration - обёртка над time.Duration, которая читается из строк вида "3s" в YAML, TOML, env и флагах
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+type ServerConfig struct {
+	Port string `yaml:"port" toml:"port"`
+}
+
+type DatabaseConfig struct {
+	// DSN имеет приоритет над отдельными полями подключения
+	DSN         string `yaml:"dsn" toml:"dsn"`
+	Host        string `yaml:"host" toml:"host"`
+	Port        string `yaml:"port" toml:"port"`
+	User        string `yaml:"user" toml:"user"`
+	Password    string `yaml:"password" toml:"password"`
+	Name        string `yaml:"name" toml:"name"`
+	SSLMode     string `yaml:"sslmode" toml:"sslmode"`
+	SSLRootCert string `yaml:"sslrootcert" toml:"sslrootcert"`
+	SSLCert     string `yaml:"sslcert" toml:"sslcert"`
+	SSLKey      string `yaml:"sslkey" toml:"sslkey"`
+
+	MaxOpenConns    int      `yaml:"max_open_conns" toml:"max_open_conns"`
+	MaxIdleConns    int      `yaml:"max_idle_conns" toml:"max_idle_conns"`
+	ConnMaxLifetime Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime"`
+
+	ConnectRetries    int      `yaml:"connect_retries" toml:"connect_retries"`
+	ConnectRetryDelay Duration `yaml:"connect_retry_delay" toml:"connect_retry_delay"`
+}
+
+type AuthConfig struct {
+	// При выключенной аутентификации все эндпоинты открыты, как раньше
+	Enabled bool `yaml:"enabled" toml:"enabled"`
+	// BootstrapAPIKey - ключ администратора, не хранящийся в БД; нужен для выпуска первых ключей
+	BootstrapAPIKey string   `yaml:"bootstrap_api_key" toml:"bootstrap_api_key"`
+	JWTSecret       string   `yaml:"jwt_secret" toml:"jwt_secret"`
+	JWKSFile        string   `yaml:"jwks_file" toml:"jwks_file"`
+	JWTIssuer       string   `yaml:"jwt_issuer" toml:"jwt_issuer"`
+	JWTAudience     string   `yaml:"jwt_audience" toml:"jwt_audience"`
+	JWTLeeway       Duration `yaml:"jwt_leeway" toml:"jwt_leeway"`
+}
+
+type SLAConfig struct {
+	Enabled       bool     `yaml:"enabled" toml:"enabled"`
+	CheckInterval Duration `yaml:"check_interval" toml:"check_interval"`
+}
+
+type SMTPConfig struct {
+	Host     string `yaml:"host" toml:"host"`
+	Port     string `yaml:"port" toml:"port"`
+	Username string `yaml:"username" toml:"username"`
+	Password string `yaml:"password" toml:"password"`
+	From     string `yaml:"from" toml:"from"`
+}
+
+type WebhookConfig struct {
+	// URL - вебхук по умолчанию для подписчиков без собственного адреса
+	URL     string   `yaml:"url" toml:"url"`
+	Timeout Duration `yaml:"timeout" toml:"timeout"`
+}
+
+type RemindersConfig struct {
+	Enabled       bool          `yaml:"enabled" toml:"enabled"`
+	CheckInterval Duration      `yaml:"check_interval" toml:"check_interval"`
+	SMTP          SMTPConfig    `yaml:"smtp" toml:"smtp"`
+	Webhook       WebhookConfig `yaml:"webhook" toml:"webhook"`
+}
+
+type StatsConfig struct {
+	SnapshotEnabled  bool     `yaml:"snapshot_enabled" toml:"snapshot_enabled"`
+	SnapshotInterval Duration `yaml:"snapshot_interval" toml:"snapshot_interval"`
+	// Снимки старше RawRetention прореживаются до одного в сутки, старше Retention удаляются
+	RawRetention Duration `yaml:"raw_retention" toml:"raw_retention"`
+	Retention    Duration `yaml:"retention" toml:"retention"`
+}
+
+type IdempotencyConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled"`
+	// TTL - сколько хранится ответ на запрос с Idempotency-Key
+	TTL Duration `yaml:"ttl" toml:"ttl"`
+}
+
+type ChatOpsConfig struct {
+	// Без секрета подписи эндпоинт slash-команд не регистрируется
+	SigningSecret string   `yaml:"signing_secret" toml:"signing_secret"`
+	MaxSkew       Duration `yaml:"max_skew" toml:"max_skew"`
+}
+
+type GRPCConfig struct {
+	// gRPC API слушает отдельный порт и использует те же аутентификацию и роли, что и HTTP
+	Enabled    bool   `yaml:"enabled" toml:"enabled"`
+	Port       string `yaml:"port" toml:"port"`
+	Reflection bool   `yaml:"reflection" toml:"reflection"`
+}
+
+type Config struct {
+	Server      ServerConfig      `yaml:"server" toml:"server"`
+	Database    DatabaseConfig    `yaml:"database" toml:"database"`
+	Auth        AuthConfig        `yaml:"auth" toml:"auth"`
+	SLA         SLAConfig         `yaml:"sla" toml:"sla"`
+	Reminders   RemindersConfig   `yaml:"reminders" toml:"reminders"`
+	Stats       StatsConfig       `yaml:"stats" toml:"stats"`
+	Idempotency IdempotencyConfig `yaml:"idempotency" toml:"idempotency"`
+	ChatOps     ChatOpsConfig     `yaml:"chatops" toml:"chatops"`
+	GRPC        GRPCConfig        `yaml:"grpc" toml:"grpc"`
+}
+
+// Default возвращает конфигурацию, совпадающую с прежним поведением сервиса
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port: "8080",
+		},
+		Database: DatabaseConfig{
+			Host:              "localhost",
+			Port:              "5432",
+			User:              "postgres",
+			Password:          "password",
+			Name:              "pr_reviewer",
+			SSLMode:           "disable",
+			MaxOpenConns:      25,
+			MaxIdleConns:      5,
+			ConnMaxLifetime:   Duration(30 * time.Minute),
+			ConnectRetries:    5,
+			ConnectRetryDelay: Duration(3 * time.Second),
+		},
+		Auth: AuthConfig{
+			JWTLeeway: Duration(30 * time.Second),
+		},
+		// Фоновые задачи пишут в БД, а проверка SLA может переназначать ревьюверов,
+		// поэтому они включаются явно, как в config.example.yaml
+		SLA: SLAConfig{
+			Enabled:       false,
+			CheckInterval: Duration(5 * time.Minute),
+		},
+		Reminders: RemindersConfig{
+			Enabled:       false,
+			CheckInterval: Duration(time.Minute),
+			SMTP: SMTPConfig{
+				Port: "25",
+			},
+			Webhook: WebhookConfig{
+				Timeout: Duration(10 * time.Second),
+			},
+		},
+		Stats: StatsConfig{
+			SnapshotEnabled:  false,
+			SnapshotInterval: Duration(time.Hour),
+			RawRetention:     Duration(14 * 24 * time.Hour),
+			Retention:        Duration(365 * 24 * time.Hour),
+		},
+		Idempotency: IdempotencyConfig{
+			Enabled: false,
+			TTL:     Duration(24 * time.Hour),
+		},
+		ChatOps: ChatOpsConfig{
+			MaxSkew: Duration(5 * time.Minute),
+		},
+		GRPC: GRPCConfig{
+			Port:       "9090",
+			Reflection: true,
+		},
+	}
+}
+
+// Options - результат разбора командной строки
+type Options struct {
+	Config      *Config
+	PrintConfig bool
+	// Args - подкоманда и её аргументы после флагов конфигурации, например ["import", "bundle.json"]
+	Args []string
+}
+
+// Load собирает конфигурацию из источников по возрастанию приоритета:
+// значения по умолчанию, файл (--config или CONFIG_FILE), переменные окружения, флаги.
+func Load(args []string) (*Options, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet("pr-reviewer-service", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	configPath := fs.String("config", "", "path to YAML or TOML config file")
+	printConfig := fs.Bool("print-config", false, "print effective config with secrets redacted and exit")
+
+	var flagCfg Config
+	fs.StringVar(&flagCfg.Server.Port, "port", "", "HTTP port")
+	fs.StringVar(&flagCfg.Database.DSN, "database-url", "", "full database DSN or URL")
+	fs.StringVar(&flagCfg.Database.Host, "db-host", "", "database host")
+	fs.StringVar(&flagCfg.Database.Port, "db-port", "", "database port")
+	fs.StringVar(&flagCfg.Database.User, "db-user", "", "database user")
+	fs.StringVar(&flagCfg.Database.Password, "db-password", "", "database password")
+	fs.StringVar(&flagCfg.Database.Name, "db-name", "", "database name")
+	fs.StringVar(&flagCfg.Database.SSLMode, "db-sslmode", "", "disable, require, verify-ca or verify-full")
+	fs.StringVar(&flagCfg.Database.SSLRootCert, "db-sslrootcert", "", "CA certificate file")
+	fs.StringVar(&flagCfg.Database.SSLCert, "db-sslcert", "", "client certificate file")
+	fs.StringVar(&flagCfg.Database.SSLKey, "db-sslkey", "", "client key file")
+	fs.IntVar(&flagCfg.Database.MaxOpenConns, "db-max-open-conns", 0, "maximum open connections")
+	fs.IntVar(&flagCfg.Database.MaxIdleConns, "db-max-idle-conns", 0, "maximum idle connections")
+	fs.TextVar(&flagCfg.Database.ConnMaxLifetime, "db-conn-max-lifetime", Duration(0), "maximum connection lifetime")
+	fs.IntVar(&flagCfg.Database.ConnectRetries, "db-connect-retries", 0, "connection attempts on startup")
+	fs.TextVar(&flagCfg.Database.ConnectRetryDelay, "db-connect-retry-delay", Duration(0), "delay between connection attempts")
+	fs.BoolVar(&flagCfg.Auth.Enabled, "auth-enabled", false, "require authentication")
+	fs.StringVar(&flagCfg.Auth.JWKSFile, "auth-jwks-file", "", "JWKS file for JWT verification")
+	fs.StringVar(&flagCfg.Auth.JWTIssuer, "auth-jwt-issuer", "", "expected JWT issuer")
+	fs.StringVar(&flagCfg.Auth.JWTAudience, "auth-jwt-audience", "", "expected JWT audience")
+	fs.BoolVar(&flagCfg.SLA.Enabled, "sla-enabled", false, "run review SLA checks")
+	fs.TextVar(&flagCfg.SLA.CheckInterval, "sla-check-interval", Duration(0), "interval between SLA checks")
+	fs.BoolVar(&flagCfg.Reminders.Enabled, "reminders-enabled", false, "send review reminder digests")
+	fs.TextVar(&flagCfg.Reminders.CheckInterval, "reminders-check-interval", Duration(0), "interval between reminder checks")
+	fs.StringVar(&flagCfg.Reminders.SMTP.Host, "smtp-host", "", "SMTP server host")
+	fs.StringVar(&flagCfg.Reminders.SMTP.Port, "smtp-port", "", "SMTP server port")
+	fs.StringVar(&flagCfg.Reminders.SMTP.From, "smtp-from", "", "sender address for reminder emails")
+	fs.BoolVar(&flagCfg.Stats.SnapshotEnabled, "stats-snapshot-enabled", false, "take periodic stats snapshots")
+	fs.TextVar(&flagCfg.Stats.SnapshotInterval, "stats-snapshot-interval", Duration(0), "interval between stats snapshots")
+	fs.BoolVar(&flagCfg.GRPC.Enabled, "grpc-enabled", false, "serve the gRPC API")
+	fs.StringVar(&flagCfg.GRPC.Port, "grpc-port", "", "gRPC port")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+
+	path := *configPath
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path != "" {
+		if err := loadFile(cfg, path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := applyEnv(cfg); err != nil {
+		return nil, err
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		applyFlag(cfg, &flagCfg, f.Name)
+	})
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &Options{Config: cfg, PrintConfig: *printConfig, Args: fs.Args()}, nil
+}
+
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config: read %s: %w", path, err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(strings.NewReader(string(data)))
+		dec.KnownFields(true)
+		if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("config: parse %s: %w", path, err)
+		}
+	case ".toml":
+		md, err := toml.Decode(string(data), cfg)
+		if err != nil {
+			return fmt.Errorf("config: parse %s: %w", path, err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("config: parse %s: unknown key %q", path, undecoded[0].String())
+		}
+	default:
+		return fmt.Errorf("config: unsupported file extension %q, use .yaml, .yml or .toml", filepath.Ext(path))
+	}
+
+	return nil
+}
+
+func applyEnv(cfg *Config) error {
+	strVars := map[string]*string{
+		"PORT":           &cfg.Server.Port,
+		"DATABASE_URL":   &cfg.Database.DSN,
+		"DB_HOST":        &cfg.Database.Host,
+		"DB_PORT":        &cfg.Database.Port,
+		"DB_USER":        &cfg.Database.User,
+		"DB_PASSWORD":    &cfg.Database.Password,
+		"DB_NAME":        &cfg.Database.Name,
+		"DB_SSLMODE":     &cfg.Database.SSLMode,
+		"DB_SSLROOTCERT": &cfg.Database.SSLRootCert,
+		"DB_SSLCERT":     &cfg.Database.SSLCert,
+		"DB_SSLKEY":      &cfg.Database.SSLKey,
+
+		"AUTH_BOOTSTRAP_API_KEY": &cfg.Auth.BootstrapAPIKey,
+		"AUTH_JWT_SECRET":        &cfg.Auth.JWTSecret,
+		"AUTH_JWKS_FILE":         &cfg.Auth.JWKSFile,
+		"AUTH_JWT_ISSUER":        &cfg.Auth.JWTIssuer,
+		"AUTH_JWT_AUDIENCE":      &cfg.Auth.JWTAudience,
+
+		"SMTP_HOST":        &cfg.Reminders.SMTP.Host,
+		"SMTP_PORT":        &cfg.Reminders.SMTP.Port,
+		"SMTP_USERNAME":    &cfg.Reminders.SMTP.Username,
+		"SMTP_PASSWORD":    &cfg.Reminders.SMTP.Password,
+		"SMTP_FROM":        &cfg.Reminders.SMTP.From,
+		"CHAT_WEBHOOK_URL": &cfg.Reminders.Webhook.URL,
+
+		"CHATOPS_SIGNING_SECRET": &cfg.ChatOps.SigningSecret,
+
+		"GRPC_PORT": &cfg.GRPC.Port,
+	}
+	for key, dst := range strVars {
+		if value := os.Getenv(key); value != "" {
+			*dst = value
+		}
+	}
+
+	intVars := map[string]*int{
+		"DB_MAX_OPEN_CONNS":  &cfg.Database.MaxOpenConns,
+		"DB_MAX_IDLE_CONNS":  &cfg.Database.MaxIdleConns,
+		"DB_CONNECT_RETRIES": &cfg.Database.ConnectRetries,
+	}
+	for key, dst := range intVars {
+		if value := os.Getenv(key); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("config: %s: %q is not an integer", key, value)
+			}
+			*dst = n
+		}
+	}
+
+	boolVars := map[string]*bool{
+		"AUTH_ENABLED": &cfg.Auth.Enabled,
+		"SLA_ENABLED":  &cfg.SLA.Enabled,
+
+		"REMINDERS_ENABLED": &cfg.Reminders.Enabled,
+
+		"STATS_SNAPSHOT_ENABLED": &cfg.Stats.SnapshotEnabled,
+
+		"IDEMPOTENCY_ENABLED": &cfg.Idempotency.Enabled,
+
+		"GRPC_ENABLED":    &cfg.GRPC.Enabled,
+		"GRPC_REFLECTION": &cfg.GRPC.Reflection,
+	}
+	for key, dst := range boolVars {
+		if value := os.Getenv(key); value != "" {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("config: %s: %q is not a boolean", key, value)
+			}
+			*dst = b
+		}
+	}
+
+	durationVars := map[string]*Duration{
+		"DB_CONN_MAX_LIFETIME":     &cfg.Database.ConnMaxLifetime,
+		"DB_CONNECT_RETRY_DELAY":   &cfg.Database.ConnectRetryDelay,
+		"AUTH_JWT_LEEWAY":          &cfg.Auth.JWTLeeway,
+		"SLA_CHECK_INTERVAL":       &cfg.SLA.CheckInterval,
+		"REMINDERS_CHECK_INTERVAL": &cfg.Reminders.CheckInterval,
+		"CHAT_WEBHOOK_TIMEOUT":     &cfg.Reminders.Webhook.Timeout,
+		"STATS_SNAPSHOT_INTERVAL":  &cfg.Stats.SnapshotInterval,
+		"STATS_RAW_RETENTION":      &cfg.Stats.RawRetention,
+		"STATS_RETENTION":          &cfg.Stats.Retention,
+		"IDEMPOTENCY_TTL":          &cfg.Idempotency.TTL,
+		"CHATOPS_MAX_SKEW":         &cfg.ChatOps.MaxSkew,
+	}
+	for key, dst := range durationVars {
+		if value := os.Getenv(key); value != "" {
+			if err := dst.UnmarshalText([]byte(value)); err != nil {
+				return fmt.Errorf("config: %s: %q is not a duration", key, value)
+			}
+		}
+	}
+
+	return nil
+}
+
+func applyFlag(cfg, flagCfg *Config, name string) {
+	switch name {
+	case "port":
+		cfg.Server.Port = flagCfg.Server.Port
+	case "database-url":
+		cfg.Database.DSN = flagCfg.Database.DSN
+	case "db-host":
+		cfg.Database.Host = flagCfg.Database.Host
+	case "db-port":
+		cfg.Database.Port = flagCfg.Database.Port
+	case "db-user":
+		cfg.Database.User = flagCfg.Database.User
+	case "db-password":
+		cfg.Database.Password = flagCfg.Database.Password
+	case "db-name":
+		cfg.Database.Name = flagCfg.Database.Name
+	case "db-sslmode":
+		cfg.Database.SSLMode = flagCfg.Database.SSLMode
+	case "db-sslrootcert":
+		cfg.Database.SSLRootCert = flagCfg.Database.SSLRootCert
+	case "db-sslcert":
+		cfg.Database.SSLCert = flagCfg.Database.SSLCert
+	case "db-sslkey":
+		cfg.Database.SSLKey = flagCfg.Database.SSLKey
+	case "db-max-open-conns":
+		cfg.Database.MaxOpenConns = flagCfg.Database.MaxOpenConns
+	case "db-max-idle-conns":
+		cfg.Database.MaxIdleConns = flagCfg.Database.MaxIdleConns
+	case "db-conn-max-lifetime":
+		cfg.Database.ConnMaxLifetime = flagCfg.Database.ConnMaxLifetime
+	case "db-connect-retries":
+		cfg.Database.ConnectRetries = flagCfg.Database.ConnectRetries
+	case "db-connect-retry-delay":
+		cfg.Database.ConnectRetryDelay = flagCfg.Database.ConnectRetryDelay
+	case "auth-enabled":
+		cfg.Auth.Enabled = flagCfg.Auth.Enabled
+	case "auth-jwks-file":
+		cfg.Auth.JWKSFile = flagCfg.Auth.JWKSFile
+	case "auth-jwt-issuer":
+		cfg.Auth.JWTIssuer = flagCfg.Auth.JWTIssuer
+	case "auth-jwt-audience":
+		cfg.Auth.JWTAudience = flagCfg.Auth.JWTAudience
+	case "sla-enabled":
+		cfg.SLA.Enabled = flagCfg.SLA.Enabled
+	case "sla-check-interval":
+		cfg.SLA.CheckInterval = flagCfg.SLA.CheckInterval
+	case "reminders-enabled":
+		cfg.Reminders.Enabled = flagCfg.Reminders.Enabled
+	case "reminders-check-interval":
+		cfg.Reminders.CheckInterval = flagCfg.Reminders.CheckInterval
+	case "smtp-host":
+		cfg.Reminders.SMTP.Host = flagCfg.Reminders.SMTP.Host
+	case "smtp-port":
+		cfg.Reminders.SMTP.Port = flagCfg.Reminders.SMTP.Port
+	case "smtp-from":
+		cfg.Reminders.SMTP.From = flagCfg.Reminders.SMTP.From
+	case "stats-snapshot-enabled":
+		cfg.Stats.SnapshotEnabled = flagCfg.Stats.SnapshotEnabled
+	case "stats-snapshot-interval":
+		cfg.Stats.SnapshotInterval = flagCfg.Stats.SnapshotInterval
+	case "grpc-enabled":
+		cfg.GRPC.Enabled = flagCfg.GRPC.Enabled
+	case "grpc-port":
+		cfg.GRPC.Port = flagCfg.GRPC.Port
+	}
+}
+
+var sslModes = map[string]bool{
+	"disable":     true,
+	"require":     true,
+	"verify-ca":   true,
+	"verify-full": true,
+}
+
+// Validate проверяет конфигурацию и возвращает все найденные ошибки сразу
+func (c *Config) Validate() error {
+	var problems []string
+
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		problems = append(problems, fmt.Sprintf("server.port: %q is not a valid port", c.Server.Port))
+	}
+
+	db := c.Database
+	if db.DSN != "" {
+		if strings.HasPrefix(db.DSN, "postgres://") || strings.HasPrefix(db.DSN, "postgresql://") {
+			if _, err := url.Parse(db.DSN); err != nil {
+				problems = append(problems, fmt.Sprintf("database.dsn: %v", err))
+			}
+		} else if !strings.Contains(db.DSN, "=") {
+			problems = append(problems, "database.dsn: expected postgres:// URL or key=value connection string")
+		}
+	} else {
+		if db.Host == "" {
+			problems = append(problems, "database.host: must not be empty")
+		}
+		if port, err := strconv.Atoi(db.Port); err != nil || port < 1 || port > 65535 {
+			problems = append(problems, fmt.Sprintf("database.port: %q is not a valid port", db.Port))
+		}
+		if db.User == "" {
+			problems = append(problems, "database.user: must not be empty")
+		}
+		if db.Name == "" {
+			problems = append(problems, "database.name: must not be empty")
+		}
+		if !sslModes[db.SSLMode] {
+			problems = append(problems, fmt.Sprintf("database.sslmode: %q is not one of disable, require, verify-ca, verify-full", db.SSLMode))
+		}
+		if db.SSLMode == "verify-ca" || db.SSLMode == "verify-full" {
+			if db.SSLRootCert == "" {
+				problems = append(problems, fmt.Sprintf("database.sslrootcert: required for sslmode %s", db.SSLMode))
+			}
+		}
+		if (db.SSLCert == "") != (db.SSLKey == "") {
+			problems = append(problems, "database.sslcert and database.sslkey must be set together")
+		}
+	}
+
+	if db.MaxOpenConns < 0 {
+		problems = append(problems, "database.max_open_conns: must not be negative")
+	}
+	if db.MaxIdleConns < 0 {
+		problems = append(problems, "database.max_idle_conns: must not be negative")
+	}
+	if db.MaxOpenConns > 0 && db.MaxIdleConns > db.MaxOpenConns {
+		problems = append(problems, "database.max_idle_conns: must not exceed max_open_conns")
+	}
+	if db.ConnMaxLifetime < 0 {
+		problems = append(problems, "database.conn_max_lifetime: must not be negative")
+	}
+	if db.ConnectRetries < 1 {
+		problems = append(problems, "database.connect_retries: must be at least 1")
+	}
+	if db.ConnectRetryDelay < 0 {
+		problems = append(problems, "database.connect_retry_delay: must not be negative")
+	}
+
+	if c.Auth.JWTSecret != "" && len(c.Auth.JWTSecret) < 32 {
+		problems = append(problems, "auth.jwt_secret: must be at least 32 bytes")
+	}
+	if c.Auth.BootstrapAPIKey != "" && len(c.Auth.BootstrapAPIKey) < 32 {
+		problems = append(problems, "auth.bootstrap_api_key: must be at least 32 characters")
+	}
+	if c.Auth.JWKSFile != "" {
+		if _, err := os.Stat(c.Auth.JWKSFile); err != nil {
+			problems = append(problems, fmt.Sprintf("auth.jwks_file: %v", err))
+		}
+	}
+	if c.Auth.JWTLeeway < 0 {
+		problems = append(problems, "auth.jwt_leeway: must not be negative")
+	}
+
+	if c.SLA.Enabled && c.SLA.CheckInterval < Duration(time.Second) {
+		problems = append(problems, "sla.check_interval: must be at least 1s")
+	}
+
+	rem := c.Reminders
+	if rem.Enabled && rem.CheckInterval < Duration(time.Second) {
+		problems = append(problems, "reminders.check_interval: must be at least 1s")
+	}
+	if rem.SMTP.Host != "" {
+		if port, err := strconv.Atoi(rem.SMTP.Port); err != nil || port < 1 || port > 65535 {
+			problems = append(problems, fmt.Sprintf("reminders.smtp.port: %q is not a valid port", rem.SMTP.Port))
+		}
+		if rem.SMTP.From == "" {
+			problems = append(problems, "reminders.smtp.from: required when smtp.host is set")
+		}
+	}
+	if rem.Webhook.URL != "" {
+		if u, err := url.Parse(rem.Webhook.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			problems = append(problems, "reminders.webhook.url: must be an http(s) URL")
+		}
+	}
+	if rem.Webhook.Timeout <= 0 {
+		problems = append(problems, "reminders.webhook.timeout: must be positive")
+	}
+
+	st := c.Stats
+	if st.SnapshotEnabled && st.SnapshotInterval < Duration(time.Second) {
+		problems = append(problems, "stats.snapshot_interval: must be at least 1s")
+	}
+	if st.RawRetention < 0 || st.Retention < 0 {
+		problems = append(problems, "stats: retention must not be negative")
+	}
+	if st.Retention > 0 && st.RawRetention > st.Retention {
+		problems = append(problems, "stats.raw_retention: must not exceed stats.retention")
+	}
+
+	if c.Idempotency.Enabled && c.Idempotency.TTL < Duration(time.Second) {
+		problems = append(problems, "idempotency.ttl: must be at least 1s")
+	}
+
+	if c.ChatOps.MaxSkew <= 0 {
+		problems = append(problems, "chatops.max_skew: must be positive")
+	}
+
+	if c.GRPC.Enabled {
+		if port, err := strconv.Atoi(c.GRPC.Port); err != nil || port < 1 || port > 65535 {
+			problems = append(problems, fmt.Sprintf("grpc.port: %q is not a valid port", c.GRPC.Port))
+		} else if c.GRPC.Port == c.Server.Port {
+			problems = append(problems, "grpc.port: must differ from server.port")
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// ConnString возвращает строку подключения для lib/pq
+func (d DatabaseConfig) ConnString() string {
+	if d.DSN != "" {
+		return d.DSN
+	}
+
+	parts := []string{
+		"host=" + quoteValue(d.Host),
+		"port=" + quoteValue(d.Port),
+		"user=" + quoteValue(d.User),
+		"password=" + quoteValue(d.Password),
+		"dbname=" + quoteValue(d.Name),
+		"sslmode=" + quoteValue(d.SSLMode),
+	}
+	if d.SSLRootCert != "" {
+		parts = append(parts, "sslrootcert="+quoteValue(d.SSLRootCert))
+	}
+	if d.SSLCert != "" {
+		parts = append(parts, "sslcert="+quoteValue(d.SSLCert), "sslkey="+quoteValue(d.SSLKey))
+	}
+	return strings.Join(parts, " ")
+}
+
+func quoteValue(v string) string {
+	if v != "" && !strings.ContainsAny(v, ` '\`) {
+		return v
+	}
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, `'`, `\'`)
+	return "'" + v + "'"
+}
+
+const redacted = "******"
+
+// Redacted возвращает копию конфигурации без секретов
+func (c *Config) Redacted() *Config {
+	out := *c
+	if out.Database.Password != "" {
+		out.Database.Password = redacted
+	}
+	out.Database.DSN = redactDSN(out.Database.DSN)
+	if out.Auth.BootstrapAPIKey != "" {
+		out.Auth.BootstrapAPIKey = redacted
+	}
+	if out.Auth.JWTSecret != "" {
+		out.Auth.JWTSecret = redacted
+	}
+	if out.Reminders.SMTP.Password != "" {
+		out.Reminders.SMTP.Password = redacted
+	}
+	// URL входящего вебхука сам по себе является секретом
+	if out.Reminders.Webhook.URL != "" {
+		out.Reminders.Webhook.URL = redacted
+	}
+	if out.ChatOps.SigningSecret != "" {
+		out.ChatOps.SigningSecret = redacted
+	}
+	return &out
+}
+
+func redactDSN(dsn string) string {
+	if dsn == "" {
+		return dsn
+	}
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), redacted)
+		}
+		q := u.Query()
+		if q.Has("password") {
+			q.Set("password", redacted)
+			u.RawQuery = q.Encode()
+		}
+		// url экранирует звёздочки, а вывод конфигурации читает человек
+		return strings.ReplaceAll(u.String(), url.QueryEscape(redacted), redacted)
+	}
+
+	return dsnPassword.ReplaceAllString(dsn, "${1}password="+redacted)
+}
+
+var dsnPassword = regexp.MustCompile(`(^|\s)password\s*=\s*('(?:[^'\\]|\\.)*'|\S*)`)
+
+// Print выводит конфигурацию в YAML без секретов
+func (c *Config) Print(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(c.Redacted()); err != nil {
+		return err
+	}
+	return enc.Close()
+}
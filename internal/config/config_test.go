@@ -0,0 +1,316 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const yamlConfig = `
+server:
+  port: "8001"
+database:
+  host: file-host
+  name: file-db
+sla:
+  enabled: true
+  check_interval: 2m
+idempotency:
+  ttl: 1h
+`
+
+func TestLoadPrecedence(t *testing.T) {
+	file := writeFile(t, "config.yaml", yamlConfig)
+
+	tests := []struct {
+		name  string
+		env   map[string]string
+		args  []string
+		check func(t *testing.T, c *Config)
+	}{
+		{"defaults", nil, nil, func(t *testing.T, c *Config) {
+			if c.Server.Port != "8080" || c.Database.Host != "localhost" || c.SLA.Enabled || c.Idempotency.Enabled {
+				t.Errorf("defaults: %+v", c)
+			}
+		}},
+		{"file over defaults", nil, []string{"--config", file}, func(t *testing.T, c *Config) {
+			if c.Server.Port != "8001" || c.Database.Host != "file-host" || c.Database.Name != "file-db" {
+				t.Errorf("file values not applied: port %q, host %q, name %q", c.Server.Port, c.Database.Host, c.Database.Name)
+			}
+			// Поля, которых нет в файле, остаются по умолчанию
+			if c.Database.User != "postgres" || c.Idempotency.TTL != Duration(time.Hour) || c.SLA.CheckInterval != Duration(2*time.Minute) {
+				t.Errorf("user %q, idempotency ttl %v, sla interval %v", c.Database.User, c.Idempotency.TTL, c.SLA.CheckInterval)
+			}
+		}},
+		{"file from CONFIG_FILE", map[string]string{"CONFIG_FILE": file}, nil, func(t *testing.T, c *Config) {
+			if c.Server.Port != "8001" {
+				t.Errorf("port = %q, want the file value", c.Server.Port)
+			}
+		}},
+		{"env over file", map[string]string{"PORT": "8002", "DB_HOST": "env-host", "SLA_ENABLED": "false", "IDEMPOTENCY_TTL": "30m"},
+			[]string{"--config", file}, func(t *testing.T, c *Config) {
+				if c.Server.Port != "8002" || c.Database.Host != "env-host" || c.SLA.Enabled || c.Idempotency.TTL != Duration(30*time.Minute) {
+					t.Errorf("env values not applied: port %q, host %q, sla %v, ttl %v", c.Server.Port, c.Database.Host, c.SLA.Enabled, c.Idempotency.TTL)
+				}
+				if c.Database.Name != "file-db" {
+					t.Errorf("name = %q, want the file value", c.Database.Name)
+				}
+			}},
+		{"flag over env", map[string]string{"PORT": "8002", "DB_HOST": "env-host", "SLA_ENABLED": "false"},
+			[]string{"--config", file, "--port", "8003", "--sla-enabled", "--sla-check-interval", "10s"}, func(t *testing.T, c *Config) {
+				if c.Server.Port != "8003" || !c.SLA.Enabled || c.SLA.CheckInterval != Duration(10*time.Second) {
+					t.Errorf("flags not applied: port %q, sla %v every %v", c.Server.Port, c.SLA.Enabled, c.SLA.CheckInterval)
+				}
+				if c.Database.Host != "env-host" {
+					t.Errorf("host = %q, want the env value", c.Database.Host)
+				}
+			}},
+		{"explicit false flag over env", map[string]string{"AUTH_ENABLED": "true"}, []string{"--auth-enabled=false"}, func(t *testing.T, c *Config) {
+			if c.Auth.Enabled {
+				t.Error("--auth-enabled=false did not override AUTH_ENABLED")
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"CONFIG_FILE", "PORT", "DB_HOST", "SLA_ENABLED", "IDEMPOTENCY_TTL", "AUTH_ENABLED", "DATABASE_URL"} {
+				t.Setenv(key, tt.env[key])
+			}
+			opts, err := Load(tt.args)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			tt.check(t, opts.Config)
+		})
+	}
+}
+
+func TestLoadArgs(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	opts, err := Load([]string{"--print-config", "import", "--dry-run", "bundle.json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opts.PrintConfig || strings.Join(opts.Args, " ") != "import --dry-run bundle.json" {
+		t.Errorf("PrintConfig = %v, Args = %q", opts.PrintConfig, opts.Args)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		env  map[string]string
+		args []string
+		want string
+	}{
+		{"unknown yaml key", "server:\n  prot: 1\n", nil, nil, "field prot not found"},
+		{"unknown toml key", "[server]\nprot = \"1\"\n", nil, nil, `unknown key "server.prot"`},
+		{"bad extension", "{}", nil, nil, "unsupported file extension"},
+		{"bad env integer", "", map[string]string{"DB_MAX_OPEN_CONNS": "many"}, nil, `DB_MAX_OPEN_CONNS: "many" is not an integer`},
+		{"bad env boolean", "", map[string]string{"SLA_ENABLED": "sometimes"}, nil, `SLA_ENABLED: "sometimes" is not a boolean`},
+		{"bad env duration", "", map[string]string{"IDEMPOTENCY_TTL": "day"}, nil, `IDEMPOTENCY_TTL: "day" is not a duration`},
+		{"unknown flag", "", nil, []string{"--no-such-flag"}, "no-such-flag"},
+		{"invalid result", "", nil, []string{"--port", "0"}, `server.port: "0" is not a valid port`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"CONFIG_FILE", "DB_MAX_OPEN_CONNS", "SLA_ENABLED", "IDEMPOTENCY_TTL"} {
+				t.Setenv(key, tt.env[key])
+			}
+			args := tt.args
+			if tt.file != "" {
+				ext := ".yaml"
+				switch {
+				case strings.Contains(tt.name, "toml"):
+					ext = ".toml"
+				case strings.Contains(tt.name, "extension"):
+					ext = ".json"
+				}
+				args = append([]string{"--config", writeFile(t, "config"+ext, tt.file)}, args...)
+			}
+			_, err := Load(args)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   []string
+	}{
+		{"defaults", func(c *Config) {}, nil},
+		{"ports", func(c *Config) {
+			c.Server.Port = "http"
+			c.Database.Port = "70000"
+		}, []string{`server.port: "http" is not a valid port`, `database.port: "70000" is not a valid port`}},
+		{"dsn replaces connection fields", func(c *Config) {
+			c.Database.DSN = "postgres://u:p@db:5432/app"
+			c.Database.Host, c.Database.Port = "", "none"
+		}, nil},
+		{"dsn without keys", func(c *Config) { c.Database.DSN = "db.example.com" }, []string{"database.dsn: expected postgres:// URL"}},
+		{"sslmode", func(c *Config) { c.Database.SSLMode = "prefer" }, []string{`database.sslmode: "prefer" is not one of`}},
+		{"verify-full without root cert", func(c *Config) { c.Database.SSLMode = "verify-full" }, []string{"database.sslrootcert: required for sslmode verify-full"}},
+		{"client cert without key", func(c *Config) { c.Database.SSLCert = "client.crt" }, []string{"database.sslcert and database.sslkey must be set together"}},
+		{"pool limits", func(c *Config) {
+			c.Database.MaxOpenConns, c.Database.MaxIdleConns = 2, 5
+			c.Database.ConnectRetries = 0
+		}, []string{"database.max_idle_conns: must not exceed max_open_conns", "database.connect_retries: must be at least 1"}},
+		{"short secrets", func(c *Config) {
+			c.Auth.JWTSecret = "secret"
+			c.Auth.BootstrapAPIKey = "key"
+		}, []string{"auth.jwt_secret: must be at least 32 bytes", "auth.bootstrap_api_key: must be at least 32 characters"}},
+		{"missing jwks file", func(c *Config) { c.Auth.JWKSFile = filepath.Join(t.TempDir(), "jwks.json") }, []string{"auth.jwks_file:"}},
+		{"intervals of enabled jobs", func(c *Config) {
+			c.SLA.Enabled, c.SLA.CheckInterval = true, Duration(500*time.Millisecond)
+			c.Reminders.Enabled, c.Reminders.CheckInterval = true, 0
+			c.Stats.SnapshotEnabled, c.Stats.SnapshotInterval = true, Duration(time.Millisecond)
+		}, []string{"sla.check_interval: must be at least 1s", "reminders.check_interval: must be at least 1s", "stats.snapshot_interval: must be at least 1s"}},
+		{"intervals of disabled jobs are not checked", func(c *Config) {
+			c.SLA.CheckInterval, c.Reminders.CheckInterval, c.Stats.SnapshotInterval = 0, 0, 0
+			c.Idempotency.TTL = 0
+		}, nil},
+		{"idempotency ttl", func(c *Config) {
+			c.Idempotency.Enabled, c.Idempotency.TTL = true, Duration(999*time.Millisecond)
+		}, []string{"idempotency.ttl: must be at least 1s"}},
+		{"idempotency ttl of one second", func(c *Config) {
+			c.Idempotency.Enabled, c.Idempotency.TTL = true, Duration(time.Second)
+		}, nil},
+		{"smtp without sender", func(c *Config) { c.Reminders.SMTP.Host = "smtp.example.com" }, []string{"reminders.smtp.from: required when smtp.host is set"}},
+		{"webhook scheme", func(c *Config) { c.Reminders.Webhook.URL = "ftp://hooks.example.com" }, []string{"reminders.webhook.url: must be an http(s) URL"}},
+		{"stats retention", func(c *Config) {
+			c.Stats.RawRetention, c.Stats.Retention = Duration(48*time.Hour), Duration(24*time.Hour)
+		}, []string{"stats.raw_retention: must not exceed stats.retention"}},
+		{"grpc port clash", func(c *Config) {
+			c.GRPC.Enabled, c.GRPC.Port = true, c.Server.Port
+		}, []string{"grpc.port: must differ from server.port"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Default()
+			tt.modify(c)
+			err := c.Validate()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate() = nil, want %q", tt.want)
+			}
+			// Все ошибки выводятся разом, по одной на строку
+			lines := strings.Split(err.Error(), "\n")[1:]
+			if len(lines) != len(tt.want) {
+				t.Fatalf("Validate() reported %d problems, want %d:\n%v", len(lines), len(tt.want), err)
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(lines[i], want) {
+					t.Errorf("problem %d = %q, want %q", i, strings.TrimSpace(lines[i]), want)
+				}
+			}
+		})
+	}
+}
+
+func TestConnString(t *testing.T) {
+	d := Default().Database
+	d.Password = `p@ss w'rd\`
+	d.SSLRootCert = "/etc/ssl/ca.pem"
+	want := `host=localhost port=5432 user=postgres password='p@ss w\'rd\\' dbname=pr_reviewer sslmode=disable sslrootcert=/etc/ssl/ca.pem`
+	if got := d.ConnString(); got != want {
+		t.Errorf("ConnString() = %q, want %q", got, want)
+	}
+
+	d.DSN = "postgres://app@db/app"
+	if got := d.ConnString(); got != d.DSN {
+		t.Errorf("ConnString() with DSN = %q", got)
+	}
+}
+
+func TestRedactedDSN(t *testing.T) {
+	tests := []struct {
+		dsn, want string
+	}{
+		{"", ""},
+		{"postgres://app:s3cret@db:5432/app?sslmode=require", "postgres://app:******@db:5432/app?sslmode=require"},
+		{"postgres://app@db/app?password=s3cret&sslmode=disable", "postgres://app@db/app?password=******&sslmode=disable"},
+		{"postgresql://app@db/app", "postgresql://app@db/app"},
+		{"host=db password=s3cret user=app", "host=db password=****** user=app"},
+		{"password='s3 cr\\'et' host=db", "password=****** host=db"},
+		{"host=db password = s3cret", "host=db password=******"},
+		{"host=db user=app", "host=db user=app"},
+	}
+	for _, tt := range tests {
+		c := Default()
+		c.Database.DSN = tt.dsn
+		got := c.Redacted().Database.DSN
+		if got != tt.want {
+			t.Errorf("redact(%q) = %q, want %q", tt.dsn, got, tt.want)
+		}
+		if strings.Contains(got, "s3") {
+			t.Errorf("redact(%q) leaks the password: %q", tt.dsn, got)
+		}
+	}
+}
+
+func TestRedactedSecrets(t *testing.T) {
+	c := Default()
+	c.Database.Password = "db-pass"
+	c.Auth.BootstrapAPIKey = "bootstrap-key"
+	c.Auth.JWTSecret = "jwt-secret"
+	c.Reminders.SMTP.Password = "smtp-pass"
+	c.Reminders.Webhook.URL = "https://hooks.example.com/T000/B000/XXXX"
+	c.ChatOps.SigningSecret = "signing-secret"
+
+	r := c.Redacted()
+	for name, value := range map[string]string{
+		"database.password":       r.Database.Password,
+		"auth.bootstrap_api_key":  r.Auth.BootstrapAPIKey,
+		"auth.jwt_secret":         r.Auth.JWTSecret,
+		"reminders.smtp.password": r.Reminders.SMTP.Password,
+		"reminders.webhook.url":   r.Reminders.Webhook.URL,
+		"chatops.signing_secret":  r.ChatOps.SigningSecret,
+	} {
+		if value != redacted {
+			t.Errorf("%s = %q, want redacted", name, value)
+		}
+	}
+	// Исходная конфигурация не меняется
+	if c.Database.Password != "db-pass" || c.ChatOps.SigningSecret != "signing-secret" {
+		t.Error("Redacted() modified the original config")
+	}
+
+	var out strings.Builder
+	if err := c.Print(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"db-pass", "bootstrap-key", "jwt-secret", "smtp-pass", "hooks.example.com", "signing-secret"} {
+		if strings.Contains(out.String(), secret) {
+			t.Errorf("Print() output contains %q", secret)
+		}
+	}
+	if !strings.Contains(out.String(), "port: \"8080\"") {
+		t.Errorf("Print() output:\n%s", out.String())
+	}
+
+	// Пустые секреты остаются пустыми, чтобы было видно, что они не заданы
+	if empty := Default().Redacted(); empty.Auth.JWTSecret != "" || empty.Database.DSN != "" {
+		t.Errorf("empty secrets redacted: %+v", empty.Auth)
+	}
+}
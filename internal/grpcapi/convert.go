@@ -0,0 +1,144 @@
+package grpcapi
+
+import (
+	pb "pr-reviewer-service/api/prreviewer/v1"
+	"pr-reviewer-service/internal/models"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var statusToProto = map[string]pb.PullRequestStatus{
+	"OPEN":   pb.PullRequestStatus_PULL_REQUEST_STATUS_OPEN,
+	"MERGED": pb.PullRequestStatus_PULL_REQUEST_STATUS_MERGED,
+}
+
+var statusFromProto = map[pb.PullRequestStatus]string{
+	pb.PullRequestStatus_PULL_REQUEST_STATUS_OPEN:   "OPEN",
+	pb.PullRequestStatus_PULL_REQUEST_STATUS_MERGED: "MERGED",
+}
+
+var reviewerKindToProto = map[string]pb.AssignmentEvent_Kind{
+	models.ReviewerAssigned:   pb.AssignmentEvent_KIND_ASSIGNED,
+	models.ReviewerUnassigned: pb.AssignmentEvent_KIND_UNASSIGNED,
+	models.ReviewerPRMerged:   pb.AssignmentEvent_KIND_MERGED,
+}
+
+func timestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func fromTimestamp(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+func teamToProto(team *models.Team) *pb.Team {
+	out := &pb.Team{TeamName: team.TeamName, Members: make([]*pb.TeamMember, 0, len(team.Members))}
+	for _, m := range team.Members {
+		out.Members = append(out.Members, &pb.TeamMember{UserId: m.UserID, Username: m.Username, IsActive: m.IsActive})
+	}
+	return out
+}
+
+func teamFromProto(team *pb.Team) *models.Team {
+	out := &models.Team{TeamName: team.GetTeamName(), Members: make([]models.TeamMember, 0, len(team.GetMembers()))}
+	for _, m := range team.GetMembers() {
+		out.Members = append(out.Members, models.TeamMember{UserID: m.GetUserId(), Username: m.GetUsername(), IsActive: m.GetIsActive()})
+	}
+	return out
+}
+
+func userToProto(user *models.User) *pb.User {
+	return &pb.User{UserId: user.UserID, Username: user.Username, TeamName: user.TeamName, IsActive: user.IsActive}
+}
+
+func prToProto(pr *models.PullRequest) *pb.PullRequest {
+	return &pb.PullRequest{
+		PullRequestId:     pr.PullRequestID,
+		PullRequestName:   pr.PullRequestName,
+		AuthorId:          pr.AuthorID,
+		Status:            statusToProto[pr.Status],
+		AssignedReviewers: pr.AssignedReviewers,
+		CreatedAt:         timestamp(pr.CreatedAt),
+		MergedAt:          timestamp(pr.MergedAt),
+		Overdue:           pr.Overdue,
+	}
+}
+
+func prShortToProto(pr *models.PullRequestShort) *pb.PullRequestShort {
+	return &pb.PullRequestShort{
+		PullRequestId:   pr.PullRequestID,
+		PullRequestName: pr.PullRequestName,
+		AuthorId:        pr.AuthorID,
+		Status:          statusToProto[pr.Status],
+		CreatedAt:       timestamp(pr.CreatedAt),
+		Overdue:         pr.Overdue,
+	}
+}
+
+func reviewerStatsToProto(list []models.ReviewerStats) []*pb.ReviewerStats {
+	out := make([]*pb.ReviewerStats, 0, len(list))
+	for _, r := range list {
+		out = append(out, &pb.ReviewerStats{
+			UserId:          r.UserID,
+			AssignmentCount: int32(r.AssignmentCount),
+			OpenReviews:     int32(r.OpenReviews),
+			ReassignedAway:  int32(r.ReassignedAway),
+		})
+	}
+	return out
+}
+
+func statsToProto(stats *models.Stats) *pb.Stats {
+	out := &pb.Stats{
+		TotalTeams:   int32(stats.TotalTeams),
+		TotalUsers:   int32(stats.TotalUsers),
+		TotalPrs:     int32(stats.TotalPRs),
+		OpenPrs:      int32(stats.OpenPRs),
+		MergedPrs:    int32(stats.MergedPRs),
+		TopReviewers: reviewerStatsToProto(stats.TopReviewers),
+		TimeToMerge: &pb.DurationStats{
+			Count:         int32(stats.TimeToMerge.Count),
+			MedianSeconds: stats.TimeToMerge.MedianSeconds,
+			P90Seconds:    stats.TimeToMerge.P90Seconds,
+		},
+		Reassignments: &pb.ReassignmentStats{
+			Total:         int32(stats.Reassignments.Total),
+			PrsReassigned: int32(stats.Reassignments.PRsReassigned),
+			Rate:          stats.Reassignments.Rate,
+		},
+		ReviewerLoad: reviewerStatsToProto(stats.ReviewerLoad),
+	}
+	for _, b := range stats.OpenPRAge {
+		out.OpenPrAge = append(out.OpenPrAge, &pb.AgeBucket{
+			Label:    b.Label,
+			MinHours: int32(b.MinHours),
+			MaxHours: int32(b.MaxHours),
+			Count:    int32(b.Count),
+		})
+	}
+	return out
+}
+
+func assignmentEventToProto(e *models.PREvent, userID string) *pb.AssignmentEvent {
+	out := &pb.AssignmentEvent{
+		EventId:       e.ID,
+		Kind:          reviewerKindToProto[e.ReviewerKind(userID)],
+		PullRequestId: e.PullRequestID,
+		UserId:        userID,
+		Actor:         e.Actor,
+		Reason:        e.Reason,
+		CreatedAt:     timestamppb.New(e.CreatedAt),
+	}
+	if out.Kind == pb.AssignmentEvent_KIND_UNASSIGNED {
+		out.ReviewerId = e.ReviewerID
+	}
+	return out
+}
@@ -0,0 +1,76 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type domainError struct {
+	code    codes.Code
+	message string
+}
+
+// domainErrors - статусы gRPC для ошибок предметной области; сообщения совпадают с HTTP API
+var domainErrors = map[string]domainError{
+	"NOT_FOUND":      {codes.NotFound, "resource not found"},
+	"TEAM_EXISTS":    {codes.AlreadyExists, "team_name already exists"},
+	"PR_EXISTS":      {codes.AlreadyExists, "PR id already exists"},
+	"PR_MERGED":      {codes.FailedPrecondition, "cannot reassign on merged PR"},
+	"NOT_ASSIGNED":   {codes.FailedPrecondition, "reviewer is not assigned to this PR"},
+	"NO_CANDIDATE":   {codes.FailedPrecondition, "no active replacement candidate in team"},
+	"PR_MODIFIED":    {codes.Aborted, "PR was changed concurrently, retry"},
+	"INVALID_CURSOR": {codes.InvalidArgument, "cursor does not match this query"},
+	"INVALID_RANGE":  {codes.InvalidArgument, "from must be before to"},
+}
+
+// toStatus переводит ошибку сервиса в статус gRPC; сообщение начинается с кода ошибки HTTP API.
+// Неизвестные ошибки (сбои БД и т. п.) только логируются: клиент получает Internal без подробностей.
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	if e, ok := domainErrors[err.Error()]; ok {
+		return status.Error(e.code, err.Error()+": "+e.message)
+	}
+	log.Printf("grpc: internal error: %v", err)
+	return status.Error(codes.Internal, "INTERNAL: internal server error")
+}
+
+// field - значение поля запроса и правило из internal/validation
+type field struct {
+	name  string
+	value string
+	check func(string) string
+}
+
+// validate проверяет поля по тем же правилам, что и HTTP API, и перечисляет все нарушения
+func validate(fields ...field) error {
+	var problems []string
+	for _, f := range fields {
+		if msg := f.check(f.value); msg != "" {
+			problems = append(problems, f.name+": "+msg)
+		}
+	}
+	if len(problems) > 0 {
+		return status.Error(codes.InvalidArgument, "VALIDATION_ERROR: "+strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func invalidArgument(message string) error {
+	return status.Error(codes.InvalidArgument, "INVALID_PARAM: "+message)
+}
@@ -0,0 +1,74 @@
+package grpcapi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// captureLog перенаправляет стандартный логгер в буфер до конца теста
+func captureLog(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	prev := log.Writer()
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(prev) })
+	return &buf
+}
+
+func TestToStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		code    codes.Code
+		message string
+	}{
+		{"not found", fmt.Errorf("NOT_FOUND"), codes.NotFound, "NOT_FOUND: resource not found"},
+		{"team exists", fmt.Errorf("TEAM_EXISTS"), codes.AlreadyExists, "TEAM_EXISTS: team_name already exists"},
+		{"pr exists", fmt.Errorf("PR_EXISTS"), codes.AlreadyExists, "PR_EXISTS: PR id already exists"},
+		{"merged", fmt.Errorf("PR_MERGED"), codes.FailedPrecondition, "PR_MERGED: cannot reassign on merged PR"},
+		{"not assigned", fmt.Errorf("NOT_ASSIGNED"), codes.FailedPrecondition, "NOT_ASSIGNED: reviewer is not assigned to this PR"},
+		{"no candidate", fmt.Errorf("NO_CANDIDATE"), codes.FailedPrecondition, "NO_CANDIDATE: no active replacement candidate in team"},
+		{"modified", fmt.Errorf("PR_MODIFIED"), codes.Aborted, "PR_MODIFIED: PR was changed concurrently, retry"},
+		{"cursor", fmt.Errorf("INVALID_CURSOR"), codes.InvalidArgument, "INVALID_CURSOR: cursor does not match this query"},
+		{"range", fmt.Errorf("INVALID_RANGE"), codes.InvalidArgument, "INVALID_RANGE: from must be before to"},
+		{"canceled", fmt.Errorf("query: %w", context.Canceled), codes.Canceled, "query: context canceled"},
+		{"deadline", context.DeadlineExceeded, codes.DeadlineExceeded, "context deadline exceeded"},
+		{"status kept", status.Error(codes.InvalidArgument, "VALIDATION_ERROR: user_id: must not be empty"), codes.InvalidArgument, "VALIDATION_ERROR: user_id: must not be empty"},
+		{"wrapped domain code is internal", fmt.Errorf("load: %w", fmt.Errorf("NOT_FOUND")), codes.Internal, "INTERNAL: internal server error"},
+		{"database error", fmt.Errorf(`pq: password authentication failed for user "reviewer"`), codes.Internal, "INTERNAL: internal server error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLog(t)
+			st, _ := status.FromError(toStatus(tt.err))
+			if st.Code() != tt.code || st.Message() != tt.message {
+				t.Errorf("toStatus() = %s %q, want %s %q", st.Code(), st.Message(), tt.code, tt.message)
+			}
+			// Подробности внутренней ошибки уходят в лог, а не клиенту
+			if logged := strings.Contains(logs.String(), tt.err.Error()); logged != (tt.code == codes.Internal) {
+				t.Errorf("log %q, internal %v", logs, tt.code == codes.Internal)
+			}
+		})
+	}
+
+	if toStatus(nil) != nil {
+		t.Error("toStatus(nil) != nil")
+	}
+}
+
+func TestDomainErrorsAreClientErrors(t *testing.T) {
+	for code, e := range domainErrors {
+		if e.code == codes.Internal || e.code == codes.Unknown || e.code == codes.OK {
+			t.Errorf("%s maps to %s", code, e.code)
+		}
+		if e.message == "" {
+			t.Errorf("%s has no message", code)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"pr-reviewer-service/internal/audit"
+	"pr-reviewer-service/internal/auth"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// requestContext делает для вызова gRPC то же, что auth- и audit-middleware для HTTP:
+// проверяет учётные данные из метаданных и политику метода, кладёт в контекст Principal, инициатора и request id
+func (s *Server) requestContext(ctx context.Context, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	header := http.Header{}
+	for _, key := range []string{"authorization", "x-api-key", "x-actor", "x-request-id"} {
+		for _, v := range md.Get(key) {
+			header.Add(key, v)
+		}
+	}
+
+	actor := header.Get("X-Actor")
+	if s.auth != nil {
+		policy, ok := s.policies[method]
+		if !ok || !policy.Public {
+			principal, err := s.auth.AuthenticateHeader(header)
+			if err != nil {
+				if err.Error() == "UNAUTHORIZED" {
+					return nil, status.Error(codes.Unauthenticated, "UNAUTHORIZED: missing or invalid credentials")
+				}
+				return nil, toStatus(err)
+			}
+			if !ok || !policy.Allows(principal.Role) {
+				return nil, status.Error(codes.PermissionDenied, "FORBIDDEN: role is not allowed to call this method")
+			}
+			ctx = auth.WithPrincipal(ctx, principal)
+			actor = principal.Subject
+		}
+	}
+
+	requestID := header.Get("X-Request-ID")
+	if requestID == "" || len(requestID) > 128 {
+		requestID = newRequestID()
+	}
+	grpc.SetHeader(ctx, metadata.Pairs("x-request-id", requestID))
+
+	return audit.WithRequestID(audit.WithActor(ctx, actor), requestID), nil
+}
+
+func (s *Server) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := s.requestContext(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := handler(ctx, req)
+	return resp, toStatus(err)
+}
+
+func (s *Server) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.requestContext(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return toStatus(handler(srv, &contextStream{ServerStream: ss, ctx: ctx}))
+}
+
+// contextStream подменяет контекст потока
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+func newRequestID() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
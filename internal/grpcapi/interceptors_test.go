@@ -0,0 +1,205 @@
+package grpcapi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	pb "pr-reviewer-service/api/prreviewer/v1"
+	"pr-reviewer-service/internal/auth"
+	"pr-reviewer-service/internal/models"
+	"pr-reviewer-service/internal/service"
+	"pr-reviewer-service/internal/storage"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// memoryKeys - KeyStore в памяти, ключи по открытому значению
+type memoryKeys map[string]*models.APIKey
+
+func (m memoryKeys) GetAPIKeyByHash(hash string) (*models.APIKey, error) {
+	for key, apiKey := range m {
+		if auth.HashAPIKey(key) == hash {
+			return apiKey, nil
+		}
+	}
+	return nil, fmt.Errorf("NOT_FOUND")
+}
+
+type failingKeys struct{}
+
+func (failingKeys) GetAPIKeyByHash(string) (*models.APIKey, error) {
+	return nil, fmt.Errorf("dial tcp 10.0.0.5:5432: connection refused")
+}
+
+// unreachableService - сервис поверх БД, к которой нельзя подключиться: вызов, прошедший проверку доступа,
+// завершается ошибкой хранилища
+func unreachableService(t *testing.T) *service.PRService {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := lis.Addr().(*net.TCPAddr).Port
+	lis.Close()
+
+	db, err := sql.Open("postgres", fmt.Sprintf("host=127.0.0.1 port=%d user=test dbname=test sslmode=disable connect_timeout=1", port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return service.NewPRService(storage.NewPostgresStorageFromDB(db))
+}
+
+// dial запускает сервер на bufconn и возвращает подключение к нему
+func dial(t *testing.T, opts Options) *grpc.ClientConn {
+	lis := bufconn.Listen(1 << 20)
+	server := NewServer(unreachableService(t), opts)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// rpcMethods - полные имена всех методов сервиса; stream - серверный поток
+func rpcMethods() map[string]bool {
+	desc := pb.PRReviewerService_ServiceDesc
+	methods := make(map[string]bool)
+	for _, m := range desc.Methods {
+		methods["/"+desc.ServiceName+"/"+m.MethodName] = false
+	}
+	for _, s := range desc.Streams {
+		methods["/"+desc.ServiceName+"/"+s.StreamName] = true
+	}
+	return methods
+}
+
+// call вызывает метод с пустым запросом и ключом key (пустой - без учётных данных)
+func call(t *testing.T, conn *grpc.ClientConn, method string, stream bool, key string) *status.Status {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if key != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-api-key", key)
+	}
+
+	var err error
+	if stream {
+		var s grpc.ClientStream
+		s, err = conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, method)
+		if err == nil {
+			if err = s.SendMsg(&emptypb.Empty{}); err == nil {
+				s.CloseSend()
+				err = s.RecvMsg(&emptypb.Empty{})
+			}
+		}
+	} else {
+		err = conn.Invoke(ctx, method, &emptypb.Empty{}, &emptypb.Empty{})
+	}
+	if err == nil {
+		t.Fatalf("%s: call succeeded against an unreachable database", method)
+	}
+	return status.Convert(err)
+}
+
+func TestRequestContextOnEveryMethod(t *testing.T) {
+	methods := rpcMethods()
+	if len(methods) != 13 {
+		t.Fatalf("service has %d methods; update the test together with the proto", len(methods))
+	}
+
+	// Все методы только для admin, у одного политики нет вовсе
+	noPolicy := pb.PRReviewerService_GetStats_FullMethodName
+	policies := make(map[string]auth.Policy)
+	for method := range methods {
+		if method != noPolicy {
+			policies[method] = auth.Policy{Roles: []auth.Role{auth.RoleAdmin}}
+		}
+	}
+	keys := memoryKeys{
+		"prk_admin":  {Name: "ops", Role: "admin"},
+		"prk_member": {Name: "dev", Role: "member"},
+	}
+	conn := dial(t, Options{Auth: auth.NewAuthenticator(keys, auth.Options{}), Policies: policies})
+	captureLog(t)
+
+	for method, stream := range methods {
+		t.Run(method[strings.LastIndex(method, "/")+1:], func(t *testing.T) {
+			for _, tt := range []struct {
+				key  string
+				code codes.Code
+			}{
+				{"", codes.Unauthenticated},
+				{"prk_unknown", codes.Unauthenticated},
+				{"prk_member", codes.PermissionDenied},
+			} {
+				if st := call(t, conn, method, stream, tt.key); st.Code() != tt.code {
+					t.Errorf("key %q: %s %q, want %s", tt.key, st.Code(), st.Message(), tt.code)
+				}
+			}
+
+			// Администратор проходит проверку доступа, если у метода есть политика: дальше запрос
+			// отклоняет проверка полей или хранилище
+			st := call(t, conn, method, stream, "prk_admin")
+			switch {
+			case method == noPolicy:
+				if st.Code() != codes.PermissionDenied {
+					t.Errorf("method without policy: %s %q, want PermissionDenied", st.Code(), st.Message())
+				}
+			case st.Code() == codes.Unauthenticated || st.Code() == codes.PermissionDenied:
+				t.Errorf("admin: %s %q", st.Code(), st.Message())
+			case st.Code() == codes.Internal && st.Message() != "INTERNAL: internal server error":
+				t.Errorf("internal error leaks details: %q", st.Message())
+			}
+		})
+	}
+}
+
+func TestRequestContextKeyStoreFailure(t *testing.T) {
+	conn := dial(t, Options{
+		Auth:     auth.NewAuthenticator(failingKeys{}, auth.Options{}),
+		Policies: map[string]auth.Policy{pb.PRReviewerService_GetTeam_FullMethodName: {Roles: []auth.Role{auth.RoleAdmin}}},
+	})
+	logs := captureLog(t)
+
+	st := call(t, conn, pb.PRReviewerService_GetTeam_FullMethodName, false, "prk_admin")
+	if st.Code() != codes.Internal || st.Message() != "INTERNAL: internal server error" {
+		t.Errorf("key store failure: %s %q", st.Code(), st.Message())
+	}
+	if !strings.Contains(logs.String(), "connection refused") {
+		t.Errorf("log %q does not contain the store error", logs)
+	}
+}
+
+func TestRequestContextPublicAndDisabled(t *testing.T) {
+	stream := pb.PRReviewerService_SubscribeAssignments_FullMethodName
+
+	// Публичный метод и выключенная аутентификация не требуют учётных данных
+	for name, opts := range map[string]Options{
+		"public":   {Auth: auth.NewAuthenticator(memoryKeys{}, auth.Options{}), Policies: map[string]auth.Policy{stream: {Public: true}}},
+		"disabled": {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			st := call(t, dial(t, opts), stream, true, "")
+			if st.Code() != codes.InvalidArgument || !strings.HasPrefix(st.Message(), "VALIDATION_ERROR: user_id") {
+				t.Errorf("%s %q, want InvalidArgument VALIDATION_ERROR", st.Code(), st.Message())
+			}
+		})
+	}
+}
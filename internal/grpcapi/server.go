@@ -0,0 +1,299 @@
+// Package grpcapi - gRPC API сервиса (api/prreviewer/v1) поверх того же PRService, что и HTTP JSON API.
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	pb "pr-reviewer-service/api/prreviewer/v1"
+	"pr-reviewer-service/internal/auth"
+	"pr-reviewer-service/internal/models"
+	"pr-reviewer-service/internal/pagination"
+	"pr-reviewer-service/internal/service"
+	"pr-reviewer-service/internal/validation"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+type Options struct {
+	// Auth равен nil, если аутентификация выключена
+	Auth *auth.Authenticator
+	// Policies - кому разрешён каждый метод (полное имя, например /prreviewer.v1.PRReviewerService/GetTeam).
+	// Метод без политики при включённой аутентификации недоступен.
+	Policies   map[string]auth.Policy
+	Reflection bool
+}
+
+type Server struct {
+	pb.UnimplementedPRReviewerServiceServer
+
+	service  *service.PRService
+	auth     *auth.Authenticator
+	policies map[string]auth.Policy
+}
+
+// NewServer создаёт grpc.Server с зарегистрированным PRReviewerService и, если включено, server reflection
+func NewServer(svc *service.PRService, opts Options) *grpc.Server {
+	s := &Server{service: svc, auth: opts.Auth, policies: opts.Policies}
+	gs := grpc.NewServer(
+		grpc.UnaryInterceptor(s.unaryInterceptor),
+		grpc.StreamInterceptor(s.streamInterceptor),
+	)
+	pb.RegisterPRReviewerServiceServer(gs, s)
+	if opts.Reflection {
+		reflection.Register(gs)
+	}
+	return gs
+}
+
+// ReflectionMethods - методы server reflection, которым нужна политика при включённой аутентификации
+var ReflectionMethods = []string{
+	"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo",
+	"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo",
+}
+
+func (s *Server) AddTeam(ctx context.Context, req *pb.AddTeamRequest) (*pb.Team, error) {
+	team := teamFromProto(req.GetTeam())
+	fields := []field{{"team.team_name", team.TeamName, validation.Name}}
+	seen := make(map[string]int)
+	for i, m := range team.Members {
+		prefix := fmt.Sprintf("team.members[%d].", i)
+		fields = append(fields, field{prefix + "user_id", m.UserID, validation.ID}, field{prefix + "username", m.Username, validation.Name})
+		if first, ok := seen[m.UserID]; ok {
+			return nil, status.Errorf(codes.InvalidArgument, "VALIDATION_ERROR: %suser_id: duplicates team.members[%d]", prefix, first)
+		}
+		seen[m.UserID] = i
+	}
+	if err := validate(fields...); err != nil {
+		return nil, err
+	}
+
+	if err := s.service.CreateTeam(ctx, team); err != nil {
+		return nil, err
+	}
+	return teamToProto(team), nil
+}
+
+func (s *Server) GetTeam(ctx context.Context, req *pb.GetTeamRequest) (*pb.Team, error) {
+	if err := validate(field{"team_name", req.GetTeamName(), validation.Name}); err != nil {
+		return nil, err
+	}
+	team, err := s.service.GetTeam(req.GetTeamName())
+	if err != nil {
+		return nil, err
+	}
+	return teamToProto(team), nil
+}
+
+func (s *Server) ListTeams(ctx context.Context, req *pb.ListTeamsRequest) (*pb.ListTeamsResponse, error) {
+	cursor, err := decodeCursor(req.GetCursor())
+	if err != nil {
+		return nil, err
+	}
+	teams, nextCursor, err := s.service.ListTeams(cursor, int(req.GetLimit()))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListTeamsResponse{NextCursor: nextCursor}
+	for _, t := range teams {
+		resp.Teams = append(resp.Teams, &pb.TeamSummary{
+			TeamName:    t.TeamName,
+			MemberCount: int32(t.MemberCount),
+			ActiveCount: int32(t.ActiveCount),
+			OpenPrCount: int32(t.OpenPRCount),
+		})
+	}
+	return resp, nil
+}
+
+func (s *Server) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.User, error) {
+	if err := validate(field{"user_id", req.GetUserId(), validation.ID}); err != nil {
+		return nil, err
+	}
+	user, err := s.service.GetUser(req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	return userToProto(user), nil
+}
+
+func (s *Server) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	filter := models.UserFilter{
+		TeamName:       req.GetTeamName(),
+		IsActive:       req.IsActive,
+		UsernamePrefix: req.GetUsernamePrefix(),
+		Limit:          int(req.GetLimit()),
+	}
+	cursor, err := decodeCursor(req.GetCursor())
+	if err != nil {
+		return nil, err
+	}
+	if cursor != nil {
+		filter.AfterID = cursor.ID
+	}
+
+	users, nextCursor, err := s.service.ListUsers(filter)
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.ListUsersResponse{NextCursor: nextCursor}
+	for i := range users {
+		resp.Users = append(resp.Users, userToProto(&users[i]))
+	}
+	return resp, nil
+}
+
+func (s *Server) SetUserActive(ctx context.Context, req *pb.SetUserActiveRequest) (*pb.User, error) {
+	if err := validate(field{"user_id", req.GetUserId(), validation.ID}); err != nil {
+		return nil, err
+	}
+
+	// Тимлид может менять активность только участников своей команды
+	if principal := auth.FromContext(ctx); principal != nil && principal.Role == auth.RoleTeamLead {
+		userTeam, err := s.service.GetUserTeam(req.GetUserId())
+		if err != nil {
+			return nil, err
+		}
+		if userTeam != principal.TeamName {
+			return nil, status.Error(codes.PermissionDenied, "FORBIDDEN: team lead can only change members of own team")
+		}
+	}
+
+	user, err := s.service.SetUserActive(ctx, req.GetUserId(), req.GetIsActive())
+	if err != nil {
+		return nil, err
+	}
+	return userToProto(user), nil
+}
+
+func (s *Server) CreatePullRequest(ctx context.Context, req *pb.CreatePullRequestRequest) (*pb.PullRequest, error) {
+	if err := validate(
+		field{"pull_request_id", req.GetPullRequestId(), validation.ID},
+		field{"pull_request_name", req.GetPullRequestName(), validation.Name},
+		field{"author_id", req.GetAuthorId(), validation.ID},
+	); err != nil {
+		return nil, err
+	}
+	pr, err := s.service.CreatePR(ctx, req.GetPullRequestId(), req.GetPullRequestName(), req.GetAuthorId())
+	if err != nil {
+		return nil, err
+	}
+	return prToProto(pr), nil
+}
+
+func (s *Server) MergePullRequest(ctx context.Context, req *pb.MergePullRequestRequest) (*pb.PullRequest, error) {
+	if err := validate(field{"pull_request_id", req.GetPullRequestId(), validation.ID}); err != nil {
+		return nil, err
+	}
+	pr, err := s.service.MergePR(ctx, req.GetPullRequestId())
+	if err != nil {
+		return nil, err
+	}
+	return prToProto(pr), nil
+}
+
+func (s *Server) ReassignReviewer(ctx context.Context, req *pb.ReassignReviewerRequest) (*pb.ReassignReviewerResponse, error) {
+	if err := validate(
+		field{"pull_request_id", req.GetPullRequestId(), validation.ID},
+		field{"old_user_id", req.GetOldUserId(), validation.ID},
+	); err != nil {
+		return nil, err
+	}
+	pr, newUserID, err := s.service.ReassignReviewer(ctx, req.GetPullRequestId(), req.GetOldUserId())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ReassignReviewerResponse{Pr: prToProto(pr), ReplacedBy: newUserID}, nil
+}
+
+func (s *Server) GetPullRequest(ctx context.Context, req *pb.GetPullRequestRequest) (*pb.PullRequest, error) {
+	if err := validate(field{"pull_request_id", req.GetPullRequestId(), validation.ID}); err != nil {
+		return nil, err
+	}
+	pr, err := s.service.GetPR(req.GetPullRequestId())
+	if err != nil {
+		return nil, err
+	}
+	return prToProto(pr), nil
+}
+
+func (s *Server) GetUserReviews(ctx context.Context, req *pb.GetUserReviewsRequest) (*pb.GetUserReviewsResponse, error) {
+	if err := validate(field{"user_id", req.GetUserId(), validation.ID}); err != nil {
+		return nil, err
+	}
+	filter := models.ReviewFilter{
+		UserID:        req.GetUserId(),
+		CreatedAfter:  fromTimestamp(req.GetCreatedAfter()),
+		CreatedBefore: fromTimestamp(req.GetCreatedBefore()),
+		SortDesc:      req.GetSortDesc(),
+		Limit:         int(req.GetLimit()),
+	}
+	for _, st := range req.GetStatuses() {
+		name, ok := statusFromProto[st]
+		if !ok {
+			return nil, invalidArgument("statuses must be OPEN or MERGED")
+		}
+		filter.Statuses = append(filter.Statuses, name)
+	}
+	var err error
+	if filter.After, err = decodeCursor(req.GetCursor()); err != nil {
+		return nil, err
+	}
+
+	page, err := s.service.GetUserReviewPRs(filter)
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.GetUserReviewsResponse{UserId: req.GetUserId(), NextCursor: page.NextCursor, Total: int32(page.Total)}
+	for i := range page.PullRequests {
+		resp.PullRequests = append(resp.PullRequests, prShortToProto(&page.PullRequests[i]))
+	}
+	return resp, nil
+}
+
+func (s *Server) GetStats(ctx context.Context, req *pb.GetStatsRequest) (*pb.Stats, error) {
+	stats, err := s.service.GetStats(models.StatsFilter{
+		TeamName: req.GetTeamName(),
+		From:     fromTimestamp(req.GetFrom()),
+		To:       fromTimestamp(req.GetTo()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return statsToProto(stats), nil
+}
+
+func (s *Server) SubscribeAssignments(req *pb.SubscribeAssignmentsRequest, stream pb.PRReviewerService_SubscribeAssignmentsServer) error {
+	if err := validate(field{"user_id", req.GetUserId(), validation.ID}); err != nil {
+		return err
+	}
+	if req.GetAfterEventId() < 0 {
+		return invalidArgument("after_event_id must not be negative")
+	}
+
+	userID := req.GetUserId()
+	afterID := req.GetAfterEventId()
+	if afterID == 0 {
+		var err error
+		if afterID, err = s.service.ReviewerEventsHead(); err != nil {
+			return err
+		}
+	}
+	return s.service.WatchReviewerEvents(stream.Context(), userID, afterID, func(e *models.PREvent) error {
+		return stream.Send(assignmentEventToProto(e, userID))
+	})
+}
+
+func decodeCursor(v string) (*models.PageCursor, error) {
+	if v == "" {
+		return nil, nil
+	}
+	var cursor models.PageCursor
+	if err := pagination.Decode(v, &cursor); err != nil {
+		return nil, invalidArgument("cursor is invalid")
+	}
+	return &cursor, nil
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"pr-reviewer-service/internal/service"
+)
+
+type AdminHandler struct {
+	service *service.PRService
+}
+
+func NewAdminHandler(service *service.PRService) *AdminHandler {
+	return &AdminHandler{service: service}
+}
+
+func (h *AdminHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Name     string `json:"name"`
+		Role     string `json:"role"`
+		UserID   string `json:"user_id"`
+		TeamName string `json:"team_name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	key, plain, err := h.service.CreateAPIKey(req.Name, req.Role, req.UserID, req.TeamName)
+	if err != nil {
+		switch err.Error() {
+		case "INVALID_NAME":
+			sendErrorResponse(w, "INVALID_NAME", "name is required", http.StatusBadRequest)
+		case "INVALID_ROLE":
+			sendErrorResponse(w, "INVALID_ROLE", "role must be one of admin, team-lead, member, bot", http.StatusBadRequest)
+		case "TEAM_REQUIRED":
+			sendErrorResponse(w, "TEAM_REQUIRED", "team-lead keys need team_name or user_id", http.StatusBadRequest)
+		case "KEY_EXISTS":
+			sendErrorResponse(w, "KEY_EXISTS", "api key with this name already exists", http.StatusConflict)
+		case "NOT_FOUND":
+			sendErrorResponse(w, "NOT_FOUND", "resource not found", http.StatusNotFound)
+		default:
+			sendError(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"api_key": key,
+		"key":     plain,
+	})
+}
+
+func (h *AdminHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	keys, err := h.service.ListAPIKeys()
+	if err != nil {
+		sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"api_keys": keys})
+}
+
+func (h *AdminHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	key, err := h.service.RevokeAPIKey(req.Name)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			sendErrorResponse(w, "NOT_FOUND", "resource not found", http.StatusNotFound)
+			return
+		}
+		sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"api_key": key})
+}
+
+// LinkChatIdentity связывает идентификатор пользователя в чате с user_id для slash-команд
+func (h *AdminHandler) LinkChatIdentity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ChatUserID string `json:"chat_user_id"`
+		UserID     string `json:"user_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ChatUserID == "" || req.UserID == "" {
+		sendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.LinkChatIdentity(req.ChatUserID, req.UserID); err != nil {
+		if err.Error() == "NOT_FOUND" {
+			sendErrorResponse(w, "NOT_FOUND", "resource not found", http.StatusNotFound)
+			return
+		}
+		sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"chat_user_id": req.ChatUserID,
+		"user_id":      req.UserID,
+	})
+}
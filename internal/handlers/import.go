@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"pr-reviewer-service/internal/importer"
+	"pr-reviewer-service/internal/models"
+	"strconv"
+)
+
+const maxImportSize = 32 << 20
+
+// Import принимает набор для массового импорта:
+//   - application/json - весь набор одним документом;
+//   - text/csv с параметром section - один раздел;
+//   - multipart/form-data - CSV-файлы в полях teams, users и pull_requests.
+//
+// С dry_run=true набор только проверяется. При ошибках возвращается 422 и отчёт по строкам.
+func (h *AdminHandler) Import(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	dryRun := false
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			sendErrorResponse(w, "INVALID_PARAM", "dry_run must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	bundle, err := decodeImport(r)
+	if err != nil {
+		sendErrorResponse(w, "INVALID_BUNDLE", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.service.Import(r.Context(), bundle, dryRun)
+	if err != nil {
+		sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	status := http.StatusOK
+	if len(report.Errors) > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
+
+func decodeImport(r *http.Request) (*models.ImportBundle, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("Content-Type must be application/json, text/csv or multipart/form-data")
+	}
+
+	switch mediaType {
+	case "application/json":
+		return importer.DecodeJSON(r.Body)
+
+	case "text/csv":
+		section := r.URL.Query().Get("section")
+		if _, ok := importer.Columns[section]; !ok {
+			return nil, fmt.Errorf("section must be teams, users or pull_requests")
+		}
+		bundle := &models.ImportBundle{}
+		if err := importer.DecodeCSV(bundle, section, r.Body); err != nil {
+			return nil, err
+		}
+		return bundle, nil
+
+	case "multipart/form-data":
+		if err := r.ParseMultipartForm(maxImportSize); err != nil {
+			return nil, fmt.Errorf("invalid multipart body: %v", err)
+		}
+		bundle := &models.ImportBundle{}
+		found := false
+		for _, section := range []string{importer.SectionTeams, importer.SectionUsers, importer.SectionPullRequests} {
+			file, _, err := r.FormFile(section)
+			if err == http.ErrMissingFile {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			err = importer.DecodeCSV(bundle, section, file)
+			file.Close()
+			if err != nil {
+				return nil, err
+			}
+			found = true
+		}
+		if !found {
+			return nil, fmt.Errorf("multipart body must contain teams, users or pull_requests files")
+		}
+		return bundle, nil
+	}
+	return nil, fmt.Errorf("Content-Type must be application/json, text/csv or multipart/form-data")
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"pr-reviewer-service/internal/auth"
+	"pr-reviewer-service/internal/models"
+	"pr-reviewer-service/internal/service"
+	"strings"
+)
+
+type ReminderHandler struct {
+	service *service.PRService
+}
+
+func NewReminderHandler(service *service.PRService) *ReminderHandler {
+	return &ReminderHandler{service: service}
+}
+
+// canManage - управлять подпиской может сам пользователь или администратор
+func canManage(r *http.Request, userID string) bool {
+	principal := auth.FromContext(r.Context())
+	return principal == nil || principal.Role == auth.RoleAdmin || principal.UserID == userID
+}
+
+func (h *ReminderHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		sendError(w, "user_id is required", http.StatusBadRequest)
+		return
+	}
+	if !canManage(r, userID) {
+		sendErrorResponse(w, "FORBIDDEN", "can only view own reminder settings", http.StatusForbidden)
+		return
+	}
+
+	settings, err := h.service.GetReminderSettings(userID)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			sendErrorResponse(w, "NOT_FOUND", "resource not found", http.StatusNotFound)
+			return
+		}
+		sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"reminders": settings})
+}
+
+func (h *ReminderHandler) Set(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var settings models.ReminderSettings
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		sendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if !canManage(r, settings.UserID) {
+		sendErrorResponse(w, "FORBIDDEN", "can only change own reminder settings", http.StatusForbidden)
+		return
+	}
+
+	if err := h.service.SetReminderSettings(&settings); err != nil {
+		if err.Error() == "NOT_FOUND" {
+			sendErrorResponse(w, "NOT_FOUND", "resource not found", http.StatusNotFound)
+			return
+		}
+		if strings.HasPrefix(err.Error(), "INVALID_REMINDER") {
+			sendErrorResponse(w, "INVALID_REMINDER", strings.TrimPrefix(err.Error(), "INVALID_REMINDER: "), http.StatusBadRequest)
+			return
+		}
+		sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"reminders": settings})
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"pr-reviewer-service/internal/auth"
+	"pr-reviewer-service/internal/models"
+	"pr-reviewer-service/internal/service"
+	"strconv"
+	"strings"
+)
+
+type SLAHandler struct {
+	service *service.PRService
+}
+
+func NewSLAHandler(service *service.PRService) *SLAHandler {
+	return &SLAHandler{service: service}
+}
+
+func (h *SLAHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		sendError(w, "team_name is required", http.StatusBadRequest)
+		return
+	}
+
+	settings, err := h.service.GetTeamSLA(teamName)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			sendErrorResponse(w, "NOT_FOUND", "resource not found", http.StatusNotFound)
+			return
+		}
+		sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"sla": settings})
+}
+
+func (h *SLAHandler) Set(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var settings models.TeamSLA
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		sendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if principal := auth.FromContext(r.Context()); principal != nil && principal.Role == auth.RoleTeamLead {
+		if principal.TeamName != settings.TeamName {
+			sendErrorResponse(w, "FORBIDDEN", "team lead can only change SLA of own team", http.StatusForbidden)
+			return
+		}
+	}
+
+	if err := h.service.SetTeamSLA(&settings); err != nil {
+		if err.Error() == "NOT_FOUND" {
+			sendErrorResponse(w, "NOT_FOUND", "resource not found", http.StatusNotFound)
+			return
+		}
+		if strings.HasPrefix(err.Error(), "INVALID_SLA") {
+			sendErrorResponse(w, "INVALID_SLA", strings.TrimPrefix(err.Error(), "INVALID_SLA: "), http.StatusBadRequest)
+			return
+		}
+		sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"sla": settings})
+}
+
+// Breaches возвращает нарушения SLA; по умолчанию только незакрытые (open=false - все)
+func (h *SLAHandler) Breaches(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	openOnly := true
+	if v := r.URL.Query().Get("open"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			sendErrorResponse(w, "INVALID_PARAM", "open must be true or false", http.StatusBadRequest)
+			return
+		}
+		openOnly = b
+	}
+
+	breaches, err := h.service.ListSLABreaches(r.URL.Query().Get("team_name"), openOnly)
+	if err != nil {
+		sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"breaches": breaches})
+}
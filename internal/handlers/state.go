@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"pr-reviewer-service/internal/models"
+	"strconv"
+	"strings"
+)
+
+const maxStateSize = 256 << 20
+
+// ExportState отдаёт архив всего состояния файлом для скачивания
+func (h *AdminHandler) ExportState(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	archive, err := h.service.ExportState(r.Context())
+	if err != nil {
+		sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="state-%s.json"`, archive.CreatedAt.Format("20060102-150405")))
+	json.NewEncoder(w).Encode(archive)
+}
+
+// ImportState восстанавливает архив состояния в пустую БД; с dry_run=true архив только проверяется
+func (h *AdminHandler) ImportState(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	dryRun := false
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			sendErrorResponse(w, "INVALID_PARAM", "dry_run must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var archive models.StateArchive
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxStateSize)).Decode(&archive); err != nil {
+		sendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.service.ImportState(r.Context(), &archive, dryRun)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "INVALID_ARCHIVE") {
+			sendErrorResponse(w, "INVALID_ARCHIVE", strings.TrimPrefix(err.Error(), "INVALID_ARCHIVE: "), http.StatusUnprocessableEntity)
+			return
+		}
+		if err.Error() == "NOT_EMPTY" {
+			sendErrorResponse(w, "NOT_EMPTY", "state can only be restored into an empty database", http.StatusConflict)
+			return
+		}
+		sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -22,7 +22,7 @@ func (h *TeamHandler) AddTeam(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.CreateTeam(&team); err != nil {
+	if err := h.service.CreateTeam(r.Context(), &team); err != nil {
 		if err.Error() == "TEAM_EXISTS" {
 			sendErrorResponse(w, "TEAM_EXISTS", "team_name already exists", http.StatusBadRequest)
 			return
@@ -0,0 +1,165 @@
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"pr-reviewer-service/internal/audit"
+	"pr-reviewer-service/internal/models"
+	"time"
+)
+
+const (
+	Header         = "Idempotency-Key"
+	ReplayedHeader = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+	// maxBodySize не меньше лимитов тела в обработчиках (архив состояния - 256 МБ)
+	maxBodySize = 256 << 20
+)
+
+// Store хранит ключи и сохранённые ответы
+type Store interface {
+	ReserveIdempotencyKey(actor, key, fingerprint string, now, expiresAt time.Time) (*models.IdempotencyRecord, bool, error)
+	CompleteIdempotencyKey(actor, key string, statusCode int, contentType string, body []byte) error
+	ReleaseIdempotencyKey(actor, key string) error
+}
+
+// Middleware делает POST-запросы с заголовком Idempotency-Key идемпотентными.
+// Первый ответ (статус и тело) сохраняется на ttl, повтор с тем же ключом и тем же запросом получает его же
+// с заголовком Idempotent-Replayed. Тот же ключ с другим запросом или пока первый ещё выполняется - 409.
+// Ответы 5xx не сохраняются, чтобы запрос можно было повторить.
+// Ключи разделены по инициатору, поэтому должен стоять после audit.Middleware.
+func Middleware(store Store, ttl time.Duration, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(Header)
+		if r.Method != "POST" || key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !validKey(key) {
+			sendErrorResponse(w, "INVALID_IDEMPOTENCY_KEY", "Idempotency-Key must be 1-255 printable ASCII characters", http.StatusBadRequest)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+		if err != nil {
+			sendErrorResponse(w, "INVALID_REQUEST", "request body is too large or unreadable", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		actor := audit.Actor(r.Context())
+		fingerprint := requestFingerprint(r, body)
+		now := time.Now()
+		record, reserved, err := store.ReserveIdempotencyKey(actor, key, fingerprint, now, now.Add(ttl))
+		if err != nil {
+			sendErrorResponse(w, "INTERNAL", err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if !reserved {
+			switch {
+			case record.Fingerprint != fingerprint:
+				sendErrorResponse(w, "IDEMPOTENCY_KEY_REUSED", "Idempotency-Key was already used with a different request", http.StatusConflict)
+			case record.StatusCode == 0:
+				sendErrorResponse(w, "IDEMPOTENCY_IN_PROGRESS", "a request with this Idempotency-Key is still in progress", http.StatusConflict)
+			default:
+				if record.ContentType != "" {
+					w.Header().Set("Content-Type", record.ContentType)
+				}
+				w.Header().Set(ReplayedHeader, "true")
+				w.WriteHeader(record.StatusCode)
+				w.Write(record.Body)
+			}
+			return
+		}
+
+		rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+		completed := false
+		defer func() {
+			// Паника или ошибка сервера: освобождаем ключ, иначе повтор получил бы IDEMPOTENCY_IN_PROGRESS до истечения ttl
+			if !completed {
+				store.ReleaseIdempotencyKey(actor, key)
+			}
+		}()
+
+		next.ServeHTTP(rec, r)
+
+		if rec.status >= 500 {
+			return
+		}
+		if err := store.CompleteIdempotencyKey(actor, key, rec.status, w.Header().Get("Content-Type"), rec.body.Bytes()); err != nil {
+			return
+		}
+		completed = true
+	})
+}
+
+// Disabled стоит вместо Middleware, когда идемпотентность выключена: POST с Idempotency-Key отклоняется,
+// иначе клиент считал бы повтор безопасным, а сервис выполнил бы его ещё раз
+func Disabled(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" && r.Header.Get(Header) != "" {
+			sendErrorResponse(w, "IDEMPOTENCY_DISABLED", "Idempotency-Key is not supported: idempotency is disabled on this server", http.StatusBadRequest)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestFingerprint - хеш метода, пути, параметров и тела запроса
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+"\n"+r.URL.Path+"\n"+r.URL.RawQuery+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func validKey(key string) bool {
+	if len(key) > maxKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x20 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// recorder пропускает ответ клиенту и запоминает статус и тело
+type recorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func sendErrorResponse(w http.ResponseWriter, code, message string, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	errorResp := models.ErrorResponse{}
+	errorResp.Error.Code = code
+	errorResp.Error.Message = message
+
+	json.NewEncoder(w).Encode(errorResp)
+}
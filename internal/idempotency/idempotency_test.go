@@ -0,0 +1,151 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pr-reviewer-service/internal/audit"
+	"pr-reviewer-service/internal/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memoryStore - Store в памяти; ключ считается занятым, пока не освобождён
+type memoryStore map[string]*models.IdempotencyRecord
+
+func (m memoryStore) ReserveIdempotencyKey(actor, key, fingerprint string, now, expiresAt time.Time) (*models.IdempotencyRecord, bool, error) {
+	if record, ok := m[actor+"/"+key]; ok {
+		return record, false, nil
+	}
+	m[actor+"/"+key] = &models.IdempotencyRecord{Fingerprint: fingerprint}
+	return nil, true, nil
+}
+
+func (m memoryStore) CompleteIdempotencyKey(actor, key string, statusCode int, contentType string, body []byte) error {
+	record := m[actor+"/"+key]
+	record.StatusCode, record.ContentType, record.Body = statusCode, contentType, body
+	return nil
+}
+
+func (m memoryStore) ReleaseIdempotencyKey(actor, key string) error {
+	delete(m, actor+"/"+key)
+	return nil
+}
+
+// counter отвечает 201 с номером вызова, а с параметром fail - 503
+type counter struct{ calls int }
+
+func (c *counter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.calls++
+	w.Header().Set("Content-Type", "application/json")
+	if r.URL.Query().Get("fail") != "" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]int{"call": c.calls})
+}
+
+func do(h http.Handler, method, target, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(Header, key)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func errorCode(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var resp models.ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("body %q: %v", rec.Body, err)
+	}
+	return resp.Error.Code
+}
+
+func TestMiddlewareReplaysResponse(t *testing.T) {
+	next := &counter{}
+	h := Middleware(memoryStore{}, time.Hour, next)
+
+	first := do(h, "POST", "/pullRequest/create", "k1", `{"pull_request_id":"pr-1"}`)
+	second := do(h, "POST", "/pullRequest/create", "k1", `{"pull_request_id":"pr-1"}`)
+	if next.calls != 1 {
+		t.Fatalf("handler called %d times, want 1", next.calls)
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %q, want %d %q", second.Code, second.Body, first.Code, first.Body)
+	}
+	if second.Header().Get(ReplayedHeader) != "true" || first.Header().Get(ReplayedHeader) != "" {
+		t.Errorf("%s: first %q, replay %q", ReplayedHeader, first.Header().Get(ReplayedHeader), second.Header().Get(ReplayedHeader))
+	}
+
+	reused := do(h, "POST", "/pullRequest/create", "k1", `{"pull_request_id":"pr-2"}`)
+	if reused.Code != http.StatusConflict || errorCode(t, reused) != "IDEMPOTENCY_KEY_REUSED" {
+		t.Errorf("same key, other body: %d %s", reused.Code, reused.Body)
+	}
+
+	// Без ключа и для GET запрос выполняется как обычно
+	do(h, "POST", "/pullRequest/create", "", `{}`)
+	do(h, "GET", "/pullRequest/get", "k1", "")
+	if next.calls != 3 {
+		t.Errorf("handler called %d times, want 3", next.calls)
+	}
+}
+
+func TestMiddlewareDoesNotStoreServerErrors(t *testing.T) {
+	next := &counter{}
+	store := memoryStore{}
+	h := Middleware(store, time.Hour, next)
+
+	for i := 0; i < 2; i++ {
+		if rec := do(h, "POST", "/pullRequest/merge?fail=1", "k1", `{}`); rec.Code != http.StatusServiceUnavailable {
+			t.Fatalf("status = %d", rec.Code)
+		}
+	}
+	if next.calls != 2 || len(store) != 0 {
+		t.Errorf("5xx: handler called %d times, %d keys kept", next.calls, len(store))
+	}
+}
+
+func TestMiddlewareInProgress(t *testing.T) {
+	store := memoryStore{}
+	// Первый запрос с ключом ещё выполняется: ключ занят, ответа нет
+	fingerprint := requestFingerprint(httptest.NewRequest("POST", "/pullRequest/create", nil), []byte(`{}`))
+	store.ReserveIdempotencyKey(audit.Actor(context.Background()), "k1", fingerprint, time.Now(), time.Now().Add(time.Hour))
+	rec := do(Middleware(store, time.Hour, &counter{}), "POST", "/pullRequest/create", "k1", `{}`)
+	if rec.Code != http.StatusConflict || errorCode(t, rec) != "IDEMPOTENCY_IN_PROGRESS" {
+		t.Errorf("reserved key: %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestMiddlewareInvalidKey(t *testing.T) {
+	for _, key := range []string{strings.Repeat("k", maxKeyLength+1), "ключ", "k\x01"} {
+		rec := do(Middleware(memoryStore{}, time.Hour, &counter{}), "POST", "/team/add", key, `{}`)
+		if rec.Code != http.StatusBadRequest || errorCode(t, rec) != "INVALID_IDEMPOTENCY_KEY" {
+			t.Errorf("key %q: %d %s", key, rec.Code, rec.Body)
+		}
+	}
+}
+
+func TestDisabledRejectsKey(t *testing.T) {
+	next := &counter{}
+	h := Disabled(next)
+
+	rec := do(h, "POST", "/pullRequest/reassign", "k1", `{}`)
+	if rec.Code != http.StatusBadRequest || errorCode(t, rec) != "IDEMPOTENCY_DISABLED" {
+		t.Errorf("POST with key: %d %s", rec.Code, rec.Body)
+	}
+	if next.calls != 0 {
+		t.Errorf("handler called %d times for a rejected request", next.calls)
+	}
+
+	for _, r := range []struct{ method, key string }{{"POST", ""}, {"GET", "k1"}} {
+		if rec := do(h, r.method, "/pullRequest/get", r.key, ""); rec.Code != http.StatusCreated {
+			t.Errorf("%s with key %q: status %d", r.method, r.key, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Package importer разбирает наборы данных для массового импорта из JSON и CSV.
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"pr-reviewer-service/internal/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Разделы набора; в CSV каждый раздел - отдельный файл
+const (
+	SectionTeams        = "teams"
+	SectionUsers        = "users"
+	SectionPullRequests = "pull_requests"
+)
+
+// Columns - обязательные и необязательные столбцы CSV каждого раздела
+var Columns = map[string][]string{
+	SectionTeams:        {"team_name"},
+	SectionUsers:        {"user_id", "username", "team_name", "is_active"},
+	SectionPullRequests: {"pull_request_id", "pull_request_name", "author_id", "status", "assigned_reviewers", "created_at", "merged_at"},
+}
+
+// DecodeJSON читает набор целиком в одном JSON-документе; неизвестные поля считаются ошибкой
+func DecodeJSON(r io.Reader) (*models.ImportBundle, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	var bundle models.ImportBundle
+	if err := dec.Decode(&bundle); err != nil {
+		return nil, fmt.Errorf("invalid JSON bundle: %v", err)
+	}
+	return &bundle, nil
+}
+
+// DecodeCSV добавляет в bundle записи раздела section из CSV с заголовком.
+// Ошибки в отдельных строках попадают в bundle.ParseErrors, ошибка возвращается только для файла целиком.
+func DecodeCSV(bundle *models.ImportBundle, section string, r io.Reader) error {
+	known, ok := Columns[section]
+	if !ok {
+		return fmt.Errorf("unknown section %q", section)
+	}
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v", section, err)
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		if !contains(known, name) {
+			return fmt.Errorf("%s: unknown column %q", section, name)
+		}
+		index[name] = i
+	}
+	if _, ok := index[known[0]]; !ok {
+		return fmt.Errorf("%s: missing column %q", section, known[0])
+	}
+
+	for row := 1; ; row++ {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", section, err)
+		}
+
+		get := func(column string) string {
+			i, ok := index[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		fail := func(id, format string, args ...interface{}) {
+			bundle.ParseErrors = append(bundle.ParseErrors, models.ImportError{
+				Section: section, Row: row, ID: id, Message: fmt.Sprintf(format, args...),
+			})
+		}
+
+		switch section {
+		case SectionTeams:
+			bundle.Teams = append(bundle.Teams, models.Team{TeamName: get("team_name")})
+
+		case SectionUsers:
+			user := models.User{
+				UserID:   get("user_id"),
+				Username: get("username"),
+				TeamName: get("team_name"),
+				IsActive: true,
+			}
+			if v := get("is_active"); v != "" {
+				if user.IsActive, err = strconv.ParseBool(v); err != nil {
+					fail(user.UserID, "is_active must be true or false")
+				}
+			}
+			bundle.Users = append(bundle.Users, user)
+
+		case SectionPullRequests:
+			pr := models.PullRequest{
+				PullRequestID:     get("pull_request_id"),
+				PullRequestName:   get("pull_request_name"),
+				AuthorID:          get("author_id"),
+				Status:            strings.ToUpper(get("status")),
+				AssignedReviewers: []string{},
+			}
+			if v := get("assigned_reviewers"); v != "" {
+				for _, reviewer := range strings.Split(v, ";") {
+					if reviewer = strings.TrimSpace(reviewer); reviewer != "" {
+						pr.AssignedReviewers = append(pr.AssignedReviewers, reviewer)
+					}
+				}
+			}
+			for _, f := range []struct {
+				column string
+				dst    **time.Time
+			}{{"created_at", &pr.CreatedAt}, {"merged_at", &pr.MergedAt}} {
+				if v := get(f.column); v != "" {
+					t, err := time.Parse(time.RFC3339, v)
+					if err != nil {
+						fail(pr.PullRequestID, "%s must be an RFC3339 timestamp", f.column)
+						continue
+					}
+					*f.dst = &t
+				}
+			}
+			bundle.PullRequests = append(bundle.PullRequests, pr)
+		}
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
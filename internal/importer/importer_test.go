@@ -0,0 +1,119 @@
+package importer
+
+import (
+	"pr-reviewer-service/internal/models"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	bundle, err := DecodeJSON(strings.NewReader(`{
+		"teams": [{"team_name": "backend", "members": [{"user_id": "u1", "username": "Alice", "is_active": true}]}],
+		"users": [{"user_id": "u2", "username": "Bob", "team_name": "backend", "is_active": false}],
+		"pull_requests": [{"pull_request_id": "pr-1", "pull_request_name": "Add search", "author_id": "u1", "status": "OPEN"}]
+	}`))
+	if err != nil {
+		t.Fatalf("DecodeJSON() = %v", err)
+	}
+	if len(bundle.Teams) != 1 || len(bundle.Teams[0].Members) != 1 || bundle.Users[0].UserID != "u2" || bundle.PullRequests[0].PullRequestID != "pr-1" {
+		t.Errorf("bundle = %+v", bundle)
+	}
+
+	for _, tt := range []struct{ name, input, err string }{
+		{"unknown section", `{"teams": [], "reviewers": []}`, `unknown field "reviewers"`},
+		{"unknown field", `{"users": [{"user_id": "u1", "role": "admin"}]}`, `unknown field "role"`},
+		{"malformed", `{"teams": [`, "unexpected EOF"},
+		{"wrong type", `{"users": [{"user_id": 1}]}`, "cannot unmarshal number"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeJSON(strings.NewReader(tt.input))
+			if err == nil || !strings.HasPrefix(err.Error(), "invalid JSON bundle: ") || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("DecodeJSON() = %v, want invalid JSON bundle: ...%s", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestDecodeCSVFileErrors(t *testing.T) {
+	tests := []struct {
+		name, section, input, err string
+	}{
+		{"unknown section", "reviewers", "user_id\nu1\n", `unknown section "reviewers"`},
+		{"unknown column", SectionUsers, "user_id,username,role\nu1,Alice,admin\n", `users: unknown column "role"`},
+		{"missing id column", SectionPullRequests, "pull_request_name,author_id\nAdd search,u1\n", `pull_requests: missing column "pull_request_id"`},
+		{"unbalanced quotes in header", SectionTeams, "\"team_name\n", `teams: parse error on line 1`},
+		{"unbalanced quotes in row", SectionUsers, "user_id,username\nu1,\"Alice\nu2,Bob\n", `users: record on line 2`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := DecodeCSV(&models.ImportBundle{}, tt.section, strings.NewReader(tt.input))
+			if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+				t.Errorf("DecodeCSV() = %v, want %s...", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestDecodeCSV(t *testing.T) {
+	bundle := &models.ImportBundle{}
+	files := []struct{ section, input string }{
+		{SectionTeams, ""},
+		{SectionTeams, "\ufeffteam_name\nbackend\n frontend\n"},
+		{SectionUsers, "user_id,username,team_name,is_active\n" +
+			"u1,Alice,backend,true\n" +
+			"u2,Bob,backend,\n" +
+			"u3,Carol,frontend,yes\n" +
+			"u1,Alice again,frontend,false\n" +
+			"u4,Dave\n"},
+		{SectionPullRequests, "pull_request_id,pull_request_name,author_id,status,assigned_reviewers,created_at,merged_at\n" +
+			"pr-1,Add search,u1,open,u2; ;u3,2025-03-01T10:00:00Z,\n" +
+			"pr-2,Fix login,u2,merged,,2025-03-01,yesterday\n" +
+			"pr-1,Add search,u1,,,,\n"},
+	}
+	for _, f := range files {
+		if err := DecodeCSV(bundle, f.section, strings.NewReader(f.input)); err != nil {
+			t.Fatalf("DecodeCSV(%s) = %v", f.section, err)
+		}
+	}
+
+	if want := []models.Team{{TeamName: "backend"}, {TeamName: "frontend"}}; !reflect.DeepEqual(bundle.Teams, want) {
+		t.Errorf("teams = %+v, want %+v", bundle.Teams, want)
+	}
+
+	// Дубликаты сохраняются в порядке строк: их находит проверка набора, указывая номер строки
+	wantUsers := []models.User{
+		{UserID: "u1", Username: "Alice", TeamName: "backend", IsActive: true},
+		{UserID: "u2", Username: "Bob", TeamName: "backend", IsActive: true},
+		{UserID: "u3", Username: "Carol", TeamName: "frontend", IsActive: false},
+		{UserID: "u1", Username: "Alice again", TeamName: "frontend", IsActive: false},
+		{UserID: "u4", Username: "Dave", IsActive: true},
+	}
+	if !reflect.DeepEqual(bundle.Users, wantUsers) {
+		t.Errorf("users = %+v, want %+v", bundle.Users, wantUsers)
+	}
+
+	prs := bundle.PullRequests
+	if len(prs) != 3 {
+		t.Fatalf("got %d pull requests, want 3", len(prs))
+	}
+	if prs[0].Status != "OPEN" || !reflect.DeepEqual(prs[0].AssignedReviewers, []string{"u2", "u3"}) ||
+		prs[0].CreatedAt == nil || prs[0].CreatedAt.Format("2006-01-02T15:04") != "2025-03-01T10:00" || prs[0].MergedAt != nil {
+		t.Errorf("pr-1 = %+v", prs[0])
+	}
+	if prs[1].Status != "MERGED" || prs[1].CreatedAt != nil || prs[1].MergedAt != nil || len(prs[1].AssignedReviewers) != 0 {
+		t.Errorf("pr-2 = %+v", prs[1])
+	}
+	if prs[2].PullRequestID != "pr-1" || prs[2].Status != "" || prs[2].AssignedReviewers == nil {
+		t.Errorf("duplicate pr-1 = %+v", prs[2])
+	}
+
+	wantErrors := []models.ImportError{
+		{Section: SectionUsers, Row: 3, ID: "u3", Message: "is_active must be true or false"},
+		{Section: SectionPullRequests, Row: 2, ID: "pr-2", Message: "created_at must be an RFC3339 timestamp"},
+		{Section: SectionPullRequests, Row: 2, ID: "pr-2", Message: "merged_at must be an RFC3339 timestamp"},
+	}
+	if !reflect.DeepEqual(bundle.ParseErrors, wantErrors) {
+		t.Errorf("parse errors = %+v, want %+v", bundle.ParseErrors, wantErrors)
+	}
+}
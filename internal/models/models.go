@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type TeamMember struct {
 	UserID   string `json:"user_id"`
@@ -28,18 +31,550 @@ type PullRequest struct {
 	AssignedReviewers []string   `json:"assigned_reviewers"`
 	CreatedAt         *time.Time `json:"createdAt,omitempty"`
 	MergedAt          *time.Time `json:"mergedAt,omitempty"`
+	// Overdue - у PR есть ревьювер, нарушивший SLA команды
+	Overdue bool `json:"overdue,omitempty"`
 }
 
 type PullRequestShort struct {
-	PullRequestID   string `json:"pull_request_id"`
-	PullRequestName string `json:"pull_request_name"`
-	AuthorID        string `json:"author_id"`
-	Status          string `json:"status"`
+	PullRequestID   string     `json:"pull_request_id"`
+	PullRequestName string     `json:"pull_request_name"`
+	AuthorID        string     `json:"author_id"`
+	Status          string     `json:"status"`
+	CreatedAt       *time.Time `json:"createdAt,omitempty"`
+	Overdue         bool       `json:"overdue,omitempty"`
+}
+
+// PageCursor - позиция keyset-пагинации по (created_at, id)
+type PageCursor struct {
+	CreatedAt time.Time `json:"c"`
+	ID        string    `json:"i"`
+	Desc      bool      `json:"d"`
+}
+
+type ReviewFilter struct {
+	UserID        string
+	Statuses      []string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	// SortDesc - сначала новые PR; иначе сначала самые старые
+	SortDesc bool
+	Limit    int
+	After    *PageCursor
+}
+
+type ReviewPage struct {
+	PullRequests []PullRequestShort `json:"pull_requests"`
+	NextCursor   string             `json:"next_cursor"`
+	Total        int                `json:"total"`
+}
+
+type PRListFilter struct {
+	TeamName   string
+	AuthorID   string
+	ReviewerID string
+	Statuses   []string
+	// MinAge/MaxAge задают границы по времени создания относительно текущего момента
+	MinAge *time.Duration
+	MaxAge *time.Duration
+	// NameContains - поиск подстроки без учёта регистра, Search - полнотекстовый поиск по названию
+	NameContains string
+	Search       string
+	NoReviewers  bool
+	OverdueOnly  bool
+	SortDesc     bool
+	Limit        int
+	After        *PageCursor
+}
+
+type PRListPage struct {
+	PullRequests []PullRequest `json:"pull_requests"`
+	NextCursor   string        `json:"next_cursor"`
+	Total        int           `json:"total"`
+}
+
+type TeamSummary struct {
+	TeamName    string `json:"team_name"`
+	MemberCount int    `json:"member_count"`
+	ActiveCount int    `json:"active_count"`
+	OpenPRCount int    `json:"open_pr_count"`
+}
+
+type ReviewerLoad struct {
+	UserID      string `json:"user_id"`
+	IsActive    bool   `json:"is_active"`
+	OpenReviews int    `json:"open_reviews"`
+}
+
+type UserFilter struct {
+	TeamName       string
+	IsActive       *bool
+	UsernamePrefix string
+	Limit          int
+	// AfterID - курсор: пользователи с user_id больше указанного
+	AfterID string
+}
+
+// TeamSLA - срок первого ревью в рабочих минутах и рабочий календарь команды
+type TeamSLA struct {
+	TeamName           string   `json:"team_name"`
+	FirstReviewMinutes int      `json:"first_review_minutes"`
+	TimeZone           string   `json:"time_zone"`
+	WorkdayStart       string   `json:"workday_start"`
+	WorkdayEnd         string   `json:"workday_end"`
+	WeekendDays        []string `json:"weekend_days"`
+	Holidays           []string `json:"holidays"`
+	AutoReassign       bool     `json:"auto_reassign"`
+}
+
+// ReviewAssignment - текущее назначение ревьювера на открытый PR
+type ReviewAssignment struct {
+	PullRequestID string
+	ReviewerID    string
+	TeamName      string
+	AssignedAt    time.Time
+}
+
+type SLABreach struct {
+	ID            int64      `json:"id"`
+	PullRequestID string     `json:"pull_request_id"`
+	ReviewerID    string     `json:"reviewer_id"`
+	TeamName      string     `json:"team_name"`
+	AssignedAt    time.Time  `json:"assigned_at"`
+	DetectedAt    time.Time  `json:"detected_at"`
+	ResolvedAt    *time.Time `json:"resolved_at,omitempty"`
+}
+
+// ReminderSettings - подписка пользователя на ежедневный дайджест ревью
+type ReminderSettings struct {
+	UserID  string `json:"user_id"`
+	Enabled bool   `json:"enabled"`
+	// Channel - email или webhook
+	Channel string `json:"channel"`
+	// Address - email получателя или URL вебхука; для webhook может быть пустым
+	Address    string     `json:"address,omitempty"`
+	TimeZone   string     `json:"time_zone"`
+	SendAt     string     `json:"send_at"`
+	LastSentOn *time.Time `json:"last_sent_on,omitempty"`
 }
 
 type ErrorResponse struct {
 	Error struct {
 		Code    string `json:"code"`
 		Message string `json:"message"`
+		// Details - ошибки отдельных полей запроса, например при VALIDATION_ERROR
+		Details []FieldError `json:"details,omitempty"`
 	} `json:"error"`
 }
+
+// FieldError - ошибка в поле запроса; In - body, query или header, Field - путь вида members[0].user_id
+type FieldError struct {
+	In      string `json:"in"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type APIKey struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Role       string     `json:"role"`
+	UserID     string     `json:"user_id,omitempty"`
+	TeamName   string     `json:"team_name,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+type AuditEntry struct {
+	ID         int64           `json:"id"`
+	CreatedAt  time.Time       `json:"created_at"`
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   string          `json:"target_id"`
+	RequestID  string          `json:"request_id,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+}
+
+type AuditFilter struct {
+	Actor      string
+	Action     string
+	TargetType string
+	TargetID   string
+	RequestID  string
+	From       *time.Time
+	To         *time.Time
+	// BeforeID - курсор: записи с id меньше указанного
+	BeforeID int64
+	Limit    int
+}
+
+// Типы событий в истории PR
+const (
+	EventCreated          = "CREATED"
+	EventReviewerAssigned = "REVIEWER_ASSIGNED"
+	EventReviewerReplaced = "REVIEWER_REPLACED"
+	EventMerged           = "MERGED"
+	EventSLABreached      = "SLA_BREACHED"
+)
+
+// Причины назначения ревьювера
+const (
+	ReasonInitialAssignment = "INITIAL_ASSIGNMENT"
+	ReasonManualReassign    = "MANUAL_REASSIGN"
+	ReasonSLABreach         = "SLA_BREACH"
+	ReasonImport            = "IMPORT"
+)
+
+type PREvent struct {
+	ID                 int64     `json:"id"`
+	PullRequestID      string    `json:"pull_request_id"`
+	Type               string    `json:"type"`
+	Actor              string    `json:"actor"`
+	ReviewerID         string    `json:"reviewer_id,omitempty"`
+	PreviousReviewerID string    `json:"previous_reviewer_id,omitempty"`
+	Reason             string    `json:"reason,omitempty"`
+	FromStatus         string    `json:"from_status,omitempty"`
+	ToStatus           string    `json:"to_status,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+// Виды событий с точки зрения ревьювера
+const (
+	ReviewerAssigned   = "assigned"
+	ReviewerUnassigned = "unassigned"
+	ReviewerPRMerged   = "merged"
+)
+
+// ReviewStreamEvent - событие потока /users/reviews/stream
+type ReviewStreamEvent struct {
+	EventID       int64  `json:"event_id"`
+	Kind          string `json:"kind"`
+	PullRequestID string `json:"pull_request_id"`
+	UserID        string `json:"user_id"`
+	// ReviewerID - кто заменил пользователя, если он снят с ревью
+	ReviewerID string       `json:"reviewer_id,omitempty"`
+	Actor      string       `json:"actor"`
+	Reason     string       `json:"reason,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+	PR         *PullRequest `json:"pr,omitempty"`
+}
+
+// ReviewerKind возвращает вид события для ревьювера userID или пустую строку, если событие его не касается
+func (e *PREvent) ReviewerKind(userID string) string {
+	switch e.Type {
+	case EventReviewerAssigned, EventReviewerReplaced:
+		if e.ReviewerID == userID {
+			return ReviewerAssigned
+		}
+		if e.PreviousReviewerID == userID {
+			return ReviewerUnassigned
+		}
+	case EventMerged:
+		return ReviewerPRMerged
+	}
+	return ""
+}
+
+// StatsFilter ограничивает статистику командой автора и интервалом создания PR
+type StatsFilter struct {
+	TeamName string     `json:"team_name,omitempty"`
+	From     *time.Time `json:"from,omitempty"`
+	To       *time.Time `json:"to,omitempty"`
+}
+
+// DurationStats - распределение длительностей в секундах
+type DurationStats struct {
+	Count         int      `json:"count"`
+	MedianSeconds *float64 `json:"median_seconds"`
+	P90Seconds    *float64 `json:"p90_seconds"`
+}
+
+type ReassignmentStats struct {
+	Total         int     `json:"total"`
+	PRsReassigned int     `json:"prs_reassigned"`
+	Rate          float64 `json:"rate"`
+}
+
+// AgeBucket - количество открытых PR с возрастом в [MinHours, MaxHours); MaxHours = 0 означает без верхней границы
+type AgeBucket struct {
+	Label    string `json:"label"`
+	MinHours int    `json:"min_hours"`
+	MaxHours int    `json:"max_hours,omitempty"`
+	Count    int    `json:"count"`
+}
+
+type ReviewerStats struct {
+	UserID          string `json:"user_id"`
+	AssignmentCount int    `json:"assignment_count"`
+	OpenReviews     int    `json:"open_reviews"`
+	ReassignedAway  int    `json:"reassigned_away"`
+}
+
+type Stats struct {
+	Filter       StatsFilter     `json:"filter"`
+	TotalTeams   int             `json:"total_teams"`
+	TotalUsers   int             `json:"total_users"`
+	TotalPRs     int             `json:"total_prs"`
+	OpenPRs      int             `json:"open_prs"`
+	MergedPRs    int             `json:"merged_prs"`
+	TopReviewers []ReviewerStats `json:"top_reviewers"`
+
+	TimeToMerge DurationStats `json:"time_to_merge"`
+	// TimeToFirstReview появится вместе с решениями ревьюверов; пока всегда null
+	TimeToFirstReview *DurationStats    `json:"time_to_first_review"`
+	Reassignments     ReassignmentStats `json:"reassignments"`
+	OpenPRAge         []AgeBucket       `json:"open_pr_age"`
+	ReviewerLoad      []ReviewerStats   `json:"reviewer_load"`
+}
+
+// StatusChange - запись истории активности пользователя
+type StatusChange struct {
+	UserID    string    `json:"user_id"`
+	IsActive  bool      `json:"is_active"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// MemberFairness - доля назначений участника команды относительно ожидаемой с учётом его доступности
+type MemberFairness struct {
+	UserID   string `json:"user_id"`
+	IsActive bool   `json:"is_active"`
+	// Availability - доля окна, в течение которой участник был активен
+	Availability        float64 `json:"availability"`
+	Assignments         int     `json:"assignments"`
+	ExpectedShare       float64 `json:"expected_share"`
+	ExpectedAssignments float64 `json:"expected_assignments"`
+	Deviation           float64 `json:"deviation"`
+	RelativeDeviation   float64 `json:"relative_deviation"`
+	// Flag - "over", "under" или пусто
+	Flag string `json:"flag,omitempty"`
+}
+
+type FairnessReport struct {
+	TeamName         string    `json:"team_name"`
+	From             time.Time `json:"from"`
+	To               time.Time `json:"to"`
+	TotalAssignments int       `json:"total_assignments"`
+	// Gini - коэффициент Джини по назначениям на единицу доступности: 0 - идеально ровно, 1 - всё у одного
+	Gini      float64          `json:"gini"`
+	Tolerance float64          `json:"tolerance"`
+	Members   []MemberFairness `json:"members"`
+}
+
+// Области снимков статистики
+const (
+	ScopeGlobal = "global"
+	ScopeTeam   = "team"
+	ScopeUser   = "user"
+)
+
+// StatsPoint - точка временного ряда. Assignments и Merges - прирост за интервал точки,
+// OpenPRs и OpenReviews - значения на конец интервала.
+type StatsPoint struct {
+	Time        time.Time `json:"time"`
+	OpenPRs     int       `json:"open_prs"`
+	OpenReviews int       `json:"open_reviews"`
+	Assignments int       `json:"assignments"`
+	Merges      int       `json:"merges"`
+}
+
+// StatsSnapshot - сохранённый снимок; счётчики накопленные
+type StatsSnapshot struct {
+	TakenAt          time.Time
+	OpenPRs          int
+	OpenReviews      int
+	AssignmentsTotal int
+	MergesTotal      int
+}
+
+type StatsSeries struct {
+	Scope   string       `json:"scope"`
+	Subject string       `json:"subject,omitempty"`
+	Bucket  string       `json:"bucket"`
+	From    time.Time    `json:"from"`
+	To      time.Time    `json:"to"`
+	Points  []StatsPoint `json:"points"`
+}
+
+// Assignment - назначение ревьювера на PR, восстановленное из истории
+type Assignment struct {
+	PullRequestID      string    `json:"pull_request_id"`
+	PullRequestName    string    `json:"pull_request_name"`
+	AuthorID           string    `json:"author_id"`
+	TeamName           string    `json:"team_name"`
+	ReviewerID         string    `json:"reviewer_id"`
+	PreviousReviewerID string    `json:"previous_reviewer_id,omitempty"`
+	Reason             string    `json:"reason"`
+	Actor              string    `json:"actor"`
+	AssignedAt         time.Time `json:"assigned_at"`
+	PRStatus           string    `json:"pr_status"`
+}
+
+// AssignmentFilter - TeamName относится к команде автора PR
+type AssignmentFilter struct {
+	TeamName      string
+	ReviewerID    string
+	PullRequestID string
+	From          *time.Time
+	To            *time.Time
+}
+
+// ImportBundle - набор команд, пользователей и исторических PR для массового импорта.
+// Участники команд из Teams[].Members импортируются так же, как записи Users.
+type ImportBundle struct {
+	Teams        []Team        `json:"teams"`
+	Users        []User        `json:"users"`
+	PullRequests []PullRequest `json:"pull_requests"`
+	// ParseErrors - ошибки разбора строк CSV, попадают в отчёт наравне с ошибками проверки
+	ParseErrors []ImportError `json:"-"`
+}
+
+// ImportError описывает проблему в строке импорта; Row - номер записи в разделе, начиная с 1
+type ImportError struct {
+	Section string `json:"section"`
+	Row     int    `json:"row"`
+	ID      string `json:"id,omitempty"`
+	Message string `json:"message"`
+}
+
+type ImportReport struct {
+	DryRun       bool          `json:"dry_run"`
+	Applied      bool          `json:"applied"`
+	NewTeams     int           `json:"new_teams"`
+	NewUsers     int           `json:"new_users"`
+	UpdatedUsers int           `json:"updated_users"`
+	PullRequests int           `json:"pull_requests"`
+	Errors       []ImportError `json:"errors"`
+}
+
+type ChatIdentity struct {
+	ChatUserID string `json:"chat_user_id"`
+	UserID     string `json:"user_id"`
+}
+
+// StateAPIKey - API-ключ в архиве состояния; хеш нужен, чтобы выданные ключи продолжили работать
+type StateAPIKey struct {
+	APIKey
+	KeyHash string `json:"key_hash"`
+}
+
+// StateData - всё состояние сервиса, кроме журнала аудита и снимков статистики
+type StateData struct {
+	Teams            []string           `json:"teams"`
+	Users            []User             `json:"users"`
+	StatusChanges    []StatusChange     `json:"status_changes"`
+	PullRequests     []PullRequest      `json:"pull_requests"`
+	PREvents         []PREvent          `json:"pr_events"`
+	TeamSLAs         []TeamSLA          `json:"team_slas"`
+	SLABreaches      []SLABreach        `json:"sla_breaches"`
+	ReminderSettings []ReminderSettings `json:"reminder_settings"`
+	ChatIdentities   []ChatIdentity     `json:"chat_identities"`
+	APIKeys          []StateAPIKey      `json:"api_keys"`
+}
+
+// Counts возвращает число записей каждого вида для проверки целостности архива
+func (d *StateData) Counts() map[string]int {
+	return map[string]int{
+		"teams":             len(d.Teams),
+		"users":             len(d.Users),
+		"status_changes":    len(d.StatusChanges),
+		"pull_requests":     len(d.PullRequests),
+		"pr_events":         len(d.PREvents),
+		"team_slas":         len(d.TeamSLAs),
+		"sla_breaches":      len(d.SLABreaches),
+		"reminder_settings": len(d.ReminderSettings),
+		"chat_identities":   len(d.ChatIdentities),
+		"api_keys":          len(d.APIKeys),
+	}
+}
+
+// StateArchive - переносимый архив состояния, не зависящий от хранилища.
+// Checksum - SHA-256 от JSON-представления Data.
+type StateArchive struct {
+	Format        string         `json:"format"`
+	Version       int            `json:"version"`
+	SchemaVersion int            `json:"schema_version"`
+	CreatedAt     time.Time      `json:"created_at"`
+	Counts        map[string]int `json:"counts"`
+	Checksum      string         `json:"checksum"`
+	Data          StateData      `json:"data"`
+}
+
+type StateRestoreReport struct {
+	DryRun        bool           `json:"dry_run"`
+	Restored      bool           `json:"restored"`
+	SchemaVersion int            `json:"schema_version"`
+	Counts        map[string]int `json:"counts"`
+}
+
+// IdempotencyRecord - сохранённый ответ на запрос с Idempotency-Key; StatusCode = 0, пока запрос выполняется
+type IdempotencyRecord struct {
+	Fingerprint string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+const (
+	BatchAtomic     = "atomic"
+	BatchBestEffort = "best_effort"
+
+	BatchOpCreate   = "create"
+	BatchOpMerge    = "merge"
+	BatchOpReassign = "reassign"
+
+	BatchItemOK      = "ok"
+	BatchItemError   = "error"
+	BatchItemSkipped = "skipped"
+)
+
+// PRBatchOperation - операция пакета; поля те же, что в теле одиночных эндпоинтов
+type PRBatchOperation struct {
+	Op              string `json:"op"`
+	PullRequestID   string `json:"pull_request_id"`
+	PullRequestName string `json:"pull_request_name,omitempty"`
+	AuthorID        string `json:"author_id,omitempty"`
+	OldUserID       string `json:"old_user_id,omitempty"`
+}
+
+type PRBatchRequest struct {
+	Mode       string             `json:"mode"`
+	Operations []PRBatchOperation `json:"operations"`
+}
+
+// PRBatchMutation - проверенное изменение, готовое к записи
+type PRBatchMutation struct {
+	Op          string
+	PR          *PullRequest
+	OldReviewer string
+	NewReviewer string
+	// PreviousReviewers - ревьюверы PR, по которым проверялась замена; запись применяется, только если они не изменились
+	PreviousReviewers []string
+	At                time.Time
+	// Audit - запись журнала аудита, которая сохраняется в одной транзакции с изменением
+	Audit *AuditEntry
+}
+
+type BatchItemErrorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type PRBatchResult struct {
+	Index         int                 `json:"index"`
+	Op            string              `json:"op"`
+	PullRequestID string              `json:"pull_request_id"`
+	Status        string              `json:"status"`
+	PR            *PullRequest        `json:"pr,omitempty"`
+	ReplacedBy    string              `json:"replaced_by,omitempty"`
+	Error         *BatchItemErrorBody `json:"error,omitempty"`
+	Err           error               `json:"-"`
+}
+
+type PRBatchReport struct {
+	Mode    string          `json:"mode"`
+	Applied int             `json:"applied"`
+	Failed  int             `json:"failed"`
+	Results []PRBatchResult `json:"results"`
+}
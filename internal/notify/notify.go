@@ -0,0 +1,17 @@
+package notify
+
+import "context"
+
+// Message - уведомление одному получателю
+type Message struct {
+	UserID string
+	// Address - адрес в канале доставки: email или URL вебхука; пустой - адрес канала по умолчанию
+	Address string
+	Subject string
+	Text    string
+}
+
+// Notifier доставляет сообщения по одному каналу
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
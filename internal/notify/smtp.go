@@ -0,0 +1,115 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+type SMTPOptions struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPNotifier отправляет письма через SMTP. Без Username отправляет без аутентификации,
+// что позволяет проверять доставку на локальном тестовом SMTP-сервере.
+type SMTPNotifier struct {
+	opts SMTPOptions
+}
+
+func NewSMTPNotifier(opts SMTPOptions) *SMTPNotifier {
+	return &SMTPNotifier{opts: opts}
+}
+
+func (n *SMTPNotifier) Send(ctx context.Context, msg Message) error {
+	if msg.Address == "" {
+		return fmt.Errorf("smtp: recipient address is empty")
+	}
+	if strings.ContainsAny(msg.Address, "\r\n") || strings.ContainsAny(msg.Subject, "\r\n") {
+		return fmt.Errorf("smtp: header values must not contain line breaks")
+	}
+
+	var auth smtp.Auth
+	if n.opts.Username != "" {
+		auth = smtp.PlainAuth("", n.opts.Username, n.opts.Password, n.opts.Host)
+	}
+
+	body := strings.Join([]string{
+		"From: " + n.opts.From,
+		"To: " + msg.Address,
+		"Subject: " + msg.Subject,
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		strings.ReplaceAll(msg.Text, "\n", "\r\n"),
+	}, "\r\n")
+
+	return n.send(ctx, msg.Address, auth, []byte(body))
+}
+
+// send повторяет smtp.SendMail, но соединение живёт не дольше ctx: при отмене оно закрывается,
+// и зависший сервер не оставляет за собой ни соединения, ни горутины
+func (n *SMTPNotifier) send(ctx context.Context, to string, auth smtp.Auth, body []byte) (err error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(n.opts.Host, n.opts.Port))
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer func() {
+		stop()
+		// Ошибка чтения из соединения, закрытого по отмене или сроку, - это ошибка контекста
+		if err != nil && ctx.Err() != nil {
+			err = ctx.Err()
+		}
+	}()
+
+	c, err := smtp.NewClient(conn, n.opts.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: n.opts.Host}); err != nil {
+			return err
+		}
+	}
+	if auth != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return fmt.Errorf("smtp: server doesn't support AUTH")
+		}
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(n.opts.From); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
@@ -0,0 +1,273 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// smtpEnvelope - то, что получил тестовый SMTP-сервер за одну сессию
+type smtpEnvelope struct {
+	From string
+	To   []string
+	Auth string
+	Data string
+}
+
+// fakeSMTP - минимальный SMTP-сервер на локальном адресе: принимает одно письмо за соединение
+type fakeSMTP struct {
+	ln       net.Listener
+	withAuth bool
+
+	mu        sync.Mutex
+	envelopes []smtpEnvelope
+}
+
+func newFakeSMTP(t *testing.T, withAuth bool) *fakeSMTP {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeSMTP{ln: ln, withAuth: withAuth}
+	t.Cleanup(func() { ln.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *fakeSMTP) hostPort() (string, string) {
+	host, port, _ := net.SplitHostPort(s.ln.Addr().String())
+	return host, port
+}
+
+func (s *fakeSMTP) received() []smtpEnvelope {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]smtpEnvelope(nil), s.envelopes...)
+}
+
+func (s *fakeSMTP) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.session(conn)
+	}
+}
+
+func (s *fakeSMTP) session(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	var env smtpEnvelope
+	reply("220 localhost ESMTP test")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch verb {
+		case "EHLO", "HELO":
+			if s.withAuth {
+				reply("250-localhost")
+				reply("250 AUTH PLAIN")
+			} else {
+				reply("250 localhost")
+			}
+		case "AUTH":
+			env.Auth = strings.TrimPrefix(line, "AUTH PLAIN ")
+			reply("235 2.7.0 Authentication successful")
+		case "MAIL":
+			env.From = strings.TrimSuffix(strings.TrimPrefix(line, "MAIL FROM:<"), ">")
+			if i := strings.Index(env.From, ">"); i >= 0 {
+				env.From = env.From[:i]
+			}
+			reply("250 OK")
+		case "RCPT":
+			env.To = append(env.To, strings.TrimSuffix(strings.TrimPrefix(line, "RCPT TO:<"), ">"))
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			env.Data = data.String()
+			s.mu.Lock()
+			s.envelopes = append(s.envelopes, env)
+			s.mu.Unlock()
+			reply("250 OK queued")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// parseMail делит письмо на заголовки и тело
+func parseMail(t *testing.T, data string) (map[string]string, string) {
+	t.Helper()
+	head, body, ok := strings.Cut(data, "\r\n\r\n")
+	if !ok {
+		t.Fatalf("message has no header/body separator: %q", data)
+	}
+	headers := map[string]string{}
+	for _, line := range strings.Split(head, "\r\n") {
+		name, value, _ := strings.Cut(line, ": ")
+		headers[name] = value
+	}
+	return headers, body
+}
+
+func TestSMTPNotifierSendsDigest(t *testing.T) {
+	server := newFakeSMTP(t, false)
+	host, port := server.hostPort()
+	n := NewSMTPNotifier(SMTPOptions{Host: host, Port: port, From: "pr-reviewer@example.com"})
+
+	digest := "Open pull requests assigned to u2, oldest first:\n\n" +
+		"- pr-1 \"Add search\" by u1, open 3d\n" +
+		"- pr-2 \"Fix login\" by u3, open 5h [OVERDUE]\n" +
+		".hidden line starts with a dot\n"
+	err := n.Send(context.Background(), Message{
+		UserID:  "u2",
+		Address: "bob@example.com",
+		Subject: "2 PR waiting for your review",
+		Text:    digest,
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	envelopes := server.received()
+	if len(envelopes) != 1 {
+		t.Fatalf("received %d messages, want 1", len(envelopes))
+	}
+	env := envelopes[0]
+	if env.From != "pr-reviewer@example.com" {
+		t.Errorf("MAIL FROM = %q", env.From)
+	}
+	if len(env.To) != 1 || env.To[0] != "bob@example.com" {
+		t.Errorf("RCPT TO = %q", env.To)
+	}
+	if env.Auth != "" {
+		t.Errorf("AUTH sent without credentials: %q", env.Auth)
+	}
+
+	headers, body := parseMail(t, env.Data)
+	want := map[string]string{
+		"From":         "pr-reviewer@example.com",
+		"To":           "bob@example.com",
+		"Subject":      "2 PR waiting for your review",
+		"MIME-Version": "1.0",
+		"Content-Type": "text/plain; charset=UTF-8",
+	}
+	for name, value := range want {
+		if headers[name] != value {
+			t.Errorf("header %s = %q, want %q", name, headers[name], value)
+		}
+	}
+	if _, err := time.Parse(time.RFC1123Z, headers["Date"]); err != nil {
+		t.Errorf("Date header %q: %v", headers["Date"], err)
+	}
+
+	wantBody := strings.ReplaceAll(digest, "\n", "\r\n")
+	if strings.TrimSuffix(body, "\r\n") != strings.TrimSuffix(wantBody, "\r\n") {
+		t.Errorf("body = %q, want %q", body, wantBody)
+	}
+}
+
+func TestSMTPNotifierAuth(t *testing.T) {
+	server := newFakeSMTP(t, true)
+	host, port := server.hostPort()
+	n := NewSMTPNotifier(SMTPOptions{Host: host, Port: port, Username: "bot", Password: "s3cret", From: "bot@example.com"})
+
+	if err := n.Send(context.Background(), Message{Address: "a@example.com", Subject: "s", Text: "t"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	envelopes := server.received()
+	if len(envelopes) != 1 {
+		t.Fatalf("received %d messages, want 1", len(envelopes))
+	}
+	credentials, err := base64.StdEncoding.DecodeString(envelopes[0].Auth)
+	if err != nil {
+		t.Fatalf("AUTH PLAIN payload %q: %v", envelopes[0].Auth, err)
+	}
+	if string(credentials) != "\x00bot\x00s3cret" {
+		t.Errorf("AUTH PLAIN = %q", credentials)
+	}
+}
+
+func TestSMTPNotifierRejectsHeaderInjection(t *testing.T) {
+	server := newFakeSMTP(t, false)
+	host, port := server.hostPort()
+	n := NewSMTPNotifier(SMTPOptions{Host: host, Port: port, From: "bot@example.com"})
+
+	for _, msg := range []Message{
+		{Address: ""},
+		{Address: "a@example.com\r\nBcc: victim@example.com"},
+		{Address: "a@example.com", Subject: "hi\nBcc: victim@example.com"},
+	} {
+		if err := n.Send(context.Background(), msg); err == nil {
+			t.Errorf("Send(%+v) succeeded", msg)
+		}
+	}
+	if got := server.received(); len(got) != 0 {
+		t.Errorf("server received %d messages", len(got))
+	}
+}
+
+func TestSMTPNotifierContextCanceled(t *testing.T) {
+	// Сервер принимает соединение, но не отвечает: Send должен вернуться по контексту и закрыть соединение
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	conns := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := ln.Accept(); err == nil {
+			conns <- conn
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	n := NewSMTPNotifier(SMTPOptions{Host: host, Port: port, From: "bot@example.com"})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if err := n.Send(ctx, Message{Address: "a@example.com", Subject: "s", Text: "t"}); err != context.DeadlineExceeded {
+		t.Fatalf("Send() error = %v, want context.DeadlineExceeded", err)
+	}
+
+	var conn net.Conn
+	select {
+	case conn = <-conns:
+	case <-time.After(time.Second):
+		t.Fatal("server did not see the connection")
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := conn.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("connection after Send returned: read error = %v, want io.EOF", err)
+	}
+}
@@ -0,0 +1,125 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateAddress - адрес вебхука ведёт в локальную или внутреннюю сеть
+var ErrPrivateAddress = errors.New("webhook: address resolves to a loopback, private or link-local network")
+
+// WebhookNotifier отправляет сообщения во входящий вебхук чата (Slack, Mattermost и совместимые).
+// Тело запроса: {"text": "..."}.
+//
+// Адреса подписчиков задают сами пользователи, поэтому запросы по ним идут только в публичные сети:
+// адрес проверяется при каждом соединении, включая редиректы и повторное разрешение DNS.
+// Вебхук по умолчанию задан в конфигурации и может быть внутренним.
+type WebhookNotifier struct {
+	defaultURL string
+	client     *http.Client
+	// public - клиент для адресов подписчиков
+	public *http.Client
+}
+
+func NewWebhookNotifier(defaultURL string, timeout time.Duration) *WebhookNotifier {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, Control: denyPrivate}
+	return &WebhookNotifier{
+		defaultURL: defaultURL,
+		client:     &http.Client{Timeout: timeout},
+		public: &http.Client{
+			Timeout: timeout,
+			// Без прокси: иначе соединение идёт к прокси, и проверка адреса назначения теряет смысл
+			Transport: &http.Transport{DialContext: dialer.DialContext, TLSHandshakeTimeout: 10 * time.Second},
+		},
+	}
+}
+
+// ValidateWebhookURL проверяет адрес вебхука подписчика: http(s) с хостом, все адреса которого публичные
+func ValidateWebhookURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("webhook: address must be an http(s) URL")
+	}
+
+	host := u.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if !publicIP(ip) {
+			return ErrPrivateAddress
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("webhook: resolve %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if !publicIP(addr.IP) {
+			return ErrPrivateAddress
+		}
+	}
+	return nil
+}
+
+// denyPrivate - Control для net.Dialer: вызывается с уже разрешённым адресом перед каждым соединением
+func denyPrivate(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+		return ErrPrivateAddress
+	}
+	return nil
+}
+
+// sharedAddressSpace - 100.64.0.0/10 (RFC 6598), адреса за NAT оператора
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+func publicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() || sharedAddressSpace.Contains(ip))
+}
+
+func (n *WebhookNotifier) Send(ctx context.Context, msg Message) error {
+	target, client := msg.Address, n.public
+	if target == "" {
+		target, client = n.defaultURL, n.client
+	}
+	if target == "" {
+		return fmt.Errorf("webhook: no URL configured")
+	}
+
+	text := msg.Text
+	if msg.Subject != "" {
+		text = "*" + msg.Subject + "*\n" + text
+	}
+	payload, _ := json.Marshal(map[string]string{"text": text})
+
+	req, err := http.NewRequestWithContext(ctx, "POST", target, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook: unexpected status %s", resp.Status)
+	}
+	return nil
+}
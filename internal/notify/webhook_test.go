@@ -0,0 +1,95 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestValidateWebhookURL(t *testing.T) {
+	tests := []struct {
+		url string
+		ok  bool
+	}{
+		{"https://203.0.113.10/hooks/abc", true},
+		{"http://[2001:db8::1]:8080/hook", true},
+
+		{"ftp://203.0.113.10/hook", false},
+		{"https:///hook", false},
+		{"not a url", false},
+		{"http://127.0.0.1:8080/admin", false},
+		{"http://127.1.2.3/", false},
+		{"http://[::1]/", false},
+		{"http://0.0.0.0/", false},
+		{"http://10.0.0.5/", false},
+		{"http://172.16.3.4/", false},
+		{"http://192.168.1.1/", false},
+		{"http://169.254.169.254/latest/meta-data/", false},
+		{"http://[fe80::1]/", false},
+		{"http://[fd00::1]/", false},
+		{"http://[::ffff:127.0.0.1]/", false},
+		{"http://100.64.0.1/", false},
+		{"http://localhost:8080/", false},
+	}
+	for _, tt := range tests {
+		err := ValidateWebhookURL(context.Background(), tt.url)
+		if tt.ok && err != nil {
+			t.Errorf("ValidateWebhookURL(%q) = %v", tt.url, err)
+		}
+		if !tt.ok && err == nil {
+			t.Errorf("ValidateWebhookURL(%q) accepted", tt.url)
+		}
+	}
+}
+
+func TestDenyPrivateAtDial(t *testing.T) {
+	for _, address := range []string{"127.0.0.1:80", "[::1]:443", "10.1.2.3:80", "169.254.169.254:80"} {
+		if err := denyPrivate("tcp", address, nil); !errors.Is(err, ErrPrivateAddress) {
+			t.Errorf("denyPrivate(%q) = %v", address, err)
+		}
+	}
+	if err := denyPrivate("tcp", "203.0.113.10:443", nil); err != nil {
+		t.Errorf("denyPrivate(public) = %v", err)
+	}
+}
+
+func TestWebhookNotifierBlocksPrivateSubscriberAddress(t *testing.T) {
+	var calls int
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer target.Close()
+
+	// Адрес подписчика проверяется при соединении, даже если при подписке он был публичным
+	n := NewWebhookNotifier("", 0)
+	err := n.Send(context.Background(), Message{Address: target.URL, Text: "digest"})
+	if !errors.Is(err, ErrPrivateAddress) {
+		t.Fatalf("Send() error = %v, want ErrPrivateAddress", err)
+	}
+	if calls != 0 {
+		t.Errorf("private server received %d requests", calls)
+	}
+}
+
+func TestWebhookNotifierDefaultURL(t *testing.T) {
+	var got map[string]string
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("request %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer target.Close()
+
+	// Вебхук по умолчанию задан в конфигурации и может быть внутренним
+	n := NewWebhookNotifier(target.URL, 0)
+	if err := n.Send(context.Background(), Message{Subject: "2 PR", Text: "- pr-1"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got["text"] != "*2 PR*\n- pr-1" {
+		t.Errorf("payload = %v", got)
+	}
+}
@@ -0,0 +1,310 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed openapi.yaml
+var specYAML []byte
+
+//go:embed docs.html
+var docsHTML []byte
+
+// Spec - разобранная спецификация: JSON для /openapi.json и операции для проверки запросов
+type Spec struct {
+	json []byte
+	// ops - операции по пути и методу в нижнем регистре
+	ops map[string]map[string]*Operation
+}
+
+type Operation struct {
+	Parameters  []*Parameter `json:"parameters"`
+	RequestBody *RequestBody `json:"requestBody"`
+	// SkipBodyValidation - тело проверяет сам обработчик (большие архивы, CSV с отчётом по строкам)
+	SkipBodyValidation bool `json:"x-skip-body-validation"`
+	// MaxBodyBytes - предел размера тела, по умолчанию DefaultMaxBodyBytes
+	MaxBodyBytes int64 `json:"x-max-body-bytes"`
+	// SkipClient - операции нет в Go-клиенте (страницы, документация, вебхуки чата)
+	SkipClient bool `json:"x-skip-client"`
+}
+
+type Parameter struct {
+	Ref      string  `json:"$ref"`
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool `json:"required"`
+	Content  map[string]struct {
+		Schema *Schema `json:"schema"`
+	} `json:"content"`
+}
+
+// Schema - подмножество JSON Schema из OpenAPI 3.0, достаточное для проверки запросов
+type Schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Format               string             `json:"format"`
+	Pattern              string             `json:"pattern"`
+	Enum                 []interface{}      `json:"enum"`
+	Required             []string           `json:"required"`
+	Properties           map[string]*Schema `json:"properties"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties"`
+	Items                *Schema            `json:"items"`
+	AllOf                []*Schema          `json:"allOf"`
+	OneOf                []*Schema          `json:"oneOf"`
+	MinLength            *int               `json:"minLength"`
+	MaxLength            *int               `json:"maxLength"`
+	MinItems             *int               `json:"minItems"`
+	MaxItems             *int               `json:"maxItems"`
+	UniqueItems          bool               `json:"uniqueItems"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	Nullable             bool               `json:"nullable"`
+	ReadOnly             bool               `json:"readOnly"`
+	// UniqueKey - поле, значение которого не должно повторяться в элементах массива объектов
+	UniqueKey string `json:"x-unique-key"`
+
+	pattern *regexp.Regexp
+	// noAdditional - additionalProperties: false
+	noAdditional bool
+}
+
+type document struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas    map[string]*Schema    `json:"schemas"`
+		Parameters map[string]*Parameter `json:"parameters"`
+	} `json:"components"`
+}
+
+var methods = map[string]bool{"get": true, "post": true, "put": true, "patch": true, "delete": true}
+
+// MustLoad разбирает встроенную спецификацию; ошибка означает ошибку в openapi.yaml
+func MustLoad() *Spec {
+	spec, err := Load(specYAML)
+	if err != nil {
+		panic("openapi: " + err.Error())
+	}
+	return spec
+}
+
+// Load разбирает спецификацию в YAML и разрешает ссылки $ref на components
+func Load(data []byte) (*Spec, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	specJSON, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc document
+	if err := json.Unmarshal(specJSON, &doc); err != nil {
+		return nil, err
+	}
+
+	r := &resolver{doc: &doc, done: make(map[*Schema]bool)}
+	spec := &Spec{json: specJSON, ops: make(map[string]map[string]*Operation)}
+	for path, item := range doc.Paths {
+		spec.ops[path] = make(map[string]*Operation)
+		for method, rawOp := range item {
+			if !methods[method] {
+				continue
+			}
+			var op Operation
+			if err := json.Unmarshal(rawOp, &op); err != nil {
+				return nil, fmt.Errorf("%s %s: %v", method, path, err)
+			}
+			for i, p := range op.Parameters {
+				if op.Parameters[i], err = r.parameter(p); err != nil {
+					return nil, fmt.Errorf("%s %s: %v", method, path, err)
+				}
+			}
+			if op.RequestBody != nil {
+				for mediaType, content := range op.RequestBody.Content {
+					if content.Schema, err = r.schema(content.Schema); err != nil {
+						return nil, fmt.Errorf("%s %s: %v", method, path, err)
+					}
+					op.RequestBody.Content[mediaType] = content
+				}
+			}
+			spec.ops[path][method] = &op
+		}
+	}
+	return spec, nil
+}
+
+type resolver struct {
+	doc  *document
+	done map[*Schema]bool
+}
+
+func (r *resolver) parameter(p *Parameter) (*Parameter, error) {
+	if p.Ref != "" {
+		name := strings.TrimPrefix(p.Ref, "#/components/parameters/")
+		ref, ok := r.doc.Components.Parameters[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown parameter %s", p.Ref)
+		}
+		p = ref
+	}
+	var err error
+	p.Schema, err = r.schema(p.Schema)
+	return p, err
+}
+
+// schema заменяет $ref на схему из components и готовит вложенные схемы к проверке
+func (r *resolver) schema(s *Schema) (*Schema, error) {
+	if s == nil {
+		return nil, nil
+	}
+	if s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+		ref, ok := r.doc.Components.Schemas[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown schema %s", s.Ref)
+		}
+		s = ref
+	}
+	if r.done[s] {
+		return s, nil
+	}
+	r.done[s] = true
+
+	var err error
+	if s.Pattern != "" {
+		if s.pattern, err = regexp.Compile(s.Pattern); err != nil {
+			return nil, err
+		}
+	}
+	s.noAdditional = string(s.AdditionalProperties) == "false"
+	for name, prop := range s.Properties {
+		if s.Properties[name], err = r.schema(prop); err != nil {
+			return nil, err
+		}
+	}
+	if s.Items, err = r.schema(s.Items); err != nil {
+		return nil, err
+	}
+	for _, list := range [][]*Schema{s.AllOf, s.OneOf} {
+		for i := range list {
+			if list[i], err = r.schema(list[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return s, nil
+}
+
+// Operation возвращает описание операции или nil, если её нет в спецификации
+func (s *Spec) Operation(method, path string) *Operation {
+	return s.ops[path][strings.ToLower(method)]
+}
+
+// Methods возвращает методы операций пути в верхнем регистре по алфавиту
+func (s *Spec) Methods(path string) []string {
+	methods := make([]string, 0, len(s.ops[path]))
+	for method := range s.ops[path] {
+		methods = append(methods, strings.ToUpper(method))
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// Paths возвращает пути спецификации по алфавиту
+func (s *Spec) Paths() []string {
+	paths := make([]string, 0, len(s.ops))
+	for path := range s.ops {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// ServeJSON отдаёт спецификацию в JSON
+func (s *Spec) ServeJSON(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(s.json)
+}
+
+// ServeDocs отдаёт страницу документации, которая читает /openapi.json
+func ServeDocs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(docsHTML)
+}
+
+// CheckRoutes сверяет спецификацию с маршрутами: каждый маршрут описан, каждый путь спецификации
+// есть в routes и обслуживается mux именно этим шаблоном. Возвращает список расхождений.
+func (s *Spec) CheckRoutes(mux *http.ServeMux, routes []string) []string {
+	var problems []string
+	known := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		known[route] = true
+		if _, ok := s.ops[route]; !ok {
+			problems = append(problems, fmt.Sprintf("route %s is not described in the spec", route))
+		}
+	}
+
+	for _, path := range s.Paths() {
+		if !known[path] {
+			problems = append(problems, fmt.Sprintf("spec path %s has no route", path))
+		}
+		if len(s.ops[path]) == 0 {
+			problems = append(problems, fmt.Sprintf("spec path %s has no operations", path))
+		}
+		for method := range s.ops[path] {
+			req, _ := http.NewRequest(strings.ToUpper(method), path, nil)
+			if _, pattern := mux.Handler(req); pattern != path {
+				problems = append(problems, fmt.Sprintf("%s %s is not registered in SetupRoutes", strings.ToUpper(method), path))
+			}
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+// CheckClient сверяет спецификацию с операциями Go-клиента вида "POST /team/add": у каждой операции
+// без x-skip-client есть метод клиента, и клиент не вызывает того, чего нет в спецификации
+func (s *Spec) CheckClient(endpoints []string) []string {
+	var problems []string
+	known := make(map[string]bool, len(endpoints))
+	for _, e := range endpoints {
+		known[e] = true
+		method, path, _ := strings.Cut(e, " ")
+		if op := s.Operation(method, path); op == nil || op.SkipClient {
+			problems = append(problems, fmt.Sprintf("client calls %s, which is not a client operation in the spec", e))
+		}
+	}
+
+	for _, path := range s.Paths() {
+		for method, op := range s.ops[path] {
+			e := strings.ToUpper(method) + " " + path
+			if !op.SkipClient && !known[e] {
+				problems = append(problems, fmt.Sprintf("%s has no method in the client package", e))
+			}
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
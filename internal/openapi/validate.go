@@ -0,0 +1,379 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"pr-reviewer-service/internal/models"
+	"pr-reviewer-service/internal/validation"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultMaxBodyBytes - предел тела запроса для операций без x-max-body-bytes
+const DefaultMaxBodyBytes = 1 << 20
+
+// Middleware ограничивает размер тела, проверяет параметры и JSON-тело запроса по спецификации и отвечает
+// 400 VALIDATION_ERROR со списком полей. Пути и методы, которых нет в спецификации, пропускаются без проверки.
+func (s *Spec) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		op := s.Operation(r.Method, r.URL.Path)
+		if op == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		limit := op.MaxBodyBytes
+		if limit <= 0 {
+			limit = DefaultMaxBodyBytes
+		}
+		if r.ContentLength > limit {
+			sendTooLarge(w, limit)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+
+		errs := validateParameters(op, r)
+
+		if schema := op.jsonBodySchema(r); schema != nil {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				sendTooLarge(w, limit)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			errs = append(errs, validateBody(schema, op.RequestBody.Required, body)...)
+		}
+
+		if len(errs) > 0 {
+			sendErrorResponse(w, "VALIDATION_ERROR", "request does not match the API schema", http.StatusBadRequest, errs)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func sendTooLarge(w http.ResponseWriter, limit int64) {
+	sendErrorResponse(w, "REQUEST_TOO_LARGE", fmt.Sprintf("request body exceeds %d bytes", limit), http.StatusRequestEntityTooLarge, nil)
+}
+
+// jsonBodySchema возвращает схему JSON-тела, если его нужно проверять. Обработчики читают JSON
+// независимо от Content-Type, поэтому проверяется любое тело, кроме явно другого описанного типа.
+func (op *Operation) jsonBodySchema(r *http.Request) *Schema {
+	if op.RequestBody == nil || op.SkipBodyValidation {
+		return nil
+	}
+	content, ok := op.RequestBody.Content["application/json"]
+	if !ok || content.Schema == nil {
+		return nil
+	}
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err == nil && mediaType != "application/json" {
+		if _, declared := op.RequestBody.Content[mediaType]; declared {
+			return nil
+		}
+	}
+	return content.Schema
+}
+
+func validateParameters(op *Operation, r *http.Request) []models.FieldError {
+	var errs []models.FieldError
+	query := r.URL.Query()
+	for _, p := range op.Parameters {
+		var value string
+		switch p.In {
+		case "query":
+			value = query.Get(p.Name)
+		case "header":
+			value = r.Header.Get(p.Name)
+		default:
+			continue
+		}
+
+		if value == "" {
+			if p.Required {
+				errs = append(errs, models.FieldError{In: p.In, Field: p.Name, Message: "is required"})
+			}
+			continue
+		}
+		if p.Schema == nil {
+			continue
+		}
+
+		typed, err := convertParameter(p.Schema.Type, value)
+		if err != nil {
+			errs = append(errs, models.FieldError{In: p.In, Field: p.Name, Message: err.Error()})
+			continue
+		}
+		v := &validator{in: p.In}
+		v.validate(p.Schema, typed, p.Name)
+		errs = append(errs, v.errs...)
+	}
+	return errs
+}
+
+// convertParameter приводит строковое значение параметра к типу схемы
+func convertParameter(typ, value string) (interface{}, error) {
+	switch typ {
+	case "integer":
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return nil, fmt.Errorf("must be an integer")
+		}
+		return json.Number(value), nil
+	case "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return nil, fmt.Errorf("must be a number")
+		}
+		return json.Number(value), nil
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("must be true or false")
+		}
+		return b, nil
+	}
+	return value, nil
+}
+
+func validateBody(schema *Schema, required bool, body []byte) []models.FieldError {
+	if len(bytes.TrimSpace(body)) == 0 {
+		if required {
+			return []models.FieldError{{In: "body", Field: "", Message: "request body is required"}}
+		}
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return []models.FieldError{{In: "body", Field: "", Message: "invalid JSON: " + err.Error()}}
+	}
+	if dec.More() {
+		return []models.FieldError{{In: "body", Field: "", Message: "invalid JSON: unexpected data after the document"}}
+	}
+
+	v := &validator{in: "body"}
+	v.validate(schema, value, "")
+	return v.errs
+}
+
+type validator struct {
+	in   string
+	errs []models.FieldError
+}
+
+func (v *validator) fail(field, format string, args ...interface{}) {
+	v.errs = append(v.errs, models.FieldError{In: v.in, Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func (v *validator) validate(s *Schema, value interface{}, path string) {
+	if s == nil {
+		return
+	}
+	for _, sub := range s.AllOf {
+		v.validate(sub, value, path)
+	}
+	if len(s.OneOf) > 0 {
+		matched := false
+		for _, sub := range s.OneOf {
+			probe := &validator{in: v.in}
+			probe.validate(sub, value, path)
+			if len(probe.errs) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.fail(path, "does not match any of the allowed schemas")
+		}
+	}
+
+	if value == nil {
+		if !s.Nullable && s.Type != "" {
+			v.fail(path, "must not be null")
+		}
+		return
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			v.fail(path, "must be an object")
+			return
+		}
+		v.validateObject(s, obj, path)
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			v.fail(path, "must be an array")
+			return
+		}
+		if s.MinItems != nil && len(arr) < *s.MinItems {
+			v.fail(path, "must contain at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(arr) > *s.MaxItems {
+			v.fail(path, "must contain at most %d items", *s.MaxItems)
+		}
+		for i, item := range arr {
+			v.validate(s.Items, item, fmt.Sprintf("%s[%d]", path, i))
+		}
+		v.validateUnique(s, arr, path)
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			v.fail(path, "must be a string")
+			return
+		}
+		v.validateString(s, str, path)
+	case "integer", "number":
+		num, ok := value.(json.Number)
+		if !ok {
+			v.fail(path, "must be a %s", s.Type)
+			return
+		}
+		if s.Type == "integer" {
+			if _, err := num.Int64(); err != nil {
+				v.fail(path, "must be an integer")
+				return
+			}
+		}
+		f, _ := num.Float64()
+		if s.Minimum != nil && f < *s.Minimum {
+			v.fail(path, "must be at least %v", *s.Minimum)
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			v.fail(path, "must be at most %v", *s.Maximum)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			v.fail(path, "must be a boolean")
+			return
+		}
+	}
+
+	if len(s.Enum) > 0 {
+		for _, allowed := range s.Enum {
+			if fmt.Sprint(allowed) == fmt.Sprint(value) {
+				return
+			}
+		}
+		names := make([]string, len(s.Enum))
+		for i, allowed := range s.Enum {
+			names[i] = fmt.Sprint(allowed)
+		}
+		v.fail(path, "must be one of %s", strings.Join(names, ", "))
+	}
+}
+
+func (v *validator) validateObject(s *Schema, obj map[string]interface{}, path string) {
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			v.fail(join(path, name), "is required")
+		}
+	}
+
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prop, ok := s.Properties[name]
+		if !ok {
+			if s.noAdditional {
+				v.fail(join(path, name), "unknown field")
+			}
+			continue
+		}
+		v.validate(prop, obj[name], join(path, name))
+	}
+}
+
+// validateUnique проверяет uniqueItems и x-unique-key; повтор указывается на втором вхождении
+func (v *validator) validateUnique(s *Schema, arr []interface{}, path string) {
+	if !s.UniqueItems && s.UniqueKey == "" {
+		return
+	}
+	seen := make(map[string]int, len(arr))
+	for i, item := range arr {
+		field := fmt.Sprintf("%s[%d]", path, i)
+		key := item
+		if s.UniqueKey != "" {
+			obj, ok := item.(map[string]interface{})
+			if !ok || obj[s.UniqueKey] == nil {
+				continue
+			}
+			key = obj[s.UniqueKey]
+			field = join(field, s.UniqueKey)
+		}
+		encoded, _ := json.Marshal(key)
+		if first, ok := seen[string(encoded)]; ok {
+			v.fail(field, "duplicates %s[%d]", path, first)
+			continue
+		}
+		seen[string(encoded)] = i
+	}
+}
+
+func (v *validator) validateString(s *Schema, str, path string) {
+	before := len(v.errs)
+	length := utf8.RuneCountInString(str)
+	if s.MinLength != nil && length < *s.MinLength {
+		if *s.MinLength == 1 {
+			v.fail(path, "must not be empty")
+		} else {
+			v.fail(path, "must be at least %d characters", *s.MinLength)
+		}
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		v.fail(path, "must be at most %d characters", *s.MaxLength)
+	}
+	if s.pattern != nil && !s.pattern.MatchString(str) {
+		v.fail(path, "must match %s", s.Pattern)
+	}
+	switch s.Format {
+	// длину форматы identifier и name тоже проверяют; второе сообщение о ней не нужно
+	case "identifier":
+		if msg := validation.ID(str); msg != "" && len(v.errs) == before {
+			v.fail(path, "%s", msg)
+		}
+	case "name":
+		if msg := validation.Name(str); msg != "" && len(v.errs) == before {
+			v.fail(path, "%s", msg)
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, str); err != nil {
+			v.fail(path, "must be an RFC3339 timestamp")
+		}
+	case "date":
+		if _, err := time.Parse("2006-01-02", str); err != nil {
+			v.fail(path, "must be a date in YYYY-MM-DD format")
+		}
+	}
+}
+
+func sendErrorResponse(w http.ResponseWriter, code, message string, statusCode int, details []models.FieldError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	errorResp := models.ErrorResponse{}
+	errorResp.Error.Code = code
+	errorResp.Error.Message = message
+	errorResp.Error.Details = details
+
+	json.NewEncoder(w).Encode(errorResp)
+}
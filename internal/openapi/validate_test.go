@@ -0,0 +1,247 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pr-reviewer-service/internal/models"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// passed отвечает 204, если запрос дошёл до обработчика
+var passed = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+})
+
+// check прогоняет запрос через Middleware и возвращает статус и список ошибок полей
+func check(t *testing.T, spec *Spec, method, target, contentType, body string) (int, []models.FieldError) {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	rec := httptest.NewRecorder()
+	spec.Middleware(passed).ServeHTTP(rec, req)
+	if rec.Code == http.StatusNoContent {
+		return rec.Code, nil
+	}
+
+	var resp models.ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("body %q: %v", rec.Body, err)
+	}
+	return rec.Code, resp.Error.Details
+}
+
+func body(field, message string) models.FieldError {
+	return models.FieldError{In: "body", Field: field, Message: message}
+}
+
+func TestMiddlewareBuiltinSpec(t *testing.T) {
+	spec := MustLoad()
+	long := strings.Repeat("a", 256)
+
+	tests := []struct {
+		name, method, target, body string
+		want                       []models.FieldError
+	}{
+		{"valid team", "POST", "/team/add",
+			`{"team_name":"backend","members":[{"user_id":"u1","username":"Alice","is_active":true}]}`, nil},
+		{"missing required fields", "POST", "/team/add", `{}`,
+			[]models.FieldError{body("team_name", "is required"), body("members", "is required")}},
+		{"identifier and name formats", "POST", "/team/add",
+			`{"team_name":" backend","members":[{"user_id":"u 1","username":"Ali\u0007ce","is_active":true},{"user_id":"","username":"","is_active":"yes"}]}`,
+			[]models.FieldError{
+				body("members[0].user_id", "may contain only latin letters, digits and . _ - : @ /"),
+				body("members[0].username", "must not contain control characters"),
+				body("members[1].is_active", "must be a boolean"),
+				body("members[1].user_id", "must not be empty"),
+				body("members[1].username", "must not be empty"),
+				body("team_name", "must not start or end with whitespace"),
+			}},
+		{"max length reported once", "POST", "/pullRequest/create",
+			`{"pull_request_id":"` + long + `","pull_request_name":"` + long + `","author_id":"u1"}`,
+			[]models.FieldError{
+				body("pull_request_id", "must be at most 255 characters"),
+				body("pull_request_name", "must be at most 255 characters"),
+			}},
+		{"duplicate unique key", "POST", "/team/add",
+			`{"team_name":"backend","members":[{"user_id":"u1","username":"A","is_active":true},{"user_id":"u2","username":"B","is_active":true},{"user_id":"u1","username":"C","is_active":false}]}`,
+			[]models.FieldError{body("members[2].user_id", "duplicates members[0]")}},
+		{"additional properties", "POST", "/pullRequest/create",
+			`{"pull_request_id":"pr-1","pull_request_name":"Add search","author_id":"u1","reviewers":["u2"],"draft":true}`,
+			[]models.FieldError{body("draft", "unknown field"), body("reviewers", "unknown field")}},
+		{"not an object", "POST", "/pullRequest/create", `[]`, []models.FieldError{body("", "must be an object")}},
+		{"empty body", "POST", "/pullRequest/create", ``, []models.FieldError{body("", "request body is required")}},
+		{"invalid JSON", "POST", "/pullRequest/create", `{"pull_request_id":`,
+			[]models.FieldError{body("", "invalid JSON: unexpected EOF")}},
+		{"trailing data", "POST", "/pullRequest/create", `{} {}`,
+			[]models.FieldError{body("", "invalid JSON: unexpected data after the document")}},
+		{"query parameters", "GET", "/users/list?is_active=maybe&limit=0", "",
+			[]models.FieldError{
+				{In: "query", Field: "is_active", Message: "must be true or false"},
+				{In: "query", Field: "limit", Message: "must be at least 1"},
+			}},
+		{"required query parameter", "GET", "/team/get", "",
+			[]models.FieldError{{In: "query", Field: "team_name", Message: "is required"}}},
+		{"unknown path is not checked", "POST", "/no/such/path", `{"x":`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, errs := check(t, spec, tt.method, tt.target, "application/json", tt.body)
+			if tt.want == nil {
+				if code != http.StatusNoContent {
+					t.Fatalf("status = %d, errors %+v", code, errs)
+				}
+				return
+			}
+			if code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", code)
+			}
+			if !reflect.DeepEqual(errs, tt.want) {
+				t.Errorf("errors:\n got %+v\nwant %+v", errs, tt.want)
+			}
+		})
+	}
+}
+
+func TestMiddlewareBodyLimit(t *testing.T) {
+	spec, err := Load([]byte(`
+paths:
+  /small:
+    post:
+      x-max-body-bytes: 16
+      requestBody:
+        content:
+          application/json:
+            schema: {type: object}
+  /default:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema: {type: object}
+  /raw:
+    post:
+      x-skip-body-validation: true
+      x-max-body-bytes: 16
+      requestBody:
+        content:
+          application/json:
+            schema: {type: object}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	padded := func(n int) string { return `{"a":"` + strings.Repeat("x", n-len(`{"a":""}`)) + `"}` }
+	tests := []struct {
+		path string
+		size int
+		want int
+	}{
+		{"/small", 16, http.StatusNoContent},
+		{"/small", 17, http.StatusRequestEntityTooLarge},
+		{"/default", DefaultMaxBodyBytes, http.StatusNoContent},
+		{"/default", DefaultMaxBodyBytes + 1, http.StatusRequestEntityTooLarge},
+		// Тело без проверки по схеме читает обработчик, предел тогда проверяет Content-Length
+		{"/raw", 17, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", tt.path, strings.NewReader(padded(tt.size)))
+		rec := httptest.NewRecorder()
+		spec.Middleware(passed).ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s with %d bytes: status %d, want %d", tt.path, tt.size, rec.Code, tt.want)
+		}
+	}
+
+	// Без Content-Length предел срабатывает при чтении тела
+	req := httptest.NewRequest("POST", "/small", strings.NewReader(padded(17)))
+	req.ContentLength = -1
+	rec := httptest.NewRecorder()
+	spec.Middleware(passed).ServeHTTP(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge || !strings.Contains(rec.Body.String(), "REQUEST_TOO_LARGE") {
+		t.Errorf("chunked body: %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestValidateSchemaRules(t *testing.T) {
+	spec, err := Load([]byte(`
+paths:
+  /check:
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                code: {type: string, minLength: 3, maxLength: 5, pattern: '^[a-z]+$'}
+                note: {type: string, minLength: 1, nullable: true}
+                tags: {type: array, minItems: 1, maxItems: 2, uniqueItems: true, items: {type: string}}
+                count: {type: integer, minimum: 1, maximum: 10}
+                mode: {type: string, enum: [atomic, best_effort]}
+                at: {type: string, format: date-time}
+                day: {type: string, format: date}
+                open: {type: object, properties: {a: {type: string}}}
+  /text:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema: {type: object, required: [a]}
+          text/csv:
+            schema: {type: string}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, body string
+		want       []models.FieldError
+	}{
+		{"valid", `{"code":"abc","note":null,"tags":["x","y"],"count":10,"mode":"atomic","at":"2025-03-01T10:00:00+03:00","day":"2025-03-01","open":{"a":"x","b":1}}`, nil},
+		{"too short", `{"code":"ab","note":""}`, []models.FieldError{
+			body("code", "must be at least 3 characters"),
+			body("note", "must not be empty"),
+		}},
+		{"too long and pattern", `{"code":"ABCDEF"}`, []models.FieldError{
+			body("code", "must be at most 5 characters"),
+			body("code", "must match ^[a-z]+$"),
+		}},
+		{"items", `{"tags":["x","y","x"]}`, []models.FieldError{
+			body("tags", "must contain at most 2 items"),
+			body("tags[2]", "duplicates tags[0]"),
+		}},
+		{"empty array", `{"tags":[]}`, []models.FieldError{body("tags", "must contain at least 1 items")}},
+		{"numbers", `{"count":1.5}`, []models.FieldError{body("count", "must be an integer")}},
+		{"range", `{"count":11}`, []models.FieldError{body("count", "must be at most 10")}},
+		{"enum", `{"mode":"all"}`, []models.FieldError{body("mode", "must be one of atomic, best_effort")}},
+		{"null", `{"code":null}`, []models.FieldError{body("code", "must not be null")}},
+		{"formats", `{"at":"2025-03-01 10:00","day":"01.03.2025"}`, []models.FieldError{
+			body("at", "must be an RFC3339 timestamp"),
+			body("day", "must be a date in YYYY-MM-DD format"),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errs := check(t, spec, "POST", "/check", "application/json", tt.body)
+			if !reflect.DeepEqual(errs, tt.want) {
+				t.Errorf("errors:\n got %+v\nwant %+v", errs, tt.want)
+			}
+		})
+	}
+
+	// Тело другого описанного типа не проверяется схемой JSON, неописанного - проверяется
+	if code, errs := check(t, spec, "POST", "/text", "text/csv", "a,b\n"); code != http.StatusNoContent {
+		t.Errorf("text/csv: %d %+v", code, errs)
+	}
+	if _, errs := check(t, spec, "POST", "/text", "text/plain", `{}`); !reflect.DeepEqual(errs, []models.FieldError{body("a", "is required")}) {
+		t.Errorf("text/plain: %+v", errs)
+	}
+}
@@ -0,0 +1,40 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 500
+)
+
+// Encode упаковывает позицию keyset-пагинации в непрозрачную строку
+func Encode(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func Decode(cursor string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return fmt.Errorf("INVALID_CURSOR")
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("INVALID_CURSOR")
+	}
+	return nil
+}
+
+// ClampLimit приводит запрошенный размер страницы к допустимому диапазону
+func ClampLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultLimit
+	}
+	if limit > MaxLimit {
+		return MaxLimit
+	}
+	return limit
+}
@@ -0,0 +1,26 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Every вызывает fn с интервалом interval, пока не отменён ctx.
+// Ошибки логируются и не останавливают цикл; вызовы не пересекаются.
+func Every(ctx context.Context, name string, interval time.Duration, fn func(context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Printf("%s: scheduler started, interval %s", name, interval)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := fn(ctx); err != nil {
+				log.Printf("%s: %v", name, err)
+			}
+		}
+	}
+}
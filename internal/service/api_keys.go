@@ -0,0 +1,65 @@
+package service
+
+import (
+	"fmt"
+	"pr-reviewer-service/internal/auth"
+	"pr-reviewer-service/internal/models"
+)
+
+// CreateAPIKey выпускает ключ и возвращает его открытое значение; в БД хранится только хеш
+func (s *PRService) CreateAPIKey(name, role, userID, teamName string) (*models.APIKey, string, error) {
+	if name == "" {
+		return nil, "", fmt.Errorf("INVALID_NAME")
+	}
+	if _, err := auth.ParseRole(role); err != nil {
+		return nil, "", err
+	}
+
+	if userID != "" {
+		userTeam, err := s.storage.GetUserTeam(userID)
+		if err != nil {
+			return nil, "", err
+		}
+		if teamName == "" {
+			teamName = userTeam
+		}
+	}
+	if role == string(auth.RoleTeamLead) && teamName == "" {
+		return nil, "", fmt.Errorf("TEAM_REQUIRED")
+	}
+
+	plain, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := &models.APIKey{
+		Name:     name,
+		Prefix:   plain[:len(auth.APIKeyPrefix)+8],
+		Role:     role,
+		UserID:   userID,
+		TeamName: teamName,
+	}
+	if err := s.storage.CreateAPIKey(key, hash); err != nil {
+		return nil, "", err
+	}
+
+	return key, plain, nil
+}
+
+func (s *PRService) ListAPIKeys() ([]models.APIKey, error) {
+	return s.storage.ListAPIKeys()
+}
+
+func (s *PRService) RevokeAPIKey(name string) (*models.APIKey, error) {
+	return s.storage.RevokeAPIKey(name)
+}
+
+// GetAPIKeyByHash реализует auth.KeyStore
+func (s *PRService) GetAPIKeyByHash(hash string) (*models.APIKey, error) {
+	return s.storage.GetAPIKeyByHash(hash)
+}
+
+func (s *PRService) GetUserTeam(userID string) (string, error) {
+	return s.storage.GetUserTeam(userID)
+}
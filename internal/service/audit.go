@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"pr-reviewer-service/internal/audit"
+	"pr-reviewer-service/internal/models"
+)
+
+const (
+	ActionTeamCreate    = "team.create"
+	ActionUserSetActive = "user.set_active"
+	ActionPRCreate      = "pr.create"
+	ActionPRMerge       = "pr.merge"
+	ActionPRReassign    = "pr.reassign"
+)
+
+// auditEntry готовит запись журнала аудита. Хранилище пишет её в транзакции изменения,
+// поэтому изменение, для которого запись не удалась, не сохраняется.
+func auditEntry(ctx context.Context, action, targetType, targetID string, before, after interface{}) *models.AuditEntry {
+	return &models.AuditEntry{
+		Actor:      audit.Actor(ctx),
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		RequestID:  audit.RequestID(ctx),
+		Before:     snapshot(before),
+		After:      snapshot(after),
+	}
+}
+
+func snapshot(v interface{}) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil || string(data) == "null" {
+		return nil
+	}
+	return data
+}
+
+func (s *PRService) ListAudit(filter models.AuditFilter) ([]models.AuditEntry, error) {
+	return s.storage.ListAudit(filter)
+}
+
+func (s *PRService) StreamAudit(filter models.AuditFilter, fn func(*models.AuditEntry) error) error {
+	return s.storage.StreamAudit(filter, fn)
+}
@@ -0,0 +1,327 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"pr-reviewer-service/internal/audit"
+	"pr-reviewer-service/internal/models"
+	"pr-reviewer-service/internal/validation"
+	"time"
+)
+
+// MaxBatchOperations - ограничение размера пакета /pullRequest/batch
+const MaxBatchOperations = 200
+
+// batchStore - запросы к хранилищу, нужные пакету операций с PR
+type batchStore interface {
+	GetPR(prID string) (*models.PullRequest, error)
+	PRExists(prID string) (bool, error)
+	GetUserTeam(userID string) (string, error)
+	GetActiveTeamMembers(teamName string, excludeUserID string) ([]string, error)
+	ApplyPRBatch(mutations []models.PRBatchMutation, actor string) (int, error)
+}
+
+// prBatch - состояние PR, затронутых пакетом, с учётом уже выполненных в нём операций,
+// и число назначений каждому ревьюверу внутри пакета для равномерного распределения нагрузки
+type prBatch struct {
+	store batchStore
+	prs   map[string]*models.PullRequest
+	load  map[string]int
+}
+
+// RunPRBatch выполняет пакет операций create/merge/reassign.
+// В режиме atomic пакет сначала проверяется целиком и при любой ошибке ничего не записывается,
+// иначе записывается в одной транзакции; если PR изменили или создали параллельно между проверкой
+// и записью, пакет отклоняется так же, как при ошибке проверки. В режиме best_effort каждая операция
+// записывается отдельно, ошибки одних не мешают другим, а замена ревьювера, как и одиночная,
+// при PR_MODIFIED проверяется заново по свежему состоянию. Каждая операция видит результаты предыдущих.
+func (s *PRService) RunPRBatch(ctx context.Context, req *models.PRBatchRequest) (*models.PRBatchReport, error) {
+	return runPRBatch(ctx, s.storage, req)
+}
+
+func runPRBatch(ctx context.Context, store batchStore, req *models.PRBatchRequest) (*models.PRBatchReport, error) {
+	if req.Mode == "" {
+		req.Mode = models.BatchAtomic
+	}
+	if req.Mode != models.BatchAtomic && req.Mode != models.BatchBestEffort {
+		return nil, fmt.Errorf("INVALID_BATCH: mode must be atomic or best_effort")
+	}
+	if len(req.Operations) == 0 || len(req.Operations) > MaxBatchOperations {
+		return nil, fmt.Errorf("INVALID_BATCH: operations must contain 1 to %d items", MaxBatchOperations)
+	}
+	for i, op := range req.Operations {
+		switch op.Op {
+		case models.BatchOpCreate, models.BatchOpMerge, models.BatchOpReassign:
+		default:
+			return nil, fmt.Errorf("INVALID_BATCH: operations[%d]: op must be create, merge or reassign", i)
+		}
+	}
+
+	batch := &prBatch{store: store, prs: make(map[string]*models.PullRequest), load: make(map[string]int)}
+	report := &models.PRBatchReport{Mode: req.Mode, Results: make([]models.PRBatchResult, len(req.Operations))}
+	actor := audit.Actor(ctx)
+
+	var planned []models.PRBatchMutation
+	// plannedIndex - номер операции пакета для каждого изменения из planned
+	var plannedIndex []int
+	for i, op := range req.Operations {
+		result := &report.Results[i]
+		*result = models.PRBatchResult{Index: i, Op: op.Op, PullRequestID: op.PullRequestID}
+
+		var prev *models.PullRequest
+		var mutation *models.PRBatchMutation
+		var err error
+		if req.Mode == models.BatchBestEffort {
+			prev, mutation, err = batch.apply(ctx, op, actor)
+		} else if prev, mutation, err = batch.plan(op); err == nil && mutation != nil {
+			mutation.Audit = batchAuditEntry(ctx, mutation, prev)
+		}
+		if err != nil {
+			result.Status = models.BatchItemError
+			result.Err = err
+			report.Failed++
+			continue
+		}
+
+		if mutation == nil {
+			// Слияние уже слитого PR - как и одиночный эндпоинт, ничего не меняет
+			result.PR = prev
+		} else {
+			batch.commit(mutation)
+			result.PR = mutation.PR
+			result.ReplacedBy = mutation.NewReviewer
+			if req.Mode == models.BatchAtomic {
+				planned = append(planned, *mutation)
+				plannedIndex = append(plannedIndex, i)
+			}
+		}
+		result.Status = models.BatchItemOK
+		report.Applied++
+	}
+
+	if req.Mode == models.BatchAtomic {
+		if report.Failed > 0 {
+			rejectBatch(report)
+			return report, nil
+		}
+		if len(planned) > 0 {
+			failed, err := store.ApplyPRBatch(planned, actor)
+			if err != nil && failed >= 0 && (err.Error() == "PR_MODIFIED" || err.Error() == "PR_EXISTS") {
+				// PR изменили или создали между проверкой и записью: пакет отклоняется так же, как при ошибке проверки
+				result := &report.Results[plannedIndex[failed]]
+				result.Status = models.BatchItemError
+				result.Err = err
+				report.Failed++
+				rejectBatch(report)
+				return report, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// apply проверяет и сразу записывает операцию пакета best_effort. Замену ревьювера, PR которой изменили
+// между проверкой и записью, как и одиночную, проверяет заново по свежему состоянию PR.
+func (b *prBatch) apply(ctx context.Context, op models.PRBatchOperation, actor string) (*models.PullRequest, *models.PRBatchMutation, error) {
+	for attempt := 1; ; attempt++ {
+		prev, mutation, err := b.plan(op)
+		if err == nil && mutation != nil {
+			mutation.Audit = batchAuditEntry(ctx, mutation, prev)
+			_, err = b.store.ApplyPRBatch([]models.PRBatchMutation{*mutation}, actor)
+		}
+		if err != nil && err.Error() == "PR_MODIFIED" && op.Op == models.BatchOpReassign && attempt < reassignAttempts {
+			// Предыдущие операции best_effort уже записаны, поэтому свежее состояние PR берётся из хранилища
+			delete(b.prs, op.PullRequestID)
+			continue
+		}
+		return prev, mutation, err
+	}
+}
+
+// rejectBatch помечает успешно проверенные операции отклонённого пакета atomic как пропущенные
+func rejectBatch(report *models.PRBatchReport) {
+	for i := range report.Results {
+		if report.Results[i].Status == models.BatchItemOK {
+			report.Results[i].Status = models.BatchItemSkipped
+		}
+	}
+	report.Applied = 0
+}
+
+// batchAuditEntry готовит запись аудита изменения пакета такую же, как у одиночного эндпоинта
+func batchAuditEntry(ctx context.Context, m *models.PRBatchMutation, before *models.PullRequest) *models.AuditEntry {
+	action := ActionPRCreate
+	switch m.Op {
+	case models.BatchOpMerge:
+		action = ActionPRMerge
+	case models.BatchOpReassign:
+		action = ActionPRReassign
+	}
+	return auditEntry(ctx, action, "pull_request", m.PR.PullRequestID, before, m.PR)
+}
+
+// getPR возвращает копию PR с учётом изменений пакета
+func (b *prBatch) getPR(prID string) (*models.PullRequest, error) {
+	pr, ok := b.prs[prID]
+	if !ok {
+		var err error
+		if pr, err = b.store.GetPR(prID); err != nil {
+			return nil, err
+		}
+		b.prs[prID] = pr
+	}
+	clone := *pr
+	clone.AssignedReviewers = append([]string{}, pr.AssignedReviewers...)
+	return &clone, nil
+}
+
+// plan проверяет операцию так же, как одиночный эндпоинт, и возвращает PR до изменения и само изменение.
+// Состояние пакета не меняется до commit.
+func (b *prBatch) plan(op models.PRBatchOperation) (*models.PullRequest, *models.PRBatchMutation, error) {
+	now := time.Now()
+
+	if err := validateBatchOperation(op); err != nil {
+		return nil, nil, err
+	}
+
+	switch op.Op {
+	case models.BatchOpCreate:
+		if _, ok := b.prs[op.PullRequestID]; ok {
+			return nil, nil, fmt.Errorf("PR_EXISTS")
+		}
+		exists, err := b.store.PRExists(op.PullRequestID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if exists {
+			return nil, nil, fmt.Errorf("PR_EXISTS")
+		}
+
+		authorTeam, err := b.store.GetUserTeam(op.AuthorID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("NOT_FOUND")
+		}
+		available, err := b.store.GetActiveTeamMembers(authorTeam, op.AuthorID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		pr := &models.PullRequest{
+			PullRequestID:     op.PullRequestID,
+			PullRequestName:   op.PullRequestName,
+			AuthorID:          op.AuthorID,
+			Status:            "OPEN",
+			AssignedReviewers: pickLeastLoaded(available, b.load, 2),
+			CreatedAt:         &now,
+		}
+		return nil, &models.PRBatchMutation{Op: op.Op, PR: pr, At: now}, nil
+
+	case models.BatchOpMerge:
+		pr, err := b.getPR(op.PullRequestID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if pr.Status != "OPEN" {
+			return pr, nil, nil
+		}
+		merged := *pr
+		merged.Status = "MERGED"
+		merged.MergedAt = &now
+		merged.Overdue = false
+		return pr, &models.PRBatchMutation{Op: op.Op, PR: &merged, At: now}, nil
+
+	case models.BatchOpReassign:
+		pr, err := b.getPR(op.PullRequestID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if pr.Status == "MERGED" {
+			return nil, nil, fmt.Errorf("PR_MERGED")
+		}
+		found := false
+		for _, reviewer := range pr.AssignedReviewers {
+			if reviewer == op.OldUserID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, nil, fmt.Errorf("NOT_ASSIGNED")
+		}
+
+		reviewerTeam, err := b.store.GetUserTeam(op.OldUserID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("NOT_FOUND")
+		}
+		available, err := b.store.GetActiveTeamMembers(reviewerTeam, op.OldUserID)
+		if err != nil {
+			return nil, nil, err
+		}
+		candidates := replacementCandidates(pr, available)
+		if len(candidates) == 0 {
+			return nil, nil, fmt.Errorf("NO_CANDIDATE")
+		}
+
+		newReviewer := pickLeastLoaded(candidates, b.load, 1)[0]
+		updated := *pr
+		updated.AssignedReviewers = replaceReviewer(pr.AssignedReviewers, op.OldUserID, newReviewer)
+		return pr, &models.PRBatchMutation{
+			Op:                op.Op,
+			PR:                &updated,
+			OldReviewer:       op.OldUserID,
+			NewReviewer:       newReviewer,
+			PreviousReviewers: pr.AssignedReviewers,
+			At:                now,
+		}, nil
+	}
+
+	return nil, nil, fmt.Errorf("INVALID_BATCH: unknown op %q", op.Op)
+}
+
+// batchField - поле операции и правило из пакета validation
+type batchField struct {
+	name, value string
+	check       func(string) string
+}
+
+// validateBatchOperation проверяет поля, обязательные для вида операции. Спецификация описывает
+// операции одной схемой, поэтому, например, create без pull_request_name проходит её проверку.
+func validateBatchOperation(op models.PRBatchOperation) error {
+	fields := []batchField{{"pull_request_id", op.PullRequestID, validation.ID}}
+	switch op.Op {
+	case models.BatchOpCreate:
+		fields = append(fields,
+			batchField{"pull_request_name", op.PullRequestName, validation.Name},
+			batchField{"author_id", op.AuthorID, validation.ID})
+	case models.BatchOpReassign:
+		fields = append(fields, batchField{"old_user_id", op.OldUserID, validation.ID})
+	}
+	for _, f := range fields {
+		if msg := f.check(f.value); msg != "" {
+			return fmt.Errorf("VALIDATION_ERROR: %s %s", f.name, msg)
+		}
+	}
+	return nil
+}
+
+// commit учитывает изменение в состоянии пакета
+func (b *prBatch) commit(m *models.PRBatchMutation) {
+	b.prs[m.PR.PullRequestID] = m.PR
+	switch m.Op {
+	case models.BatchOpCreate:
+		for _, reviewer := range m.PR.AssignedReviewers {
+			b.load[reviewer]++
+		}
+	case models.BatchOpReassign:
+		b.load[m.NewReviewer]++
+		// load считает только назначения внутри пакета, а снимают чаще ревьюверов, назначенных раньше:
+		// отрицательная нагрузка сделала бы их первыми кандидатами в следующих операциях
+		if b.load[m.OldReviewer] > 0 {
+			b.load[m.OldReviewer]--
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"pr-reviewer-service/internal/models"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestValidateBatchOperation(t *testing.T) {
+	tests := []struct {
+		name string
+		op   models.PRBatchOperation
+		err  string
+	}{
+		{"create", models.PRBatchOperation{Op: models.BatchOpCreate, PullRequestID: "pr-1", PullRequestName: "Add search", AuthorID: "u1"}, ""},
+		{"create without name", models.PRBatchOperation{Op: models.BatchOpCreate, PullRequestID: "pr-1", AuthorID: "u1"}, "pull_request_name must not be empty"},
+		{"create with padded name", models.PRBatchOperation{Op: models.BatchOpCreate, PullRequestID: "pr-1", PullRequestName: " x", AuthorID: "u1"}, "pull_request_name must not start or end"},
+		{"create without author", models.PRBatchOperation{Op: models.BatchOpCreate, PullRequestID: "pr-1", PullRequestName: "x"}, "author_id must not be empty"},
+		{"create with bad author", models.PRBatchOperation{Op: models.BatchOpCreate, PullRequestID: "pr-1", PullRequestName: "x", AuthorID: "u 1"}, "author_id may contain only"},
+		{"merge", models.PRBatchOperation{Op: models.BatchOpMerge, PullRequestID: "pr-1"}, ""},
+		{"merge without id", models.PRBatchOperation{Op: models.BatchOpMerge}, "pull_request_id must not be empty"},
+		{"reassign", models.PRBatchOperation{Op: models.BatchOpReassign, PullRequestID: "pr-1", OldUserID: "u2"}, ""},
+		{"reassign without old user", models.PRBatchOperation{Op: models.BatchOpReassign, PullRequestID: "pr-1"}, "old_user_id must not be empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateBatchOperation(tt.op)
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("validateBatchOperation() = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.HasPrefix(err.Error(), "VALIDATION_ERROR: "+tt.err) {
+				t.Fatalf("validateBatchOperation() = %v, want VALIDATION_ERROR: %s...", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestBatchCommitLoadNeverNegative(t *testing.T) {
+	b := &prBatch{prs: make(map[string]*models.PullRequest), load: make(map[string]int)}
+
+	// u1 назначен до пакета, снятие его не должно делать u1 предпочтительным кандидатом
+	b.commit(&models.PRBatchMutation{
+		Op:          models.BatchOpReassign,
+		PR:          &models.PullRequest{PullRequestID: "pr-1", AssignedReviewers: []string{"u2"}},
+		OldReviewer: "u1",
+		NewReviewer: "u2",
+	})
+	if b.load["u1"] != 0 || b.load["u2"] != 1 {
+		t.Fatalf("load = %v, want u1:0 u2:1", b.load)
+	}
+
+	// Назначенного в пакете ревьювера снимают с учётом его назначений
+	b.commit(&models.PRBatchMutation{
+		Op:          models.BatchOpReassign,
+		PR:          &models.PullRequest{PullRequestID: "pr-1", AssignedReviewers: []string{"u3"}},
+		OldReviewer: "u2",
+		NewReviewer: "u3",
+	})
+	if b.load["u2"] != 0 || b.load["u3"] != 1 {
+		t.Fatalf("load = %v, want u2:0 u3:1", b.load)
+	}
+
+	picked := pickLeastLoaded([]string{"u1", "u3"}, b.load, 1)
+	if picked[0] != "u1" {
+		t.Errorf("pickLeastLoaded() = %v, want u1", picked)
+	}
+}
+
+// stubBatchStore - хранилище пакета в памяти. ApplyPRBatch проверяет изменения так же, как транзакция
+// в PostgreSQL, и при ошибке ничего не записывает; concurrent вызывается перед каждой записью
+// и изображает параллельный запрос.
+type stubBatchStore struct {
+	prs        map[string]*models.PullRequest
+	teams      map[string]string
+	concurrent func(s *stubBatchStore)
+	// fail - ошибка, которой завершается каждая запись
+	fail error
+	// applied - записанные транзакции, calls - все вызовы ApplyPRBatch
+	applied [][]models.PRBatchMutation
+	calls   int
+}
+
+// newStubBatchStore: команда backend из u1-u4, открытый PR pr-1 автора u1 с ревьюверами u2 и u3
+func newStubBatchStore() *stubBatchStore {
+	return &stubBatchStore{
+		prs: map[string]*models.PullRequest{
+			"pr-1": {PullRequestID: "pr-1", PullRequestName: "Add search", AuthorID: "u1", Status: "OPEN", AssignedReviewers: []string{"u2", "u3"}},
+		},
+		teams: map[string]string{"u1": "backend", "u2": "backend", "u3": "backend", "u4": "backend"},
+	}
+}
+
+func (s *stubBatchStore) GetPR(prID string) (*models.PullRequest, error) {
+	pr, ok := s.prs[prID]
+	if !ok {
+		return nil, fmt.Errorf("NOT_FOUND")
+	}
+	clone := *pr
+	clone.AssignedReviewers = append([]string{}, pr.AssignedReviewers...)
+	return &clone, nil
+}
+
+func (s *stubBatchStore) PRExists(prID string) (bool, error) {
+	_, ok := s.prs[prID]
+	return ok, nil
+}
+
+func (s *stubBatchStore) GetUserTeam(userID string) (string, error) {
+	team, ok := s.teams[userID]
+	if !ok {
+		return "", fmt.Errorf("NOT_FOUND")
+	}
+	return team, nil
+}
+
+func (s *stubBatchStore) GetActiveTeamMembers(teamName string, excludeUserID string) ([]string, error) {
+	var members []string
+	for user, team := range s.teams {
+		if team == teamName && user != excludeUserID {
+			members = append(members, user)
+		}
+	}
+	sort.Strings(members)
+	return members, nil
+}
+
+func (s *stubBatchStore) ApplyPRBatch(mutations []models.PRBatchMutation, actor string) (int, error) {
+	s.calls++
+	if s.concurrent != nil {
+		s.concurrent(s)
+	}
+	if s.fail != nil {
+		return -1, s.fail
+	}
+
+	written := make(map[string]bool)
+	for i, m := range mutations {
+		id := m.PR.PullRequestID
+		if m.Audit == nil {
+			return i, fmt.Errorf("no audit entry")
+		}
+		switch m.Op {
+		case models.BatchOpCreate:
+			if _, ok := s.prs[id]; ok || written[id] {
+				return i, fmt.Errorf("PR_EXISTS")
+			}
+		case models.BatchOpReassign:
+			if pr := s.prs[id]; !written[id] && (pr.Status != "OPEN" || !reflect.DeepEqual(pr.AssignedReviewers, m.PreviousReviewers)) {
+				return i, fmt.Errorf("PR_MODIFIED")
+			}
+		}
+		written[id] = true
+	}
+	for _, m := range mutations {
+		s.prs[m.PR.PullRequestID] = m.PR
+	}
+	s.applied = append(s.applied, mutations)
+	return -1, nil
+}
+
+// statuses возвращает статусы операций отчёта по порядку
+func statuses(report *models.PRBatchReport) []string {
+	var got []string
+	for _, r := range report.Results {
+		status := r.Status
+		if r.Err != nil {
+			status += " " + r.Err.Error()
+		}
+		got = append(got, status)
+	}
+	return got
+}
+
+func TestRunPRBatchAtomic(t *testing.T) {
+	create := models.PRBatchOperation{Op: models.BatchOpCreate, PullRequestID: "pr-2", PullRequestName: "Fix login", AuthorID: "u1"}
+	merge := models.PRBatchOperation{Op: models.BatchOpMerge, PullRequestID: "pr-1"}
+	tests := []struct {
+		name       string
+		ops        []models.PRBatchOperation
+		concurrent func(s *stubBatchStore)
+		want       []string
+		calls      int
+	}{
+		{"check fails", []models.PRBatchOperation{
+			create,
+			{Op: models.BatchOpReassign, PullRequestID: "pr-1", OldUserID: "u9"},
+			merge,
+		}, nil, []string{"skipped", "error NOT_ASSIGNED", "skipped"}, 0},
+		// Параллельный запрос создал PR после проверки: пакет отклоняется, а не падает с ошибкой базы
+		{"created concurrently", []models.PRBatchOperation{merge, create}, func(s *stubBatchStore) {
+			s.prs["pr-2"] = &models.PullRequest{PullRequestID: "pr-2", Status: "OPEN"}
+		}, []string{"skipped", "error PR_EXISTS"}, 1},
+		{"modified concurrently", []models.PRBatchOperation{
+			create,
+			{Op: models.BatchOpReassign, PullRequestID: "pr-1", OldUserID: "u2"},
+		}, func(s *stubBatchStore) {
+			s.prs["pr-1"].AssignedReviewers = []string{"u3", "u4"}
+		}, []string{"skipped", "error PR_MODIFIED"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newStubBatchStore()
+			store.concurrent = tt.concurrent
+			report, err := runPRBatch(context.Background(), store, &models.PRBatchRequest{Operations: tt.ops})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := statuses(report); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("results = %v, want %v", got, tt.want)
+			}
+			if report.Mode != models.BatchAtomic || report.Applied != 0 || report.Failed != 1 {
+				t.Errorf("report: mode %s, applied %d, failed %d", report.Mode, report.Applied, report.Failed)
+			}
+			if store.calls != tt.calls || len(store.applied) != 0 || store.prs["pr-1"].Status != "OPEN" {
+				t.Errorf("batch is written: %d calls, %d transactions, pr-1 %s", store.calls, len(store.applied), store.prs["pr-1"].Status)
+			}
+		})
+	}
+
+	t.Run("applied in one transaction", func(t *testing.T) {
+		store := newStubBatchStore()
+		report, err := runPRBatch(context.Background(), store, &models.PRBatchRequest{Operations: []models.PRBatchOperation{
+			create,
+			{Op: models.BatchOpReassign, PullRequestID: "pr-1", OldUserID: "u2"},
+			merge,
+			merge,
+		}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Applied != 4 || report.Failed != 0 || len(store.applied) != 1 || len(store.applied[0]) != 3 {
+			t.Fatalf("applied %d, failed %d, transactions %v", report.Applied, report.Failed, store.applied)
+		}
+		// Слияние видит замену из того же пакета, повторное слияние ничего не меняет и не записывается
+		pr := store.prs["pr-1"]
+		if pr.Status != "MERGED" || !reflect.DeepEqual(pr.AssignedReviewers, []string{"u4", "u3"}) || report.Results[3].PR.Status != "MERGED" {
+			t.Errorf("pr-1 = %+v", pr)
+		}
+	})
+
+	// Прочие ошибки записи - ошибка запроса, а не отклонённый пакет
+	t.Run("storage error", func(t *testing.T) {
+		store := newStubBatchStore()
+		store.fail = fmt.Errorf("pq: connection reset")
+		if _, err := runPRBatch(context.Background(), store, &models.PRBatchRequest{Operations: []models.PRBatchOperation{merge}}); err != store.fail {
+			t.Fatalf("err = %v, want %v", err, store.fail)
+		}
+	})
+}
+
+func TestRunPRBatchBestEffort(t *testing.T) {
+	store := newStubBatchStore()
+	report, err := runPRBatch(context.Background(), store, &models.PRBatchRequest{Mode: models.BatchBestEffort, Operations: []models.PRBatchOperation{
+		{Op: models.BatchOpCreate, PullRequestID: "pr-2", PullRequestName: "Fix login", AuthorID: "u1"},
+		{Op: models.BatchOpReassign, PullRequestID: "pr-1", OldUserID: "u9"},
+		{Op: models.BatchOpMerge, PullRequestID: "pr-1"},
+		{Op: models.BatchOpMerge, PullRequestID: "pr-404"},
+		{Op: models.BatchOpCreate, PullRequestID: "pr-2", PullRequestName: "Fix login", AuthorID: "u1"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"ok", "error NOT_ASSIGNED", "ok", "error NOT_FOUND", "error PR_EXISTS"}
+	if got := statuses(report); !reflect.DeepEqual(got, want) {
+		t.Errorf("results = %v, want %v", got, want)
+	}
+	if report.Applied != 2 || report.Failed != 3 {
+		t.Errorf("applied %d, failed %d", report.Applied, report.Failed)
+	}
+	// Каждая успешная операция записана отдельно, ошибки других её не отменяют
+	if len(store.applied) != 2 || store.prs["pr-2"] == nil || store.prs["pr-1"].Status != "MERGED" {
+		t.Errorf("transactions %d, pr-2 %v, pr-1 %s", len(store.applied), store.prs["pr-2"], store.prs["pr-1"].Status)
+	}
+}
+
+func TestRunPRBatchBestEffortRetriesModifiedReassign(t *testing.T) {
+	reassign := &models.PRBatchRequest{Mode: models.BatchBestEffort, Operations: []models.PRBatchOperation{
+		{Op: models.BatchOpReassign, PullRequestID: "pr-1", OldUserID: "u2"},
+	}}
+
+	// Параллельная замена u3 на u4 между проверкой и записью: замена u2 проверяется заново, и кандидатом остаётся u3
+	store := newStubBatchStore()
+	store.concurrent = func(s *stubBatchStore) {
+		if s.calls == 1 {
+			s.prs["pr-1"].AssignedReviewers = []string{"u2", "u4"}
+		}
+	}
+	report, err := runPRBatch(context.Background(), store, reassign)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := report.Results[0]
+	if result.Status != models.BatchItemOK || result.ReplacedBy != "u3" || store.calls != 2 {
+		t.Fatalf("result %s %v replaced by %q after %d calls", result.Status, result.Err, result.ReplacedBy, store.calls)
+	}
+	if got := store.prs["pr-1"].AssignedReviewers; !reflect.DeepEqual(got, []string{"u3", "u4"}) {
+		t.Errorf("reviewers = %v, want [u3 u4]", got)
+	}
+
+	// PR меняют при каждой попытке: после reassignAttempts попыток операция завершается PR_MODIFIED
+	store = newStubBatchStore()
+	store.teams["u5"] = "backend"
+	store.concurrent = func(s *stubBatchStore) {
+		s.prs["pr-1"].AssignedReviewers = append(s.prs["pr-1"].AssignedReviewers, fmt.Sprintf("x%d", s.calls))
+	}
+	report, err = runPRBatch(context.Background(), store, reassign)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := report.Results[0]; result.Err == nil || result.Err.Error() != "PR_MODIFIED" || store.calls != reassignAttempts {
+		t.Errorf("result %s %v after %d calls, want PR_MODIFIED after %d", result.Status, result.Err, store.calls, reassignAttempts)
+	}
+}
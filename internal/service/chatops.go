@@ -0,0 +1,22 @@
+package service
+
+import "pr-reviewer-service/internal/models"
+
+func (s *PRService) LinkChatIdentity(chatUserID, userID string) error {
+	if _, err := s.storage.GetUser(userID); err != nil {
+		return err
+	}
+	return s.storage.LinkChatIdentity(chatUserID, userID)
+}
+
+func (s *PRService) ResolveChatUser(chatUserID string) (string, error) {
+	return s.storage.ResolveChatUser(chatUserID)
+}
+
+func (s *PRService) GetTeamSummary(teamName string) (*models.TeamSummary, error) {
+	return s.storage.GetTeamSummary(teamName)
+}
+
+func (s *PRService) GetTeamReviewLoad(teamName string) ([]models.ReviewerLoad, error) {
+	return s.storage.GetTeamReviewLoad(teamName)
+}
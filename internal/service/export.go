@@ -0,0 +1,21 @@
+package service
+
+import (
+	"fmt"
+	"pr-reviewer-service/internal/models"
+)
+
+// StreamPRs выгружает PR по фильтру списка; в отличие от ListPRs лимит не ограничен
+func (s *PRService) StreamPRs(filter models.PRListFilter, fn func(*models.PullRequest) error) error {
+	if filter.After != nil && filter.After.Desc != filter.SortDesc {
+		return fmt.Errorf("INVALID_CURSOR")
+	}
+	return s.storage.StreamPRs(filter, fn)
+}
+
+func (s *PRService) StreamAssignments(filter models.AssignmentFilter, fn func(*models.Assignment) error) error {
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return fmt.Errorf("INVALID_RANGE")
+	}
+	return s.storage.StreamAssignments(filter, fn)
+}
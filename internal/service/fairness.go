@@ -0,0 +1,146 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"pr-reviewer-service/internal/models"
+	"sort"
+	"time"
+)
+
+// DefaultFairnessWindow - окно отчёта о равномерности, если from не задан
+const DefaultFairnessWindow = 30 * 24 * time.Hour
+
+// FairnessReport сравнивает назначения участников команды за [from, to) с ожидаемыми.
+// Ожидаемая доля участника пропорциональна времени, которое он был активен в окне,
+// поэтому отпуск не делает участника "недогруженным".
+func (s *PRService) FairnessReport(teamName string, from, to time.Time, tolerance float64) (*models.FairnessReport, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("INVALID_RANGE")
+	}
+
+	team, err := s.storage.GetTeam(teamName)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := s.storage.ListTeamStatusChanges(teamName, to)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.storage.CountTeamAssignments(teamName, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make(map[string][]models.StatusChange)
+	for _, c := range changes {
+		history[c.UserID] = append(history[c.UserID], c)
+	}
+
+	report := &models.FairnessReport{
+		TeamName:  teamName,
+		From:      from,
+		To:        to,
+		Tolerance: tolerance,
+		Members:   make([]models.MemberFairness, 0, len(team.Members)),
+	}
+
+	var totalAvailability float64
+	for _, member := range team.Members {
+		m := models.MemberFairness{
+			UserID:       member.UserID,
+			IsActive:     member.IsActive,
+			Availability: availability(history[member.UserID], member.IsActive, from, to),
+			Assignments:  counts[member.UserID],
+		}
+		totalAvailability += m.Availability
+		report.TotalAssignments += m.Assignments
+		report.Members = append(report.Members, m)
+	}
+
+	rates := make([]float64, 0, len(report.Members))
+	for i := range report.Members {
+		m := &report.Members[i]
+		if totalAvailability > 0 {
+			m.ExpectedShare = m.Availability / totalAvailability
+		}
+		m.ExpectedAssignments = m.ExpectedShare * float64(report.TotalAssignments)
+		m.Deviation = float64(m.Assignments) - m.ExpectedAssignments
+		if m.ExpectedAssignments > 0 {
+			m.RelativeDeviation = m.Deviation / m.ExpectedAssignments
+		}
+
+		// Отклонение меньше одного назначения не считается перекосом
+		switch {
+		case math.Abs(m.Deviation) < 1:
+		case m.Deviation > 0 && (m.ExpectedAssignments == 0 || m.RelativeDeviation > tolerance):
+			m.Flag = "over"
+		case m.RelativeDeviation < -tolerance:
+			m.Flag = "under"
+		}
+
+		if m.Availability > 0 {
+			rates = append(rates, float64(m.Assignments)/m.Availability)
+		}
+	}
+	report.Gini = gini(rates)
+
+	sort.Slice(report.Members, func(i, j int) bool {
+		return report.Members[i].UserID < report.Members[j].UserID
+	})
+	return report, nil
+}
+
+// availability возвращает долю [from, to), в течение которой пользователь был активен.
+// changes отсортированы по времени; без истории используется текущий статус.
+func availability(changes []models.StatusChange, current bool, from, to time.Time) float64 {
+	if len(changes) == 0 {
+		if current {
+			return 1
+		}
+		return 0
+	}
+
+	var active time.Duration
+	for i, c := range changes {
+		if !c.IsActive {
+			continue
+		}
+		start := c.ChangedAt
+		end := to
+		if i+1 < len(changes) {
+			end = changes[i+1].ChangedAt
+		}
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			active += end.Sub(start)
+		}
+	}
+	return active.Seconds() / to.Sub(from).Seconds()
+}
+
+// gini - коэффициент Джини для неотрицательных значений
+func gini(values []float64) float64 {
+	n := len(values)
+	if n == 0 {
+		return 0
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	var sum, weighted float64
+	for i, v := range sorted {
+		sum += v
+		weighted += float64(i+1) * v
+	}
+	if sum == 0 {
+		return 0
+	}
+	return (2*weighted)/(float64(n)*sum) - float64(n+1)/float64(n)
+}
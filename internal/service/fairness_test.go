@@ -0,0 +1,82 @@
+package service
+
+import (
+	"math"
+	"pr-reviewer-service/internal/models"
+	"testing"
+	"time"
+)
+
+func TestAvailability(t *testing.T) {
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(10 * 24 * time.Hour)
+	day := func(n float64) time.Time { return from.Add(time.Duration(n * 24 * float64(time.Hour))) }
+	change := func(at time.Time, active bool) models.StatusChange {
+		return models.StatusChange{UserID: "u1", IsActive: active, ChangedAt: at}
+	}
+
+	tests := []struct {
+		name    string
+		changes []models.StatusChange
+		current bool
+		want    float64
+	}{
+		{"no history, active", nil, true, 1},
+		{"no history, inactive", nil, false, 0},
+		{"activated before the window", []models.StatusChange{change(day(-5), true)}, true, 1},
+		{"deactivated before the window", []models.StatusChange{change(day(-5), true), change(day(-1), false)}, false, 0},
+		{"joined inside the window", []models.StatusChange{change(day(4), true)}, true, 0.6},
+		{"vacation inside the window", []models.StatusChange{
+			change(day(-30), true), change(day(2), false), change(day(5), true),
+		}, true, 0.7},
+		{"two vacations", []models.StatusChange{
+			change(day(-30), true), change(day(1), false), change(day(2), true), change(day(8), false), change(day(9.5), true),
+		}, true, 0.75},
+		{"deactivated after the window", []models.StatusChange{change(day(-3), true), change(day(12), false)}, false, 1},
+		{"activated after the window", []models.StatusChange{change(day(-3), false), change(day(11), true)}, true, 0},
+		{"inactive for the whole window", []models.StatusChange{change(day(-3), true), change(day(-2), false)}, false, 0},
+		{"repeated active changes are not counted twice", []models.StatusChange{
+			change(day(-1), true), change(day(3), true), change(day(6), false),
+		}, false, 0.6},
+		{"change at the window start", []models.StatusChange{change(from, true)}, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := availability(tt.changes, tt.current, from, to)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("availability() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGini(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   float64
+	}{
+		{"no members", nil, 0},
+		{"one member", []float64{7}, 0},
+		{"zero load", []float64{0, 0, 0}, 0},
+		{"uniform load", []float64{4, 4, 4, 4}, 0},
+		{"all load on one of two", []float64{0, 10}, 0.5},
+		{"all load on one of four", []float64{0, 12, 0, 0}, 0.75},
+		{"unsorted input", []float64{3, 1, 2}, 2.0 / 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := gini(tt.values)
+			if math.IsNaN(got) || math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("gini(%v) = %v, want %v", tt.values, got, tt.want)
+			}
+		})
+	}
+
+	// Вход не сортируется на месте: это ставки участников в порядке отчёта
+	values := []float64{3, 1, 2}
+	gini(values)
+	if values[0] != 3 || values[1] != 1 || values[2] != 2 {
+		t.Errorf("gini() reordered its input: %v", values)
+	}
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"log"
+	"pr-reviewer-service/internal/models"
+	"time"
+)
+
+// ReserveIdempotencyKey, CompleteIdempotencyKey и ReleaseIdempotencyKey реализуют idempotency.Store
+
+func (s *PRService) ReserveIdempotencyKey(actor, key, fingerprint string, now, expiresAt time.Time) (*models.IdempotencyRecord, bool, error) {
+	return s.storage.ReserveIdempotencyKey(actor, key, fingerprint, now, expiresAt)
+}
+
+func (s *PRService) CompleteIdempotencyKey(actor, key string, statusCode int, contentType string, body []byte) error {
+	return s.storage.CompleteIdempotencyKey(actor, key, statusCode, contentType, body)
+}
+
+func (s *PRService) ReleaseIdempotencyKey(actor, key string) error {
+	return s.storage.ReleaseIdempotencyKey(actor, key)
+}
+
+// PruneIdempotencyKeys удаляет истёкшие ключи
+func (s *PRService) PruneIdempotencyKeys(ctx context.Context) error {
+	n, err := s.storage.PruneIdempotencyKeys(time.Now())
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Printf("idempotency keys: pruned %d rows", n)
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"pr-reviewer-service/internal/audit"
+	"pr-reviewer-service/internal/importer"
+	"pr-reviewer-service/internal/models"
+	"pr-reviewer-service/internal/validation"
+	"time"
+)
+
+const ActionImport = "import.apply"
+
+// importLookup - запросы к хранилищу, нужные проверке набора
+type importLookup interface {
+	ExistingTeams(names []string) (map[string]bool, error)
+	ExistingUsers(ids []string) (map[string]bool, error)
+	ExistingPRs(ids []string) (map[string]bool, error)
+}
+
+// importUser - пользователь из набора с указанием, где он описан, для отчёта об ошибках
+type importUser struct {
+	models.User
+	section string
+	row     int
+}
+
+// Import проверяет набор целиком и, если ошибок нет и это не пробный запуск, применяет его в одной транзакции.
+// Отчёт содержит все найденные ошибки; при ошибках ничего не сохраняется.
+func (s *PRService) Import(ctx context.Context, bundle *models.ImportBundle, dryRun bool) (*models.ImportReport, error) {
+	teams, users, prs, errs, err := validateImport(s.storage, bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ImportReport{DryRun: dryRun, Errors: errs}
+	if len(errs) > 0 || dryRun {
+		existingTeams, err := s.storage.ExistingTeams(teams)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, len(users))
+		for i := range users {
+			ids[i] = users[i].UserID
+		}
+		existingUsers, err := s.storage.ExistingUsers(ids)
+		if err != nil {
+			return nil, err
+		}
+
+		report.NewTeams = len(teams) - len(existingTeams)
+		report.UpdatedUsers = len(existingUsers)
+		report.NewUsers = len(users) - len(existingUsers)
+		report.PullRequests = len(prs)
+		return report, nil
+	}
+
+	entry := auditEntry(ctx, ActionImport, "import", audit.RequestID(ctx), nil, nil)
+	applied, err := s.storage.ApplyImport(teams, users, prs, audit.Actor(ctx), entry)
+	if err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
+
+// validateImport нормализует набор и собирает ошибки по строкам
+func validateImport(lookup importLookup, bundle *models.ImportBundle) ([]string, []models.User, []models.PullRequest, []models.ImportError, error) {
+	errs := append([]models.ImportError{}, bundle.ParseErrors...)
+	fail := func(section string, row int, id, format string, args ...interface{}) {
+		errs = append(errs, models.ImportError{Section: section, Row: row, ID: id, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// Команды
+	var teams []string
+	teamSet := make(map[string]bool)
+	var flat []importUser
+	for i, team := range bundle.Teams {
+		row := i + 1
+		if team.TeamName == "" {
+			fail(importer.SectionTeams, row, "", "team_name is required")
+			continue
+		}
+		if msg := validation.Name(team.TeamName); msg != "" {
+			fail(importer.SectionTeams, row, team.TeamName, "team_name %s", msg)
+			continue
+		}
+		if teamSet[team.TeamName] {
+			fail(importer.SectionTeams, row, team.TeamName, "duplicate team")
+			continue
+		}
+		teamSet[team.TeamName] = true
+		teams = append(teams, team.TeamName)
+
+		for _, m := range team.Members {
+			flat = append(flat, importUser{
+				User:    models.User{UserID: m.UserID, Username: m.Username, TeamName: team.TeamName, IsActive: m.IsActive},
+				section: importer.SectionTeams,
+				row:     row,
+			})
+		}
+	}
+	for i, user := range bundle.Users {
+		flat = append(flat, importUser{User: user, section: importer.SectionUsers, row: i + 1})
+	}
+
+	var teamRefs []string
+	for _, u := range flat {
+		if u.TeamName != "" && !teamSet[u.TeamName] {
+			teamRefs = append(teamRefs, u.TeamName)
+		}
+	}
+	knownTeams, err := lookup.ExistingTeams(teamRefs)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	// Пользователи
+	var users []models.User
+	userSet := make(map[string]bool)
+	for _, u := range flat {
+		switch {
+		case u.UserID == "":
+			fail(u.section, u.row, "", "user_id is required")
+		case u.Username == "":
+			fail(u.section, u.row, u.UserID, "username is required")
+		case u.TeamName == "":
+			fail(u.section, u.row, u.UserID, "team_name is required")
+		case validation.ID(u.UserID) != "":
+			fail(u.section, u.row, u.UserID, "user_id %s", validation.ID(u.UserID))
+		case validation.Name(u.Username) != "":
+			fail(u.section, u.row, u.UserID, "username %s", validation.Name(u.Username))
+		case !teamSet[u.TeamName] && !knownTeams[u.TeamName]:
+			fail(u.section, u.row, u.UserID, "team %q is neither in the bundle nor in the database", u.TeamName)
+		case userSet[u.UserID]:
+			fail(u.section, u.row, u.UserID, "duplicate user")
+		default:
+			userSet[u.UserID] = true
+			users = append(users, u.User)
+		}
+	}
+
+	// PR
+	var prIDs, userRefs []string
+	for _, pr := range bundle.PullRequests {
+		prIDs = append(prIDs, pr.PullRequestID)
+		for _, id := range append([]string{pr.AuthorID}, pr.AssignedReviewers...) {
+			if !userSet[id] {
+				userRefs = append(userRefs, id)
+			}
+		}
+	}
+	existingPRs, err := lookup.ExistingPRs(prIDs)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	knownUsers, err := lookup.ExistingUsers(userRefs)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	userExists := func(id string) bool { return userSet[id] || knownUsers[id] }
+
+	var prs []models.PullRequest
+	prSet := make(map[string]bool)
+	now := time.Now()
+	for i, pr := range bundle.PullRequests {
+		row := i + 1
+		before := len(errs)
+		id := pr.PullRequestID
+
+		switch {
+		case id == "":
+			fail(importer.SectionPullRequests, row, "", "pull_request_id is required")
+		case validation.ID(id) != "":
+			fail(importer.SectionPullRequests, row, id, "pull_request_id %s", validation.ID(id))
+		case prSet[id]:
+			fail(importer.SectionPullRequests, row, id, "duplicate pull request")
+		case existingPRs[id]:
+			fail(importer.SectionPullRequests, row, id, "pull request already exists")
+		}
+		prSet[id] = true
+
+		if pr.PullRequestName == "" {
+			fail(importer.SectionPullRequests, row, id, "pull_request_name is required")
+		} else if msg := validation.Name(pr.PullRequestName); msg != "" {
+			fail(importer.SectionPullRequests, row, id, "pull_request_name %s", msg)
+		}
+		if !userExists(pr.AuthorID) {
+			fail(importer.SectionPullRequests, row, id, "author %q is unknown", pr.AuthorID)
+		}
+
+		if pr.Status == "" {
+			pr.Status = "OPEN"
+			if pr.MergedAt != nil {
+				pr.Status = "MERGED"
+			}
+		}
+		switch pr.Status {
+		case "OPEN":
+			if pr.MergedAt != nil {
+				fail(importer.SectionPullRequests, row, id, "open pull request must not have merged_at")
+			}
+		case "MERGED":
+			if pr.MergedAt == nil {
+				fail(importer.SectionPullRequests, row, id, "merged pull request requires merged_at")
+			}
+		default:
+			fail(importer.SectionPullRequests, row, id, "status must be OPEN or MERGED")
+		}
+
+		if pr.CreatedAt == nil {
+			pr.CreatedAt = &now
+		}
+		if pr.CreatedAt.After(now) {
+			fail(importer.SectionPullRequests, row, id, "created_at is in the future")
+		}
+		if pr.MergedAt != nil && pr.MergedAt.Before(*pr.CreatedAt) {
+			fail(importer.SectionPullRequests, row, id, "merged_at is before created_at")
+		}
+
+		if pr.AssignedReviewers == nil {
+			pr.AssignedReviewers = []string{}
+		}
+		seen := make(map[string]bool)
+		for _, reviewer := range pr.AssignedReviewers {
+			switch {
+			case reviewer == pr.AuthorID:
+				fail(importer.SectionPullRequests, row, id, "author %q cannot review own pull request", reviewer)
+			case seen[reviewer]:
+				fail(importer.SectionPullRequests, row, id, "reviewer %q listed twice", reviewer)
+			case !userExists(reviewer):
+				fail(importer.SectionPullRequests, row, id, "reviewer %q is unknown", reviewer)
+			}
+			seen[reviewer] = true
+		}
+
+		if len(errs) == before {
+			prs = append(prs, pr)
+		}
+	}
+
+	return teams, users, prs, errs, nil
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"pr-reviewer-service/internal/importer"
+	"pr-reviewer-service/internal/models"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubLookup - хранилище, в котором уже есть перечисленные команды, пользователи и PR
+type stubLookup struct {
+	teams, users, prs []string
+}
+
+func has(known, ids []string) map[string]bool {
+	found := make(map[string]bool)
+	for _, id := range ids {
+		for _, k := range known {
+			if id == k {
+				found[id] = true
+			}
+		}
+	}
+	return found
+}
+
+func (l stubLookup) ExistingTeams(names []string) (map[string]bool, error) {
+	return has(l.teams, names), nil
+}
+
+func (l stubLookup) ExistingUsers(ids []string) (map[string]bool, error) {
+	return has(l.users, ids), nil
+}
+
+func (l stubLookup) ExistingPRs(ids []string) (map[string]bool, error) {
+	return has(l.prs, ids), nil
+}
+
+func TestValidateImport(t *testing.T) {
+	csv := []struct{ section, input string }{
+		{importer.SectionTeams, "team_name\nbackend\nbackend\nbad\tname\n"},
+		{importer.SectionUsers, "user_id,username,team_name,is_active\n" +
+			"u1,Alice,backend,true\n" +
+			"u2,Bob,ops,maybe\n" +
+			"u1,Alice again,backend,true\n" +
+			"u3,Carol,qa,true\n" +
+			"u 4,Dave,backend,true\n"},
+		{importer.SectionPullRequests, "pull_request_id,pull_request_name,author_id,status,assigned_reviewers,created_at,merged_at\n" +
+			"pr-1,Add search,u1,OPEN,u2,,\n" +
+			"pr-1,Add search,u1,OPEN,,,\n" +
+			"pr-old,Legacy,u1,OPEN,,,\n" +
+			"pr-2,Fix login,u9,MERGED,u1;u1,2025-03-02T00:00:00Z,2025-03-01T00:00:00Z\n"},
+	}
+	bundle := &models.ImportBundle{}
+	for _, f := range csv {
+		if err := importer.DecodeCSV(bundle, f.section, strings.NewReader(f.input)); err != nil {
+			t.Fatalf("DecodeCSV(%s) = %v", f.section, err)
+		}
+	}
+
+	teams, users, prs, errs, err := validateImport(stubLookup{teams: []string{"ops"}, prs: []string{"pr-old"}}, bundle)
+	if err != nil {
+		t.Fatalf("validateImport() = %v", err)
+	}
+
+	want := []models.ImportError{
+		// Ошибки разбора идут первыми, строка с ними проверяется дальше как обычно
+		{Section: "users", Row: 2, ID: "u2", Message: "is_active must be true or false"},
+		{Section: "teams", Row: 2, ID: "backend", Message: "duplicate team"},
+		{Section: "teams", Row: 3, ID: "bad\tname", Message: "team_name must not contain control characters"},
+		{Section: "users", Row: 3, ID: "u1", Message: "duplicate user"},
+		{Section: "users", Row: 4, ID: "u3", Message: `team "qa" is neither in the bundle nor in the database`},
+		{Section: "users", Row: 5, ID: "u 4", Message: "user_id may contain only latin letters, digits and . _ - : @ /"},
+		{Section: "pull_requests", Row: 2, ID: "pr-1", Message: "duplicate pull request"},
+		{Section: "pull_requests", Row: 3, ID: "pr-old", Message: "pull request already exists"},
+		{Section: "pull_requests", Row: 4, ID: "pr-2", Message: `author "u9" is unknown`},
+		{Section: "pull_requests", Row: 4, ID: "pr-2", Message: "merged_at is before created_at"},
+		{Section: "pull_requests", Row: 4, ID: "pr-2", Message: `reviewer "u1" listed twice`},
+	}
+	if !reflect.DeepEqual(errs, want) {
+		t.Errorf("errors:\n got %+v\nwant %+v", errs, want)
+	}
+
+	if !reflect.DeepEqual(teams, []string{"backend"}) {
+		t.Errorf("teams = %v", teams)
+	}
+	if len(users) != 2 || users[0].UserID != "u1" || users[1].UserID != "u2" {
+		t.Errorf("users = %+v", users)
+	}
+	if len(prs) != 1 || prs[0].PullRequestID != "pr-1" || prs[0].CreatedAt == nil || prs[0].CreatedAt.After(time.Now()) {
+		t.Errorf("pull requests = %+v", prs)
+	}
+}
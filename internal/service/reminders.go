@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/mail"
+	"pr-reviewer-service/internal/models"
+	"pr-reviewer-service/internal/notify"
+	"strings"
+	"time"
+)
+
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+
+	// digestLimit - сколько PR попадает в один дайджест
+	digestLimit = 50
+
+	// webhookResolveTimeout - сколько ждать DNS при проверке адреса вебхука
+	webhookResolveTimeout = 5 * time.Second
+)
+
+func (s *PRService) GetReminderSettings(userID string) (*models.ReminderSettings, error) {
+	return s.storage.GetReminderSettings(userID)
+}
+
+// SetReminderSettings подписывает пользователя на дайджест или отписывает (Enabled = false)
+func (s *PRService) SetReminderSettings(settings *models.ReminderSettings) error {
+	if _, err := s.storage.GetUser(settings.UserID); err != nil {
+		return err
+	}
+
+	if settings.TimeZone == "" {
+		settings.TimeZone = "UTC"
+	}
+	if settings.SendAt == "" {
+		settings.SendAt = "09:00"
+	}
+
+	switch settings.Channel {
+	case ChannelEmail:
+		if _, err := mail.ParseAddress(settings.Address); err != nil {
+			return fmt.Errorf("INVALID_REMINDER: address must be a valid email for channel email")
+		}
+	case ChannelWebhook:
+		if settings.Address != "" {
+			ctx, cancel := context.WithTimeout(context.Background(), webhookResolveTimeout)
+			err := notify.ValidateWebhookURL(ctx, settings.Address)
+			cancel()
+			if err != nil {
+				return fmt.Errorf("INVALID_REMINDER: address must be a public http(s) URL for channel webhook")
+			}
+		}
+	default:
+		return fmt.Errorf("INVALID_REMINDER: channel must be email or webhook")
+	}
+	if _, ok := s.notifiers[settings.Channel]; !ok && settings.Enabled {
+		return fmt.Errorf("INVALID_REMINDER: channel %s is not configured on this server", settings.Channel)
+	}
+
+	if _, err := time.LoadLocation(settings.TimeZone); err != nil {
+		return fmt.Errorf("INVALID_REMINDER: unknown time_zone %q", settings.TimeZone)
+	}
+	if _, err := time.Parse("15:04", settings.SendAt); err != nil {
+		return fmt.Errorf("INVALID_REMINDER: send_at must be HH:MM")
+	}
+
+	return s.storage.SetReminderSettings(settings)
+}
+
+// SendDigests отправляет дайджест каждому подписчику, у которого в его часовом поясе
+// наступило время send_at и сегодня дайджест ещё не отправлялся
+func (s *PRService) SendDigests(ctx context.Context) error {
+	reminders, err := s.storage.ListEnabledReminders()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, r := range reminders {
+		loc, err := time.LoadLocation(r.TimeZone)
+		if err != nil {
+			log.Printf("reminders: user %s: %v", r.UserID, err)
+			continue
+		}
+		local := now.In(loc)
+		today := local.Format("2006-01-02")
+
+		if local.Format("15:04") < r.SendAt {
+			continue
+		}
+		if r.LastSentOn != nil && r.LastSentOn.Format("2006-01-02") >= today {
+			continue
+		}
+
+		if err := s.sendDigest(ctx, r, now); err != nil {
+			log.Printf("reminders: user %s: %v", r.UserID, err)
+			continue
+		}
+		if err := s.storage.MarkReminderSent(r.UserID, today); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *PRService) sendDigest(ctx context.Context, r models.ReminderSettings, now time.Time) error {
+	prs, total, err := s.storage.GetUserReviewPRs(models.ReviewFilter{
+		UserID:   r.UserID,
+		Statuses: []string{"OPEN"},
+		Limit:    digestLimit,
+	})
+	if err != nil {
+		return err
+	}
+	if len(prs) == 0 {
+		return nil
+	}
+	if len(prs) > digestLimit {
+		prs = prs[:digestLimit]
+	}
+
+	notifier, ok := s.notifiers[r.Channel]
+	if !ok {
+		return fmt.Errorf("channel %s is not configured", r.Channel)
+	}
+
+	return notifier.Send(ctx, notify.Message{
+		UserID:  r.UserID,
+		Address: r.Address,
+		Subject: fmt.Sprintf("%d PR waiting for your review", total),
+		Text:    formatDigest(r.UserID, prs, total, now),
+	})
+}
+
+// formatDigest - список PR от самых старых к новым с возрастом каждого
+func formatDigest(userID string, prs []models.PullRequestShort, total int, now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Open pull requests assigned to %s, oldest first:\n\n", userID)
+	for _, pr := range prs {
+		age := ""
+		if pr.CreatedAt != nil {
+			age = formatAge(now.Sub(*pr.CreatedAt))
+		}
+		overdue := ""
+		if pr.Overdue {
+			overdue = " [OVERDUE]"
+		}
+		fmt.Fprintf(&b, "- %s %q by %s, open %s%s\n", pr.PullRequestID, pr.PullRequestName, pr.AuthorID, age, overdue)
+	}
+	if total > len(prs) {
+		fmt.Fprintf(&b, "\n...and %d more\n", total-len(prs))
+	}
+	return b.String()
+}
+
+func formatAge(d time.Duration) string {
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	if days > 0 {
+		return fmt.Sprintf("%dd %dh", days, hours)
+	}
+	return fmt.Sprintf("%dh %dm", hours, int(d.Minutes())%60)
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"pr-reviewer-service/internal/models"
+	"time"
+)
+
+// ReviewerEventPollInterval - как часто подписка на события ревьювера проверяет журнал PR
+const ReviewerEventPollInterval = 2 * time.Second
+
+// reviewerEventSettle - более свежие события откладываются до следующей проверки: транзакция с меньшим id
+// могла ещё не зафиксироваться. Журнал читается по id, а id выдаются до фиксации, поэтому задержка только
+// снижает риск: событие транзакции, которая фиксируется дольше reviewerEventSettle, в поток не попадёт.
+const reviewerEventSettle = time.Second
+
+const reviewerEventBatch = 100
+
+// ReviewerEventsHead возвращает id, начиная с которого WatchReviewerEvents отдаст только новые события
+func (s *PRService) ReviewerEventsHead() (int64, error) {
+	return s.storage.LastPREventID(time.Now().Add(-reviewerEventSettle))
+}
+
+// WatchReviewerEvents вызывает fn для каждого события ревьювера с id больше afterID (см. PREvent.ReviewerKind),
+// пока не отменён ctx или fn не вернёт ошибку
+func (s *PRService) WatchReviewerEvents(ctx context.Context, userID string, afterID int64, fn func(*models.PREvent) error) error {
+	if _, err := s.storage.GetUser(userID); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(ReviewerEventPollInterval)
+	defer ticker.Stop()
+	for {
+		events, err := s.storage.ListReviewerEvents(userID, afterID, time.Now().Add(-reviewerEventSettle), reviewerEventBatch)
+		if err != nil {
+			return err
+		}
+		for i := range events {
+			if err := fn(&events[i]); err != nil {
+				return err
+			}
+			afterID = events[i].ID
+		}
+		if len(events) == reviewerEventBatch && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -1,34 +1,96 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
+	"pr-reviewer-service/internal/audit"
 	"pr-reviewer-service/internal/models"
+	"pr-reviewer-service/internal/notify"
+	"pr-reviewer-service/internal/pagination"
 	"pr-reviewer-service/internal/storage"
+	"sort"
 	"time"
 )
 
 type PRService struct {
-	storage *storage.PostgresStorage
+	storage   *storage.PostgresStorage
+	notifiers map[string]notify.Notifier
 }
 
 func NewPRService(storage *storage.PostgresStorage) *PRService {
-	return &PRService{storage: storage}
+	return &PRService{
+		storage:   storage,
+		notifiers: make(map[string]notify.Notifier),
+	}
+}
+
+// RegisterNotifier подключает канал доставки уведомлений (email, webhook)
+func (s *PRService) RegisterNotifier(channel string, n notify.Notifier) {
+	s.notifiers[channel] = n
 }
 
-func (s *PRService) CreateTeam(team *models.Team) error {
-	return s.storage.CreateTeam(team)
+func (s *PRService) CreateTeam(ctx context.Context, team *models.Team) error {
+	return s.storage.CreateTeam(team, auditEntry(ctx, ActionTeamCreate, "team", team.TeamName, nil, team))
 }
 
 func (s *PRService) GetTeam(teamName string) (*models.Team, error) {
 	return s.storage.GetTeam(teamName)
 }
 
-func (s *PRService) SetUserActive(userID string, isActive bool) (*models.User, error) {
-	return s.storage.SetUserActive(userID, isActive)
+// ListTeams возвращает страницу команд со счётчиками участников и открытых PR
+func (s *PRService) ListTeams(after *models.PageCursor, limit int) ([]models.TeamSummary, string, error) {
+	limit = pagination.ClampLimit(limit)
+	afterName := ""
+	if after != nil {
+		afterName = after.ID
+	}
+
+	teams, err := s.storage.ListTeams(afterName, limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var nextCursor string
+	if len(teams) > limit {
+		teams = teams[:limit]
+		nextCursor = pagination.Encode(models.PageCursor{ID: teams[limit-1].TeamName})
+	}
+	return teams, nextCursor, nil
+}
+
+func (s *PRService) GetUser(userID string) (*models.User, error) {
+	return s.storage.GetUser(userID)
+}
+
+func (s *PRService) ListUsers(filter models.UserFilter) ([]models.User, string, error) {
+	filter.Limit = pagination.ClampLimit(filter.Limit)
+
+	users, err := s.storage.ListUsers(filter)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var nextCursor string
+	if len(users) > filter.Limit {
+		users = users[:filter.Limit]
+		nextCursor = pagination.Encode(models.PageCursor{ID: users[filter.Limit-1].UserID})
+	}
+	return users, nextCursor, nil
+}
+
+func (s *PRService) SetUserActive(ctx context.Context, userID string, isActive bool) (*models.User, error) {
+	before, err := s.storage.GetUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	after := *before
+	after.IsActive = isActive
+	return s.storage.SetUserActive(userID, isActive, auditEntry(ctx, ActionUserSetActive, "user", userID, before, &after))
 }
 
-func (s *PRService) CreatePR(prID, prName, authorID string) (*models.PullRequest, error) {
+func (s *PRService) CreatePR(ctx context.Context, prID, prName, authorID string) (*models.PullRequest, error) {
 	exists, err := s.storage.PRExists(prID)
 	if err != nil {
 		return nil, err
@@ -56,10 +118,9 @@ func (s *PRService) CreatePR(prID, prName, authorID string) (*models.PullRequest
 		CreatedAt:         &[]time.Time{time.Now()}[0],
 	}
 
-	if err := s.storage.CreatePR(pr); err != nil {
+	if err := s.storage.CreatePR(pr, audit.Actor(ctx), auditEntry(ctx, ActionPRCreate, "pull_request", prID, nil, pr)); err != nil {
 		return nil, err
 	}
-
 	return pr, nil
 }
 
@@ -70,16 +131,21 @@ func (s *PRService) selectReviewers(teamName, excludeUserID string) ([]string, e
 	}
 
 	rand.Seed(time.Now().UnixNano())
-	rand.Shuffle(len(availableReviewers), func(i, j int) {
-		availableReviewers[i], availableReviewers[j] = availableReviewers[j], availableReviewers[i]
-	})
+	return pickLeastLoaded(availableReviewers, nil, 2), nil
+}
 
-	count := min(2, len(availableReviewers))
-	if count == 0 {
-		return []string{}, nil
-	}
+// pickLeastLoaded выбирает до count кандидатов с наименьшей нагрузкой load, при равенстве - случайно.
+// С пустым load выбор полностью случайный.
+func pickLeastLoaded(candidates []string, load map[string]int, count int) []string {
+	shuffled := append([]string{}, candidates...)
+	rand.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	sort.SliceStable(shuffled, func(i, j int) bool {
+		return load[shuffled[i]] < load[shuffled[j]]
+	})
 
-	return availableReviewers[:count], nil
+	return shuffled[:min(count, len(shuffled))]
 }
 
 func min(a, b int) int {
@@ -89,18 +155,22 @@ func min(a, b int) int {
 	return b
 }
 
-func (s *PRService) MergePR(prID string) (*models.PullRequest, error) {
+func (s *PRService) MergePR(ctx context.Context, prID string) (*models.PullRequest, error) {
 	pr, err := s.storage.GetPR(prID)
 	if err != nil {
 		return nil, err
 	}
 
 	if pr.Status == "OPEN" {
-		if err := s.storage.MergePR(prID); err != nil {
+		now := time.Now()
+		merged := *pr
+		merged.Status = "MERGED"
+		merged.MergedAt = &now
+		merged.Overdue = false
+		if err := s.storage.MergePR(prID, audit.Actor(ctx), now, auditEntry(ctx, ActionPRMerge, "pull_request", prID, pr, &merged)); err != nil {
 			return nil, err
 		}
-		pr, err = s.storage.GetPR(prID)
-		if err != nil {
+		if pr, err = s.storage.GetPR(prID); err != nil {
 			return nil, err
 		}
 	}
@@ -108,7 +178,26 @@ func (s *PRService) MergePR(prID string) (*models.PullRequest, error) {
 	return pr, nil
 }
 
-func (s *PRService) ReassignReviewer(prID, oldUserID string) (*models.PullRequest, string, error) {
+func (s *PRService) ReassignReviewer(ctx context.Context, prID, oldUserID string) (*models.PullRequest, string, error) {
+	return s.reassignReviewer(ctx, prID, oldUserID, models.ReasonManualReassign)
+}
+
+// reassignAttempts - сколько раз замена перечитывает PR, если его изменили параллельно
+const reassignAttempts = 3
+
+// reassignReviewer заменяет ревьювера случайным активным участником его команды, reason попадает в историю PR.
+// Если PR изменили между чтением и записью, замена проверяется заново по свежему состоянию.
+func (s *PRService) reassignReviewer(ctx context.Context, prID, oldUserID, reason string) (*models.PullRequest, string, error) {
+	for attempt := 1; ; attempt++ {
+		pr, newReviewer, err := s.tryReassignReviewer(ctx, prID, oldUserID, reason)
+		if err != nil && err.Error() == "PR_MODIFIED" && attempt < reassignAttempts {
+			continue
+		}
+		return pr, newReviewer, err
+	}
+}
+
+func (s *PRService) tryReassignReviewer(ctx context.Context, prID, oldUserID, reason string) (*models.PullRequest, string, error) {
 	pr, err := s.storage.GetPR(prID)
 	if err != nil {
 		return nil, "", err
@@ -139,8 +228,32 @@ func (s *PRService) ReassignReviewer(prID, oldUserID string) (*models.PullReques
 		return nil, "", err
 	}
 
+	candidates := replacementCandidates(pr, availableReviewers)
+	if len(candidates) == 0 {
+		return nil, "", fmt.Errorf("NO_CANDIDATE")
+	}
+
+	newReviewer := candidates[rand.Intn(len(candidates))]
+	newReviewers := replaceReviewer(pr.AssignedReviewers, oldUserID, newReviewer)
+
+	updated := *pr
+	updated.AssignedReviewers = newReviewers
+	entry := auditEntry(ctx, ActionPRReassign, "pull_request", prID, pr, &updated)
+	if err := s.storage.ReplacePRReviewer(prID, pr.AssignedReviewers, newReviewers, oldUserID, newReviewer, reason, audit.Actor(ctx), entry); err != nil {
+		return nil, "", err
+	}
+
+	updatedPR, err := s.storage.GetPR(prID)
+	if err != nil {
+		return nil, "", err
+	}
+	return updatedPR, newReviewer, nil
+}
+
+// replacementCandidates - участники команды, которые ещё не ревьюят PR и не являются его автором
+func replacementCandidates(pr *models.PullRequest, available []string) []string {
 	var candidates []string
-	for _, candidate := range availableReviewers {
+	for _, candidate := range available {
 		isAlreadyReviewer := false
 		for _, reviewer := range pr.AssignedReviewers {
 			if candidate == reviewer {
@@ -152,39 +265,112 @@ func (s *PRService) ReassignReviewer(prID, oldUserID string) (*models.PullReques
 			candidates = append(candidates, candidate)
 		}
 	}
+	return candidates
+}
 
-	if len(candidates) == 0 {
-		return nil, "", fmt.Errorf("NO_CANDIDATE")
-	}
-
-	newReviewer := candidates[rand.Intn(len(candidates))]
-
-	newReviewers := make([]string, len(pr.AssignedReviewers))
-	for i, reviewer := range pr.AssignedReviewers {
+func replaceReviewer(reviewers []string, oldUserID, newUserID string) []string {
+	newReviewers := make([]string, len(reviewers))
+	for i, reviewer := range reviewers {
 		if reviewer == oldUserID {
-			newReviewers[i] = newReviewer
+			newReviewers[i] = newUserID
 		} else {
 			newReviewers[i] = reviewer
 		}
 	}
+	return newReviewers
+}
 
-	if err := s.storage.UpdatePRReviewers(prID, newReviewers); err != nil {
-		return nil, "", err
+func (s *PRService) GetPR(prID string) (*models.PullRequest, error) {
+	return s.storage.GetPR(prID)
+}
+
+// GetPRTimeline возвращает упорядоченную историю PR
+func (s *PRService) GetPRTimeline(prID string) ([]models.PREvent, error) {
+	if _, err := s.storage.GetPR(prID); err != nil {
+		return nil, err
+	}
+	return s.storage.GetPREvents(prID)
+}
+
+// GetUserReviewPRs возвращает страницу PR, назначенных пользователю, и курсор следующей страницы
+func (s *PRService) GetUserReviewPRs(filter models.ReviewFilter) (*models.ReviewPage, error) {
+	filter.Limit = pagination.ClampLimit(filter.Limit)
+	if filter.After != nil && filter.After.Desc != filter.SortDesc {
+		return nil, fmt.Errorf("INVALID_CURSOR")
 	}
 
-	updatedPR, err := s.storage.GetPR(prID)
+	prs, total, err := s.storage.GetUserReviewPRs(filter)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
-	return updatedPR, newReviewer, nil
+	page := &models.ReviewPage{PullRequests: prs, Total: total}
+	if len(prs) > filter.Limit {
+		page.PullRequests = prs[:filter.Limit]
+		last := page.PullRequests[filter.Limit-1]
+		page.NextCursor = pagination.Encode(models.PageCursor{
+			CreatedAt: *last.CreatedAt,
+			ID:        last.PullRequestID,
+			Desc:      filter.SortDesc,
+		})
+	}
+
+	return page, nil
+}
+
+// OpenReviews возвращает все открытые PR, где пользователь ревьювер, от самых старых
+func (s *PRService) OpenReviews(userID string) ([]models.PullRequestShort, error) {
+	filter := models.ReviewFilter{UserID: userID, Statuses: []string{"OPEN"}, Limit: pagination.MaxLimit}
+	prs := []models.PullRequestShort{}
+	for {
+		page, err := s.GetUserReviewPRs(filter)
+		if err != nil {
+			return nil, err
+		}
+		prs = append(prs, page.PullRequests...)
+		if page.NextCursor == "" {
+			return prs, nil
+		}
+		last := page.PullRequests[len(page.PullRequests)-1]
+		filter.After = &models.PageCursor{CreatedAt: *last.CreatedAt, ID: last.PullRequestID}
+	}
 }
 
-func (s *PRService) GetUserReviewPRs(userID string) ([]models.PullRequestShort, error) {
-	return s.storage.GetUserReviewPRs(userID)
+// ListPRs возвращает страницу PR по фильтрам и курсор следующей страницы
+func (s *PRService) ListPRs(filter models.PRListFilter) (*models.PRListPage, error) {
+	filter.Limit = pagination.ClampLimit(filter.Limit)
+	if filter.After != nil && filter.After.Desc != filter.SortDesc {
+		return nil, fmt.Errorf("INVALID_CURSOR")
+	}
+
+	prs, total, err := s.storage.ListPRs(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.PRListPage{PullRequests: prs, Total: total}
+	if len(prs) > filter.Limit {
+		page.PullRequests = prs[:filter.Limit]
+		last := page.PullRequests[filter.Limit-1]
+		page.NextCursor = pagination.Encode(models.PageCursor{
+			CreatedAt: *last.CreatedAt,
+			ID:        last.PullRequestID,
+			Desc:      filter.SortDesc,
+		})
+	}
+
+	return page, nil
 }
 
-// GetStats возвращает статистику системы
-func (s *PRService) GetStats() (map[string]interface{}, error) {
-	return s.storage.GetStats()
+// GetStats возвращает статистику системы, при необходимости по команде и интервалу создания PR
+func (s *PRService) GetStats(filter models.StatsFilter) (*models.Stats, error) {
+	if filter.TeamName != "" {
+		if _, err := s.storage.GetTeamSummary(filter.TeamName); err != nil {
+			return nil, err
+		}
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, fmt.Errorf("INVALID_RANGE")
+	}
+	return s.storage.GetStats(filter)
 }
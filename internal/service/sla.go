@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"pr-reviewer-service/internal/audit"
+	"pr-reviewer-service/internal/models"
+	"pr-reviewer-service/internal/sla"
+	"time"
+)
+
+// slaActor - инициатор действий, выполняемых проверкой SLA
+const slaActor = "sla-scheduler"
+
+func (s *PRService) GetTeamSLA(teamName string) (*models.TeamSLA, error) {
+	return s.storage.GetTeamSLA(teamName)
+}
+
+// SetTeamSLA проверяет и сохраняет настройки SLA команды
+func (s *PRService) SetTeamSLA(settings *models.TeamSLA) error {
+	if settings.TimeZone == "" {
+		settings.TimeZone = "UTC"
+	}
+	if settings.WorkdayStart == "" {
+		settings.WorkdayStart = "09:00"
+	}
+	if settings.WorkdayEnd == "" {
+		settings.WorkdayEnd = "18:00"
+	}
+	if settings.WeekendDays == nil {
+		settings.WeekendDays = []string{"Saturday", "Sunday"}
+	}
+	if settings.Holidays == nil {
+		settings.Holidays = []string{}
+	}
+
+	if settings.FirstReviewMinutes <= 0 {
+		return fmt.Errorf("INVALID_SLA: first_review_minutes must be positive")
+	}
+	if _, err := calendarFor(settings); err != nil {
+		return fmt.Errorf("INVALID_SLA: %v", err)
+	}
+
+	return s.storage.SetTeamSLA(settings)
+}
+
+func calendarFor(settings *models.TeamSLA) (*sla.Calendar, error) {
+	return sla.NewCalendar(settings.TimeZone, settings.WorkdayStart, settings.WorkdayEnd,
+		settings.WeekendDays, settings.Holidays)
+}
+
+func (s *PRService) ListSLABreaches(teamName string, openOnly bool) ([]models.SLABreach, error) {
+	return s.storage.ListSLABreaches(teamName, openOnly)
+}
+
+// CheckSLA находит назначения, превысившие срок первого ревью в рабочем времени команды,
+// фиксирует нарушения и, если команда это включила, переназначает ревьювера с причиной SLA_BREACH.
+func (s *PRService) CheckSLA(ctx context.Context) error {
+	settings, err := s.storage.ListTeamSLAs()
+	if err != nil {
+		return err
+	}
+	if len(settings) == 0 {
+		return nil
+	}
+
+	type teamRule struct {
+		settings models.TeamSLA
+		calendar *sla.Calendar
+	}
+	rules := make(map[string]teamRule, len(settings))
+	for _, st := range settings {
+		cal, err := calendarFor(&st)
+		if err != nil {
+			log.Printf("sla: team %s has invalid settings: %v", st.TeamName, err)
+			continue
+		}
+		rules[st.TeamName] = teamRule{settings: st, calendar: cal}
+	}
+
+	assignments, err := s.storage.ListOpenAssignments()
+	if err != nil {
+		return err
+	}
+
+	ctx = audit.WithActor(ctx, slaActor)
+	now := time.Now()
+	for _, a := range assignments {
+		rule, ok := rules[a.TeamName]
+		if !ok {
+			continue
+		}
+
+		limit := time.Duration(rule.settings.FirstReviewMinutes) * time.Minute
+		if rule.calendar.BusinessDuration(a.AssignedAt, now) < limit {
+			continue
+		}
+
+		recorded, err := s.storage.RecordSLABreach(a, slaActor)
+		if err != nil {
+			return err
+		}
+		if !recorded {
+			continue
+		}
+		log.Printf("sla: escalation: reviewer %s exceeded SLA on PR %s (team %s, assigned %s)",
+			a.ReviewerID, a.PullRequestID, a.TeamName, a.AssignedAt.Format(time.RFC3339))
+
+		if rule.settings.AutoReassign {
+			_, newReviewer, err := s.reassignReviewer(ctx, a.PullRequestID, a.ReviewerID, models.ReasonSLABreach)
+			if err != nil {
+				log.Printf("sla: auto-reassign of %s on PR %s failed: %v", a.ReviewerID, a.PullRequestID, err)
+				continue
+			}
+			log.Printf("sla: reassigned PR %s from %s to %s", a.PullRequestID, a.ReviewerID, newReviewer)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"pr-reviewer-service/internal/models"
+	"time"
+)
+
+// DefaultHistoryWindow - окно /stats/history, если from не задан: восемь недель для сравнения неделя к неделе
+const DefaultHistoryWindow = 8 * 7 * 24 * time.Hour
+
+var historyBuckets = map[string]bool{"hour": true, "day": true, "week": true}
+
+// SnapshotStats сохраняет снимок метрик и применяет хранение: снимки старше rawRetention
+// прореживаются до одного в сутки, старше retention удаляются. Нулевые значения отключают очистку.
+func (s *PRService) SnapshotStats(ctx context.Context, rawRetention, retention time.Duration) error {
+	now := time.Now()
+	if err := s.storage.TakeStatsSnapshot(now); err != nil {
+		return err
+	}
+
+	if rawRetention <= 0 && retention <= 0 {
+		return nil
+	}
+	rawBefore, deleteBefore := time.Time{}, time.Time{}
+	if rawRetention > 0 {
+		rawBefore = now.Add(-rawRetention)
+	}
+	if retention > 0 {
+		deleteBefore = now.Add(-retention)
+	}
+
+	n, err := s.storage.PruneStatsSnapshots(rawBefore, deleteBefore)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Printf("stats snapshots: pruned %d rows", n)
+	}
+	return nil
+}
+
+// GetStatsHistory возвращает временной ряд метрик области scope по интервалам bucket
+func (s *PRService) GetStatsHistory(scope, subject, bucket string, from, to time.Time) (*models.StatsSeries, error) {
+	switch scope {
+	case models.ScopeGlobal:
+		subject = ""
+	case models.ScopeTeam:
+		if _, err := s.storage.GetTeamSummary(subject); err != nil {
+			return nil, err
+		}
+	case models.ScopeUser:
+		if _, err := s.storage.GetUser(subject); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("INVALID_HISTORY: scope must be global, team or user")
+	}
+	if !historyBuckets[bucket] {
+		return nil, fmt.Errorf("INVALID_HISTORY: bucket must be hour, day or week")
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("INVALID_HISTORY: from must be before to")
+	}
+
+	snapshots, base, err := s.storage.ListStatsSnapshots(scope, subject, bucket, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	series := &models.StatsSeries{
+		Scope:   scope,
+		Subject: subject,
+		Bucket:  bucket,
+		From:    from,
+		To:      to,
+		Points:  make([]models.StatsPoint, 0, len(snapshots)),
+	}
+	// Без предыдущего снимка прирост первой точки неизвестен и считается нулевым
+	prev := base
+	for i := range snapshots {
+		snap := &snapshots[i]
+		point := models.StatsPoint{
+			Time:        snap.TakenAt,
+			OpenPRs:     snap.OpenPRs,
+			OpenReviews: snap.OpenReviews,
+		}
+		if prev != nil {
+			point.Assignments = nonNegative(snap.AssignmentsTotal - prev.AssignmentsTotal)
+			point.Merges = nonNegative(snap.MergesTotal - prev.MergesTotal)
+		}
+		series.Points = append(series.Points, point)
+		prev = snap
+	}
+	return series, nil
+}
+
+// Накопленные счётчики могут уменьшиться при удалении данных
+func nonNegative(n int) int {
+	if n < 0 {
+		return 0
+	}
+	return n
+}
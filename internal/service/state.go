@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"pr-reviewer-service/internal/models"
+	"pr-reviewer-service/internal/storage"
+	"time"
+)
+
+const (
+	ActionStateRestore = "state.restore"
+
+	StateArchiveFormat  = "pr-reviewer-state"
+	StateArchiveVersion = 1
+)
+
+// ExportState собирает архив всего состояния сервиса с контрольной суммой
+func (s *PRService) ExportState(ctx context.Context) (*models.StateArchive, error) {
+	data, err := s.storage.DumpState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sum, err := stateChecksum(data)
+	if err != nil {
+		return nil, err
+	}
+	return &models.StateArchive{
+		Format:        StateArchiveFormat,
+		Version:       StateArchiveVersion,
+		SchemaVersion: storage.SchemaVersion,
+		CreatedAt:     time.Now().UTC(),
+		Counts:        data.Counts(),
+		Checksum:      sum,
+		Data:          *data,
+	}, nil
+}
+
+// ImportState проверяет архив и восстанавливает его в пустую БД.
+// При dryRun выполняются только проверки.
+func (s *PRService) ImportState(ctx context.Context, archive *models.StateArchive, dryRun bool) (*models.StateRestoreReport, error) {
+	if err := verifyStateArchive(archive); err != nil {
+		return nil, err
+	}
+
+	report := &models.StateRestoreReport{
+		DryRun:        dryRun,
+		SchemaVersion: archive.SchemaVersion,
+		Counts:        archive.Counts,
+	}
+	if dryRun {
+		return report, nil
+	}
+
+	report.Restored = true
+	if err := s.storage.RestoreState(ctx, &archive.Data, auditEntry(ctx, ActionStateRestore, "state", archive.Checksum, nil, report)); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func stateChecksum(data *models.StateData) (string, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// verifyStateArchive проверяет формат, версию схемы, контрольную сумму и ссылочную целостность архива
+func verifyStateArchive(archive *models.StateArchive) error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("INVALID_ARCHIVE: "+format, args...)
+	}
+
+	if archive.Format != StateArchiveFormat {
+		return invalid("format must be %q", StateArchiveFormat)
+	}
+	if archive.Version != StateArchiveVersion {
+		return invalid("unsupported archive version %d", archive.Version)
+	}
+	if archive.SchemaVersion <= 0 || archive.SchemaVersion > storage.SchemaVersion {
+		return invalid("schema version %d is not supported, server schema is %d", archive.SchemaVersion, storage.SchemaVersion)
+	}
+
+	sum, err := stateChecksum(&archive.Data)
+	if err != nil {
+		return err
+	}
+	if sum != archive.Checksum {
+		return invalid("checksum mismatch")
+	}
+	for name, n := range archive.Data.Counts() {
+		if archive.Counts[name] != n {
+			return invalid("%s: expected %d records, found %d", name, archive.Counts[name], n)
+		}
+	}
+
+	data := &archive.Data
+	teams := make(map[string]bool, len(data.Teams))
+	for _, team := range data.Teams {
+		if team == "" || teams[team] {
+			return invalid("teams: empty or duplicate team %q", team)
+		}
+		teams[team] = true
+	}
+	users := make(map[string]bool, len(data.Users))
+	for _, u := range data.Users {
+		if u.UserID == "" || users[u.UserID] {
+			return invalid("users: empty or duplicate user %q", u.UserID)
+		}
+		if !teams[u.TeamName] {
+			return invalid("users: %s references unknown team %q", u.UserID, u.TeamName)
+		}
+		users[u.UserID] = true
+	}
+	for _, c := range data.StatusChanges {
+		if !users[c.UserID] {
+			return invalid("status_changes: unknown user %q", c.UserID)
+		}
+	}
+	prs := make(map[string]bool, len(data.PullRequests))
+	for _, pr := range data.PullRequests {
+		if pr.PullRequestID == "" || prs[pr.PullRequestID] {
+			return invalid("pull_requests: empty or duplicate pull request %q", pr.PullRequestID)
+		}
+		if !users[pr.AuthorID] {
+			return invalid("pull_requests: %s references unknown author %q", pr.PullRequestID, pr.AuthorID)
+		}
+		for _, reviewer := range pr.AssignedReviewers {
+			if !users[reviewer] {
+				return invalid("pull_requests: %s references unknown reviewer %q", pr.PullRequestID, reviewer)
+			}
+		}
+		if pr.CreatedAt == nil {
+			return invalid("pull_requests: %s has no created_at", pr.PullRequestID)
+		}
+		prs[pr.PullRequestID] = true
+	}
+	for _, e := range data.PREvents {
+		if !prs[e.PullRequestID] {
+			return invalid("pr_events: event %d references unknown pull request %q", e.ID, e.PullRequestID)
+		}
+	}
+	for _, sla := range data.TeamSLAs {
+		if !teams[sla.TeamName] {
+			return invalid("team_slas: unknown team %q", sla.TeamName)
+		}
+	}
+	for _, b := range data.SLABreaches {
+		if !prs[b.PullRequestID] {
+			return invalid("sla_breaches: breach %d references unknown pull request %q", b.ID, b.PullRequestID)
+		}
+	}
+	for _, r := range data.ReminderSettings {
+		if !users[r.UserID] {
+			return invalid("reminder_settings: unknown user %q", r.UserID)
+		}
+	}
+	for _, c := range data.ChatIdentities {
+		if !users[c.UserID] {
+			return invalid("chat_identities: %s references unknown user %q", c.ChatUserID, c.UserID)
+		}
+	}
+	for _, key := range data.APIKeys {
+		if key.KeyHash == "" {
+			return invalid("api_keys: %s has no key_hash", key.Name)
+		}
+		if key.UserID != "" && !users[key.UserID] {
+			return invalid("api_keys: %s references unknown user %q", key.Name, key.UserID)
+		}
+		if key.TeamName != "" && !teams[key.TeamName] {
+			return invalid("api_keys: %s references unknown team %q", key.Name, key.TeamName)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package sla
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Calendar описывает рабочее время команды: часовой пояс, рабочие часы, выходные и праздники
+type Calendar struct {
+	Location  *time.Location
+	WorkStart time.Duration
+	WorkEnd   time.Duration
+	Weekend   map[time.Weekday]bool
+	Holidays  map[string]bool
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// NewCalendar проверяет настройки и собирает календарь.
+// workdayStart и workdayEnd задаются как "HH:MM", holidays - как "YYYY-MM-DD".
+func NewCalendar(timeZone, workdayStart, workdayEnd string, weekend, holidays []string) (*Calendar, error) {
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return nil, fmt.Errorf("time_zone: unknown time zone %q", timeZone)
+	}
+
+	start, err := parseClock(workdayStart)
+	if err != nil {
+		return nil, fmt.Errorf("workday_start: %w", err)
+	}
+	end, err := parseClock(workdayEnd)
+	if err != nil {
+		return nil, fmt.Errorf("workday_end: %w", err)
+	}
+	if end <= start {
+		return nil, fmt.Errorf("workday_end must be after workday_start")
+	}
+
+	cal := &Calendar{
+		Location:  loc,
+		WorkStart: start,
+		WorkEnd:   end,
+		Weekend:   make(map[time.Weekday]bool),
+		Holidays:  make(map[string]bool),
+	}
+	for _, day := range weekend {
+		wd, ok := weekdays[strings.ToLower(day)]
+		if !ok {
+			return nil, fmt.Errorf("weekend_days: unknown day %q", day)
+		}
+		cal.Weekend[wd] = true
+	}
+	if len(cal.Weekend) == 7 {
+		return nil, fmt.Errorf("weekend_days: at least one working day is required")
+	}
+	for _, day := range holidays {
+		if _, err := time.Parse("2006-01-02", day); err != nil {
+			return nil, fmt.Errorf("holidays: %q is not a YYYY-MM-DD date", day)
+		}
+		cal.Holidays[day] = true
+	}
+
+	return cal, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not HH:MM", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func (c *Calendar) isWorkingDay(day time.Time) bool {
+	return !c.Weekend[day.Weekday()] && !c.Holidays[day.Format("2006-01-02")]
+}
+
+// BusinessDuration считает рабочее время между from и to в часовом поясе календаря
+func (c *Calendar) BusinessDuration(from, to time.Time) time.Duration {
+	if !to.After(from) {
+		return 0
+	}
+	from = from.In(c.Location)
+	to = to.In(c.Location)
+
+	var total time.Duration
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, c.Location)
+	for !day.After(to) {
+		if c.isWorkingDay(day) {
+			start := c.at(day, c.WorkStart)
+			end := c.at(day, c.WorkEnd)
+			if start.Before(from) {
+				start = from
+			}
+			if end.After(to) {
+				end = to
+			}
+			if end.After(start) {
+				total += end.Sub(start)
+			}
+		}
+		day = time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, c.Location)
+	}
+	return total
+}
+
+// at возвращает момент дня по часам, а не по смещению от полуночи, чтобы переход на летнее время не сдвигал рабочие часы
+func (c *Calendar) at(day time.Time, clock time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(),
+		int(clock/time.Hour), int(clock%time.Hour/time.Minute), 0, 0, c.Location)
+}
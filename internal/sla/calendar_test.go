@@ -0,0 +1,119 @@
+package sla
+
+import (
+	"testing"
+	"time"
+)
+
+func mustCalendar(t *testing.T, timeZone, start, end string, weekend, holidays []string) *Calendar {
+	t.Helper()
+	cal, err := NewCalendar(timeZone, start, end, weekend, holidays)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cal
+}
+
+func TestBusinessDuration(t *testing.T) {
+	weekend := []string{"saturday", "sunday"}
+	utc := mustCalendar(t, "UTC", "09:00", "18:00", weekend, []string{"2025-03-05"})
+	moscow := mustCalendar(t, "Europe/Moscow", "10:00", "19:00", weekend, nil)
+	berlin := mustCalendar(t, "Europe/Berlin", "09:00", "17:00", nil, nil)
+
+	// 2025-03-03 - понедельник, 2025-03-05 - праздник в utc
+	at := func(loc string, year int, month time.Month, day, hour, min int) time.Time {
+		l, err := time.LoadLocation(loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return time.Date(year, month, day, hour, min, 0, 0, l)
+	}
+	u := func(day, hour, min int) time.Time { return at("UTC", 2025, time.March, day, hour, min) }
+
+	tests := []struct {
+		name     string
+		cal      *Calendar
+		from, to time.Time
+		want     time.Duration
+	}{
+		{"same working day", utc, u(3, 10, 0), u(3, 12, 30), 150 * time.Minute},
+		{"to before from", utc, u(3, 12, 0), u(3, 10, 0), 0},
+		{"equal", utc, u(3, 12, 0), u(3, 12, 0), 0},
+		{"from before working hours", utc, u(3, 6, 0), u(3, 10, 0), time.Hour},
+		{"to after working hours", utc, u(3, 17, 0), u(3, 23, 0), time.Hour},
+		{"both outside working hours", utc, u(3, 19, 0), u(4, 8, 0), 0},
+		{"overnight", utc, u(3, 17, 0), u(4, 10, 0), 2 * time.Hour},
+		{"whole working day", utc, u(4, 0, 0), u(5, 0, 0), 9 * time.Hour},
+		{"holiday", utc, u(5, 9, 0), u(5, 18, 0), 0},
+		{"across a holiday", utc, u(4, 17, 0), u(6, 10, 0), 2 * time.Hour},
+		{"weekend", utc, u(8, 9, 0), u(9, 23, 0), 0},
+		{"friday to monday", utc, u(7, 16, 0), u(10, 11, 0), 4 * time.Hour},
+		{"started on weekend", utc, u(8, 12, 0), u(10, 10, 30), 90 * time.Minute},
+		{"full week", utc, u(3, 0, 0), u(10, 0, 0), 4 * 9 * time.Hour},
+		// 09:00 UTC - 12:00 в Москве, рабочий день там с 10:00
+		{"non-UTC zone", moscow, u(3, 6, 0), u(3, 9, 0), 2 * time.Hour},
+		{"non-UTC zone across midnight", moscow, u(3, 15, 0), u(3, 22, 0), time.Hour},
+		{"non-UTC weekend starts on friday evening UTC", moscow, u(7, 21, 30), u(9, 21, 30), 0},
+		// 30 марта 2025 в Берлине часы переводятся с 02:00 на 03:00: сутки длятся 23 часа, рабочий день - те же 8 часов
+		{"DST spring forward", berlin, at("Europe/Berlin", 2025, time.March, 30, 0, 0), at("Europe/Berlin", 2025, time.March, 31, 0, 0), 8 * time.Hour},
+		{"DST autumn back", berlin, at("Europe/Berlin", 2025, time.October, 26, 0, 0), at("Europe/Berlin", 2025, time.October, 27, 0, 0), 8 * time.Hour},
+		{"DST boundary in UTC", berlin, time.Date(2025, time.March, 30, 7, 0, 0, 0, time.UTC), time.Date(2025, time.March, 30, 8, 0, 0, 0, time.UTC), time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cal.BusinessDuration(tt.from, tt.to); got != tt.want {
+				t.Errorf("BusinessDuration(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalendarAt(t *testing.T) {
+	berlin := mustCalendar(t, "Europe/Berlin", "09:00", "17:30", nil, nil)
+	tests := []struct {
+		name  string
+		day   time.Time
+		clock time.Duration
+		want  string
+	}{
+		{"winter", time.Date(2025, time.March, 29, 0, 0, 0, 0, berlin.Location), berlin.WorkStart, "2025-03-29T09:00:00+01:00"},
+		{"spring forward day", time.Date(2025, time.March, 30, 0, 0, 0, 0, berlin.Location), berlin.WorkStart, "2025-03-30T09:00:00+02:00"},
+		{"autumn back day", time.Date(2025, time.October, 26, 0, 0, 0, 0, berlin.Location), berlin.WorkEnd, "2025-10-26T17:30:00+01:00"},
+		// Время внутри пропущенного часа переносится вперёд, как в time.Date
+		{"skipped hour", time.Date(2025, time.March, 30, 0, 0, 0, 0, berlin.Location), 2*time.Hour + 30*time.Minute, "2025-03-30T03:30:00+02:00"},
+		// День в другом поясе берётся по его дате, а не по моменту времени
+		{"day from another zone", time.Date(2025, time.March, 3, 23, 0, 0, 0, time.UTC), berlin.WorkStart, "2025-03-03T09:00:00+01:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := berlin.at(tt.day, tt.clock).Format(time.RFC3339); got != tt.want {
+				t.Errorf("at(%s, %v) = %s, want %s", tt.day, tt.clock, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCalendarErrors(t *testing.T) {
+	tests := []struct {
+		name, zone, start, end string
+		weekend, holidays      []string
+		want                   string
+	}{
+		{"zone", "Mars/Olympus", "09:00", "18:00", nil, nil, `time_zone: unknown time zone "Mars/Olympus"`},
+		{"start", "UTC", "9am", "18:00", nil, nil, `workday_start: "9am" is not HH:MM`},
+		{"end before start", "UTC", "18:00", "09:00", nil, nil, "workday_end must be after workday_start"},
+		{"weekend day", "UTC", "09:00", "18:00", []string{"funday"}, nil, `weekend_days: unknown day "funday"`},
+		{"no working days", "UTC", "09:00", "18:00",
+			[]string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "Sunday"}, nil,
+			"weekend_days: at least one working day is required"},
+		{"holiday", "UTC", "09:00", "18:00", nil, []string{"05.03.2025"}, `holidays: "05.03.2025" is not a YYYY-MM-DD date`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCalendar(tt.zone, tt.start, tt.end, tt.weekend, tt.holidays)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("NewCalendar() = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
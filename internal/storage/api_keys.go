@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"pr-reviewer-service/internal/models"
+	"time"
+)
+
+func (s *PostgresStorage) CreateAPIKey(key *models.APIKey, keyHash string) error {
+	var exists bool
+	err := s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM api_keys WHERE name = $1)", key.Name).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("KEY_EXISTS")
+	}
+
+	return s.db.QueryRow(`
+		INSERT INTO api_keys (name, key_hash, prefix, role, user_id, team_name)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''))
+		RETURNING id, created_at
+	`, key.Name, keyHash, key.Prefix, key.Role, key.UserID, key.TeamName).Scan(&key.ID, &key.CreatedAt)
+}
+
+const apiKeyColumns = `id, name, prefix, role, COALESCE(user_id, ''), COALESCE(team_name, ''),
+		       created_at, last_used_at, revoked_at`
+
+func scanAPIKey(row interface{ Scan(...interface{}) error }) (*models.APIKey, error) {
+	var key models.APIKey
+	var lastUsedAt, revokedAt sql.NullTime
+	err := row.Scan(&key.ID, &key.Name, &key.Prefix, &key.Role, &key.UserID, &key.TeamName,
+		&key.CreatedAt, &lastUsedAt, &revokedAt)
+	if err != nil {
+		return nil, err
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	return &key, nil
+}
+
+// lastUsedPrecision - как часто обновляется last_used_at: без этого каждый запрос
+// с ключом был бы записью в одну и ту же строку api_keys
+const lastUsedPrecision = time.Minute
+
+// GetAPIKeyByHash заодно обновляет last_used_at, если он старше lastUsedPrecision
+func (s *PostgresStorage) GetAPIKeyByHash(keyHash string) (*models.APIKey, error) {
+	key, err := scanAPIKey(s.db.QueryRow(`SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1`, keyHash))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("NOT_FOUND")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if key.LastUsedAt != nil && now.Sub(*key.LastUsedAt) < lastUsedPrecision {
+		return key, nil
+	}
+	// Условие повторяется в UPDATE: параллельные запросы с тем же ключом не перезаписывают друг друга
+	_, err = s.db.Exec(`
+		UPDATE api_keys SET last_used_at = $2
+		WHERE key_hash = $1 AND (last_used_at IS NULL OR last_used_at < $3)
+	`, keyHash, now, now.Add(-lastUsedPrecision))
+	if err != nil {
+		return nil, err
+	}
+	key.LastUsedAt = &now
+	return key, nil
+}
+
+func (s *PostgresStorage) ListAPIKeys() ([]models.APIKey, error) {
+	rows, err := s.db.Query(`SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+	return keys, rows.Err()
+}
+
+func (s *PostgresStorage) RevokeAPIKey(name string) (*models.APIKey, error) {
+	key, err := scanAPIKey(s.db.QueryRow(`
+		UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $2)
+		WHERE name = $1
+		RETURNING `+apiKeyColumns, name, time.Now()))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("NOT_FOUND")
+	}
+	return key, err
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"pr-reviewer-service/internal/models"
+	"strings"
+)
+
+// insertAudit пишет запись аудита в транзакции изменения: если запись не удалась, изменение тоже не сохраняется
+func insertAudit(tx *sql.Tx, entry *models.AuditEntry) error {
+	return tx.QueryRow(`
+		INSERT INTO audit_log (actor, action, target_type, target_id, request_id, before, after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`, entry.Actor, entry.Action, entry.TargetType, entry.TargetID, entry.RequestID,
+		nullJSON(entry.Before), nullJSON(entry.After),
+	).Scan(&entry.ID, &entry.CreatedAt)
+}
+
+func nullJSON(data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
+
+func auditWhere(filter models.AuditFilter) (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.Actor != "" {
+		add("actor = $%d", filter.Actor)
+	}
+	if filter.Action != "" {
+		add("action = $%d", filter.Action)
+	}
+	if filter.TargetType != "" {
+		add("target_type = $%d", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		add("target_id = $%d", filter.TargetID)
+	}
+	if filter.RequestID != "" {
+		add("request_id = $%d", filter.RequestID)
+	}
+	if filter.From != nil {
+		add("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		add("created_at < $%d", *filter.To)
+	}
+	if filter.BeforeID > 0 {
+		add("id < $%d", filter.BeforeID)
+	}
+
+	if len(conds) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conds, " AND "), args
+}
+
+// StreamAudit вызывает fn для каждой подходящей записи, начиная с самых новых, не загружая всё в память
+func (s *PostgresStorage) StreamAudit(filter models.AuditFilter, fn func(*models.AuditEntry) error) error {
+	where, args := auditWhere(filter)
+	query := `
+		SELECT id, created_at, actor, action, target_type, target_id, request_id,
+		       COALESCE(before::text, ''), COALESCE(after::text, '')
+		FROM audit_log ` + where + `
+		ORDER BY id DESC`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry models.AuditEntry
+		var before, after string
+		if err := rows.Scan(&entry.ID, &entry.CreatedAt, &entry.Actor, &entry.Action,
+			&entry.TargetType, &entry.TargetID, &entry.RequestID, &before, &after); err != nil {
+			return err
+		}
+		if before != "" {
+			entry.Before = []byte(before)
+		}
+		if after != "" {
+			entry.After = []byte(after)
+		}
+		if err := fn(&entry); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (s *PostgresStorage) ListAudit(filter models.AuditFilter) ([]models.AuditEntry, error) {
+	entries := []models.AuditEntry{}
+	err := s.StreamAudit(filter, func(entry *models.AuditEntry) error {
+		entries = append(entries, *entry)
+		return nil
+	})
+	return entries, err
+}
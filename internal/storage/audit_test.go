@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"database/sql/driver"
+	"errors"
+	"pr-reviewer-service/internal/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+// auditRows - ответы recorder на запросы с RETURNING, которые выполняются в изменениях с аудитом
+func auditRows(r *recorder) {
+	now := time.Now()
+	r.rows["SELECT EXISTS"] = []driver.Value{false}
+	r.rows["UPDATE users"] = []driver.Value{"u1", "Alice", "backend", false}
+	r.rows["RETURNING xmax"] = []driver.Value{true}
+	r.rows["INSERT INTO pr_events"] = []driver.Value{int64(1)}
+	r.rows["INSERT INTO audit_log"] = []driver.Value{int64(42), now}
+}
+
+// index возвращает позицию первого запроса, начинающегося с prefix, или -1
+func index(log []string, prefix string) int {
+	for i, q := range log {
+		if strings.HasPrefix(q, prefix) {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestMutationsWriteAuditInTransaction(t *testing.T) {
+	now := time.Now()
+	pr := func() *models.PullRequest {
+		return &models.PullRequest{PullRequestID: "pr-1", PullRequestName: "Add search", AuthorID: "u1", Status: "OPEN",
+			AssignedReviewers: []string{"u2"}, CreatedAt: &now}
+	}
+	tests := []struct {
+		name   string
+		mutate func(s *PostgresStorage, entry *models.AuditEntry) error
+	}{
+		{"create team", func(s *PostgresStorage, entry *models.AuditEntry) error {
+			return s.CreateTeam(&models.Team{TeamName: "backend", Members: []models.TeamMember{{UserID: "u1", Username: "Alice"}}}, entry)
+		}},
+		{"set user active", func(s *PostgresStorage, entry *models.AuditEntry) error {
+			_, err := s.SetUserActive("u1", false, entry)
+			return err
+		}},
+		{"create PR", func(s *PostgresStorage, entry *models.AuditEntry) error {
+			return s.CreatePR(pr(), "ops", entry)
+		}},
+		{"merge PR", func(s *PostgresStorage, entry *models.AuditEntry) error {
+			return s.MergePR("pr-1", "ops", now, entry)
+		}},
+		{"reassign", func(s *PostgresStorage, entry *models.AuditEntry) error {
+			return s.ReplacePRReviewer("pr-1", []string{"u2"}, []string{"u3"}, "u2", "u3", models.ReasonManualReassign, "ops", entry)
+		}},
+		{"import", func(s *PostgresStorage, entry *models.AuditEntry) error {
+			_, err := s.ApplyImport([]string{"backend"}, []models.User{{UserID: "u1", Username: "Alice", TeamName: "backend"}},
+				[]models.PullRequest{*pr()}, "ops", entry)
+			return err
+		}},
+		{"batch", func(s *PostgresStorage, entry *models.AuditEntry) error {
+			_, err := s.ApplyPRBatch([]models.PRBatchMutation{
+				{Op: models.BatchOpCreate, PR: pr(), At: now, Audit: &models.AuditEntry{Actor: "ops", Action: "pr.create"}},
+				{Op: models.BatchOpMerge, PR: pr(), At: now, Audit: entry},
+			}, "ops")
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, r := newRecordingStorage(t)
+			auditRows(r)
+			entry := &models.AuditEntry{Actor: "ops", Action: "test"}
+			if err := tt.mutate(s, entry); err != nil {
+				t.Fatal(err)
+			}
+			log := r.log()
+			audit, commit := index(log, "INSERT INTO audit_log"), index(log, "COMMIT")
+			if audit < 0 || commit < audit || index(log, "ROLLBACK") >= 0 {
+				t.Fatalf("audit is not written before COMMIT:\n%s", strings.Join(log, "\n"))
+			}
+			if entry.ID != 42 || entry.CreatedAt.IsZero() {
+				t.Errorf("entry = %+v, want id and created_at from the insert", entry)
+			}
+		})
+
+		// Изменение без записи аудита не сохраняется
+		t.Run(tt.name+" audit failure", func(t *testing.T) {
+			s, r := newRecordingStorage(t)
+			auditRows(r)
+			r.fail["INSERT INTO audit_log"] = errors.New("pq: permission denied for table audit_log")
+			if err := tt.mutate(s, &models.AuditEntry{Actor: "ops", Action: "test"}); err == nil || !strings.Contains(err.Error(), "audit_log") {
+				t.Fatalf("err = %v, want the audit insert error", err)
+			}
+			log := r.log()
+			if index(log, "COMMIT") >= 0 || index(log, "ROLLBACK") < 0 {
+				t.Errorf("transaction is not rolled back:\n%s", strings.Join(log, "\n"))
+			}
+		})
+	}
+}
+
+func TestMergeMergedPRWritesNoAudit(t *testing.T) {
+	s, r := newRecordingStorage(t)
+	auditRows(r)
+	r.affected = 0
+
+	if err := s.MergePR("pr-1", "ops", time.Now(), &models.AuditEntry{Actor: "ops", Action: "pr.merge"}); err != nil {
+		t.Fatal(err)
+	}
+	if log := r.log(); index(log, "INSERT INTO audit_log") >= 0 || index(log, "INSERT INTO pr_events") >= 0 {
+		t.Errorf("repeated merge is recorded:\n%s", strings.Join(log, "\n"))
+	}
+}
+
+func TestImportAuditContainsReport(t *testing.T) {
+	s, r := newRecordingStorage(t)
+	auditRows(r)
+	entry := &models.AuditEntry{Actor: "ops", Action: "import"}
+
+	report, err := s.ApplyImport([]string{"backend"}, []models.User{{UserID: "u1", Username: "Alice", TeamName: "backend"}}, nil, "ops", entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.NewTeams != 1 || report.NewUsers != 1 || !report.Applied {
+		t.Errorf("report = %+v", report)
+	}
+	if want := `{"dry_run":false,"applied":true,"new_teams":1,`; !strings.HasPrefix(string(entry.After), want) {
+		t.Errorf("after = %s, want the report", entry.After)
+	}
+}
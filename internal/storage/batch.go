@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"fmt"
+	"pr-reviewer-service/internal/models"
+)
+
+// ApplyPRBatch записывает проверенные изменения пакета PR вместе с их записями аудита в одной транзакции.
+// При ошибке ничего не записывается, а failed - номер изменения, на котором она произошла (-1 - вне изменений).
+// Замена ревьювера, PR которой изменили после проверки, завершается ошибкой PR_MODIFIED.
+func (s *PostgresStorage) ApplyPRBatch(mutations []models.PRBatchMutation, actor string) (failed int, err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return -1, err
+	}
+	defer tx.Rollback()
+
+	for i, m := range mutations {
+		// Слияние PR, который уже слили, ничего не меняет, и записи аудита для него нет
+		changed := true
+		switch m.Op {
+		case models.BatchOpCreate:
+			err = insertPR(tx, m.PR, actor)
+		case models.BatchOpMerge:
+			changed, err = mergePR(tx, m.PR.PullRequestID, actor, m.At)
+		case models.BatchOpReassign:
+			err = replacePRReviewer(tx, m.PR.PullRequestID, m.PreviousReviewers, m.PR.AssignedReviewers, m.OldReviewer, m.NewReviewer,
+				models.ReasonManualReassign, actor, m.At)
+		default:
+			err = fmt.Errorf("unknown batch operation %q", m.Op)
+		}
+		if err == nil && changed {
+			err = insertAudit(tx, m.Audit)
+		}
+		if err != nil {
+			return i, err
+		}
+	}
+
+	return -1, tx.Commit()
+}
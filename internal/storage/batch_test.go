@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"pr-reviewer-service/internal/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func TestApplyPRBatchConcurrentCreate(t *testing.T) {
+	s, r := newRecordingStorage(t)
+	auditRows(r)
+	// PR с тем же id создали параллельно, уже после проверки пакета
+	r.fail["INSERT INTO pull_requests"] = &pq.Error{Code: uniqueViolation, Message: `duplicate key value violates unique constraint "pull_requests_pkey"`}
+
+	now := time.Now()
+	failed, err := s.ApplyPRBatch([]models.PRBatchMutation{
+		{Op: models.BatchOpMerge, PR: &models.PullRequest{PullRequestID: "pr-1"}, At: now, Audit: &models.AuditEntry{}},
+		{Op: models.BatchOpCreate, PR: &models.PullRequest{PullRequestID: "pr-2", Status: "OPEN", CreatedAt: &now}, At: now, Audit: &models.AuditEntry{}},
+	}, "ops")
+	if failed != 1 || err == nil || err.Error() != "PR_EXISTS" {
+		t.Fatalf("ApplyPRBatch() = %d, %v, want 1, PR_EXISTS", failed, err)
+	}
+	if log := r.log(); index(log, "COMMIT") >= 0 || index(log, "ROLLBACK") < 0 {
+		t.Errorf("transaction is not rolled back:\n%s", strings.Join(log, "\n"))
+	}
+}
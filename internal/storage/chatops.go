@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"pr-reviewer-service/internal/models"
+)
+
+func (s *PostgresStorage) LinkChatIdentity(chatUserID, userID string) error {
+	_, err := s.db.Exec(`
+		INSERT INTO chat_identities (chat_user_id, user_id)
+		VALUES ($1, $2)
+		ON CONFLICT (chat_user_id) DO UPDATE SET user_id = EXCLUDED.user_id
+	`, chatUserID, userID)
+	return err
+}
+
+// ResolveChatUser возвращает user_id по идентификатору в чате.
+// Если связи нет, идентификатор считается user_id сервиса.
+func (s *PostgresStorage) ResolveChatUser(chatUserID string) (string, error) {
+	var userID string
+	err := s.db.QueryRow(`
+		SELECT user_id FROM (
+			SELECT user_id, 0 AS priority FROM chat_identities WHERE chat_user_id = $1
+			UNION ALL
+			SELECT user_id, 1 AS priority FROM users WHERE user_id = $1
+		) candidates
+		ORDER BY priority
+		LIMIT 1
+	`, chatUserID).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("NOT_FOUND")
+	}
+	return userID, err
+}
+
+func (s *PostgresStorage) GetTeamSummary(teamName string) (*models.TeamSummary, error) {
+	var team models.TeamSummary
+	err := s.db.QueryRow(`
+		SELECT t.team_name,
+		       (SELECT COUNT(*) FROM users u WHERE u.team_name = t.team_name),
+		       (SELECT COUNT(*) FROM users u WHERE u.team_name = t.team_name AND u.is_active),
+		       (SELECT COUNT(*)
+		        FROM pull_requests p
+		        JOIN users a ON a.user_id = p.author_id
+		        WHERE a.team_name = t.team_name AND p.status = 'OPEN')
+		FROM teams t
+		WHERE t.team_name = $1
+	`, teamName).Scan(&team.TeamName, &team.MemberCount, &team.ActiveCount, &team.OpenPRCount)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("NOT_FOUND")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// GetTeamReviewLoad возвращает число открытых PR на ревью у каждого участника команды
+func (s *PostgresStorage) GetTeamReviewLoad(teamName string) ([]models.ReviewerLoad, error) {
+	rows, err := s.db.Query(`
+		SELECT u.user_id, u.is_active,
+		       (SELECT COUNT(*) FROM pull_requests p
+		        WHERE p.status = 'OPEN' AND p.assigned_reviewers ? u.user_id)
+		FROM users u
+		WHERE u.team_name = $1
+		ORDER BY 3 DESC, u.user_id
+	`, teamName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	load := []models.ReviewerLoad{}
+	for rows.Next() {
+		var l models.ReviewerLoad
+		if err := rows.Scan(&l.UserID, &l.IsActive, &l.OpenReviews); err != nil {
+			return nil, err
+		}
+		load = append(load, l)
+	}
+	return load, rows.Err()
+}
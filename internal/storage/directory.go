@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"fmt"
+	"pr-reviewer-service/internal/models"
+	"strings"
+)
+
+// ListTeams возвращает до limit+1 команд с именем больше afterName вместе со счётчиками
+func (s *PostgresStorage) ListTeams(afterName string, limit int) ([]models.TeamSummary, error) {
+	rows, err := s.db.Query(`
+		SELECT t.team_name,
+		       COUNT(u.user_id),
+		       COUNT(u.user_id) FILTER (WHERE u.is_active),
+		       COALESCE((
+		           SELECT COUNT(*)
+		           FROM pull_requests p
+		           JOIN users a ON a.user_id = p.author_id
+		           WHERE a.team_name = t.team_name AND p.status = 'OPEN'
+		       ), 0)
+		FROM teams t
+		LEFT JOIN users u ON u.team_name = t.team_name
+		WHERE t.team_name > $1
+		GROUP BY t.team_name
+		ORDER BY t.team_name
+		LIMIT $2
+	`, afterName, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	teams := []models.TeamSummary{}
+	for rows.Next() {
+		var team models.TeamSummary
+		if err := rows.Scan(&team.TeamName, &team.MemberCount, &team.ActiveCount, &team.OpenPRCount); err != nil {
+			return nil, err
+		}
+		teams = append(teams, team)
+	}
+	return teams, rows.Err()
+}
+
+// ListUsers возвращает до filter.Limit+1 пользователей в порядке user_id
+func (s *PostgresStorage) ListUsers(filter models.UserFilter) ([]models.User, error) {
+	conds := []string{"user_id > $1"}
+	args := []interface{}{filter.AfterID}
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.TeamName != "" {
+		add("team_name = $%d", filter.TeamName)
+	}
+	if filter.IsActive != nil {
+		add("is_active = $%d", *filter.IsActive)
+	}
+	if filter.UsernamePrefix != "" {
+		add(`lower(username) LIKE $%d`, likeEscaper.Replace(strings.ToLower(filter.UsernamePrefix))+"%")
+	}
+
+	args = append(args, filter.Limit+1)
+	rows, err := s.db.Query(fmt.Sprintf(`
+		SELECT user_id, username, team_name, is_active
+		FROM users
+		WHERE %s
+		ORDER BY user_id
+		LIMIT $%d
+	`, strings.Join(conds, " AND "), len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"fmt"
+	"pr-reviewer-service/internal/models"
+)
+
+// StreamAssignments передаёт в fn назначения ревьюверов в порядке времени по мере чтения из БД
+func (s *PostgresStorage) StreamAssignments(filter models.AssignmentFilter, fn func(*models.Assignment) error) error {
+	conds := []string{
+		"e.event_type IN ('" + models.EventReviewerAssigned + "', '" + models.EventReviewerReplaced + "')",
+		"e.reviewer_id IS NOT NULL",
+	}
+	var args []interface{}
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.TeamName != "" {
+		add("a.team_name = $%d", filter.TeamName)
+	}
+	if filter.ReviewerID != "" {
+		add("e.reviewer_id = $%d", filter.ReviewerID)
+	}
+	if filter.PullRequestID != "" {
+		add("e.pull_request_id = $%d", filter.PullRequestID)
+	}
+	if filter.From != nil {
+		add("e.created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		add("e.created_at < $%d", *filter.To)
+	}
+
+	rows, err := s.db.Query(`
+		SELECT e.pull_request_id, p.pull_request_name, p.author_id, COALESCE(a.team_name, ''),
+		       e.reviewer_id, COALESCE(e.previous_reviewer_id, ''), e.reason, e.actor, e.created_at, p.status
+		FROM pr_events e
+		JOIN pull_requests p ON p.pull_request_id = e.pull_request_id
+		LEFT JOIN users a ON a.user_id = p.author_id
+		`+whereClause(conds)+`
+		ORDER BY e.created_at, e.id
+	`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a models.Assignment
+		if err := rows.Scan(&a.PullRequestID, &a.PullRequestName, &a.AuthorID, &a.TeamName,
+			&a.ReviewerID, &a.PreviousReviewerID, &a.Reason, &a.Actor, &a.AssignedAt, &a.PRStatus); err != nil {
+			return err
+		}
+		if err := fn(&a); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"database/sql"
+	"pr-reviewer-service/internal/models"
+	"time"
+)
+
+// recordStatusChange добавляет запись в историю активности, только если статус действительно изменился
+func recordStatusChange(tx *sql.Tx, userID string, isActive bool, at time.Time) error {
+	_, err := tx.Exec(`
+		INSERT INTO user_status_changes (user_id, is_active, changed_at)
+		SELECT $1, $2::boolean, $3
+		WHERE (
+			SELECT is_active FROM user_status_changes
+			WHERE user_id = $1
+			ORDER BY changed_at DESC, id DESC
+			LIMIT 1
+		) IS DISTINCT FROM $2::boolean
+	`, userID, isActive, at)
+	return err
+}
+
+// ListTeamStatusChanges возвращает историю активности участников команды до until, по возрастанию времени
+func (s *PostgresStorage) ListTeamStatusChanges(teamName string, until time.Time) ([]models.StatusChange, error) {
+	rows, err := s.db.Query(`
+		SELECT c.user_id, c.is_active, c.changed_at
+		FROM user_status_changes c
+		JOIN users u ON u.user_id = c.user_id
+		WHERE u.team_name = $1 AND c.changed_at < $2
+		ORDER BY c.changed_at, c.id
+	`, teamName, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []models.StatusChange{}
+	for rows.Next() {
+		var c models.StatusChange
+		if err := rows.Scan(&c.UserID, &c.IsActive, &c.ChangedAt); err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+// CountTeamAssignments считает назначения участников команды ревьюверами за [from, to)
+func (s *PostgresStorage) CountTeamAssignments(teamName string, from, to time.Time) (map[string]int, error) {
+	rows, err := s.db.Query(`
+		SELECT e.reviewer_id, COUNT(*)
+		FROM pr_events e
+		JOIN users u ON u.user_id = e.reviewer_id
+		WHERE u.team_name = $1
+		  AND e.event_type IN ('`+models.EventReviewerAssigned+`', '`+models.EventReviewerReplaced+`')
+		  AND e.created_at >= $2 AND e.created_at < $3
+		GROUP BY e.reviewer_id
+	`, teamName, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var userID string
+		var n int
+		if err := rows.Scan(&userID, &n); err != nil {
+			return nil, err
+		}
+		counts[userID] = n
+	}
+	return counts, rows.Err()
+}
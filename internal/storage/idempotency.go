@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"pr-reviewer-service/internal/models"
+	"time"
+)
+
+// ReserveIdempotencyKey занимает ключ за первым запросом. Если ключ уже занят и не истёк,
+// возвращает его запись и false; истёкший ключ занимается заново.
+func (s *PostgresStorage) ReserveIdempotencyKey(actor, key, fingerprint string, now, expiresAt time.Time) (*models.IdempotencyRecord, bool, error) {
+	// Запись может быть удалена очисткой между INSERT и SELECT - тогда пробуем ещё раз
+	for attempt := 0; attempt < 3; attempt++ {
+		var reserved bool
+		err := s.db.QueryRow(`
+			INSERT INTO idempotency_keys (actor, idempotency_key, fingerprint, created_at, expires_at)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (actor, idempotency_key) DO UPDATE SET
+				fingerprint = EXCLUDED.fingerprint,
+				status_code = NULL,
+				content_type = '',
+				response_body = NULL,
+				created_at = EXCLUDED.created_at,
+				expires_at = EXCLUDED.expires_at
+			WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+			RETURNING true
+		`, actor, key, fingerprint, now, expiresAt).Scan(&reserved)
+		if err == nil {
+			return nil, true, nil
+		}
+		if err != sql.ErrNoRows {
+			return nil, false, err
+		}
+
+		var record models.IdempotencyRecord
+		var status sql.NullInt64
+		err = s.db.QueryRow(`
+			SELECT fingerprint, status_code, content_type, COALESCE(response_body, '')
+			FROM idempotency_keys
+			WHERE actor = $1 AND idempotency_key = $2
+		`, actor, key).Scan(&record.Fingerprint, &status, &record.ContentType, &record.Body)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		record.StatusCode = int(status.Int64)
+		return &record, false, nil
+	}
+	return nil, false, fmt.Errorf("idempotency key %q: concurrent cleanup, try again", key)
+}
+
+// CompleteIdempotencyKey сохраняет ответ на первый запрос
+func (s *PostgresStorage) CompleteIdempotencyKey(actor, key string, statusCode int, contentType string, body []byte) error {
+	_, err := s.db.Exec(`
+		UPDATE idempotency_keys SET status_code = $3, content_type = $4, response_body = $5
+		WHERE actor = $1 AND idempotency_key = $2
+	`, actor, key, statusCode, contentType, body)
+	return err
+}
+
+// ReleaseIdempotencyKey освобождает ключ, если ответ не стоит повторять (ошибка сервера)
+func (s *PostgresStorage) ReleaseIdempotencyKey(actor, key string) error {
+	_, err := s.db.Exec(`DELETE FROM idempotency_keys WHERE actor = $1 AND idempotency_key = $2`, actor, key)
+	return err
+}
+
+func (s *PostgresStorage) PruneIdempotencyKeys(before time.Time) (int64, error) {
+	res, err := s.db.Exec(`DELETE FROM idempotency_keys WHERE expires_at <= $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"encoding/json"
+	"pr-reviewer-service/internal/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// existing возвращает множество значений column из table, совпадающих с values
+func (s *PostgresStorage) existing(table, column string, values []string) (map[string]bool, error) {
+	found := make(map[string]bool)
+	if len(values) == 0 {
+		return found, nil
+	}
+
+	rows, err := s.db.Query(`SELECT `+column+` FROM `+table+` WHERE `+column+` = ANY($1)`, pq.Array(values))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		found[v] = true
+	}
+	return found, rows.Err()
+}
+
+func (s *PostgresStorage) ExistingTeams(names []string) (map[string]bool, error) {
+	return s.existing("teams", "team_name", names)
+}
+
+func (s *PostgresStorage) ExistingUsers(ids []string) (map[string]bool, error) {
+	return s.existing("users", "user_id", ids)
+}
+
+func (s *PostgresStorage) ExistingPRs(ids []string) (map[string]bool, error) {
+	return s.existing("pull_requests", "pull_request_id", ids)
+}
+
+// ApplyImport сохраняет проверенный импорт в одной транзакции: либо всё, либо ничего.
+// Существующие команды пропускаются, пользователи обновляются, PR создаются вместе с историей.
+// Отчёт известен только внутри транзакции, поэтому в entry.After его записывает сам ApplyImport.
+func (s *PostgresStorage) ApplyImport(teams []string, users []models.User, prs []models.PullRequest, actor string, entry *models.AuditEntry) (*models.ImportReport, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	report := &models.ImportReport{Applied: true, Errors: []models.ImportError{}}
+	now := time.Now()
+
+	for _, team := range teams {
+		res, err := tx.Exec(`INSERT INTO teams (team_name) VALUES ($1) ON CONFLICT (team_name) DO NOTHING`, team)
+		if err != nil {
+			return nil, err
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			report.NewTeams++
+		}
+	}
+
+	for _, user := range users {
+		var inserted bool
+		err := tx.QueryRow(`
+			INSERT INTO users (user_id, username, team_name, is_active)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (user_id) DO UPDATE SET
+				username = EXCLUDED.username,
+				team_name = EXCLUDED.team_name,
+				is_active = EXCLUDED.is_active
+			RETURNING xmax = 0
+		`, user.UserID, user.Username, user.TeamName, user.IsActive).Scan(&inserted)
+		if err != nil {
+			return nil, err
+		}
+
+		// Новые пользователи считаются бывшими в этом статусе всегда, чтобы исторические PR
+		// не искажали отчёт о равномерности
+		changedAt := now
+		if inserted {
+			report.NewUsers++
+			changedAt = time.Unix(0, 0)
+		} else {
+			report.UpdatedUsers++
+		}
+		if err := recordStatusChange(tx, user.UserID, user.IsActive, changedAt); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range prs {
+		pr := &prs[i]
+		reviewersJSON, _ := json.Marshal(pr.AssignedReviewers)
+		_, err := tx.Exec(`
+			INSERT INTO pull_requests
+			(pull_request_id, pull_request_name, author_id, status, assigned_reviewers, created_at, merged_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, pr.PullRequestID, pr.PullRequestName, pr.AuthorID, pr.Status, reviewersJSON, *pr.CreatedAt, pr.MergedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		events := []models.PREvent{{Type: models.EventCreated, ToStatus: "OPEN", CreatedAt: *pr.CreatedAt}}
+		for _, reviewer := range pr.AssignedReviewers {
+			events = append(events, models.PREvent{
+				Type:       models.EventReviewerAssigned,
+				ReviewerID: reviewer,
+				Reason:     models.ReasonImport,
+				CreatedAt:  *pr.CreatedAt,
+			})
+		}
+		if pr.MergedAt != nil {
+			events = append(events, models.PREvent{
+				Type:       models.EventMerged,
+				FromStatus: "OPEN",
+				ToStatus:   "MERGED",
+				CreatedAt:  *pr.MergedAt,
+			})
+		}
+		for _, event := range events {
+			event.PullRequestID = pr.PullRequestID
+			event.Actor = actor
+			if err := insertPREvent(tx, &event); err != nil {
+				return nil, err
+			}
+		}
+		report.PullRequests++
+	}
+
+	if entry.After, err = json.Marshal(report); err != nil {
+		return nil, err
+	}
+	if err := insertAudit(tx, entry); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
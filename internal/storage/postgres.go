@@ -3,13 +3,17 @@ package storage
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"pr-reviewer-service/internal/models"
 	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
+// uniqueViolation - код ошибки PostgreSQL при нарушении ограничения уникальности
+const uniqueViolation = "23505"
+
 type PostgresStorage struct {
 	db *sql.DB
 }
@@ -27,6 +31,24 @@ func NewPostgresStorage(connStr string) (*PostgresStorage, error) {
 	return &PostgresStorage{db: db}, nil
 }
 
+// NewPostgresStorageFromDB оборачивает уже открытый пул без проверки соединения
+func NewPostgresStorageFromDB(db *sql.DB) *PostgresStorage {
+	return &PostgresStorage{db: db}
+}
+
+// SetPoolLimits настраивает пул соединений; нулевые значения оставляют значения database/sql по умолчанию
+func (s *PostgresStorage) SetPoolLimits(maxOpen, maxIdle int, maxLifetime time.Duration) {
+	if maxOpen > 0 {
+		s.db.SetMaxOpenConns(maxOpen)
+	}
+	if maxIdle > 0 {
+		s.db.SetMaxIdleConns(maxIdle)
+	}
+	if maxLifetime > 0 {
+		s.db.SetConnMaxLifetime(maxLifetime)
+	}
+}
+
 func (s *PostgresStorage) Close() error {
 	if s.db != nil {
 		return s.db.Close()
@@ -34,7 +56,7 @@ func (s *PostgresStorage) Close() error {
 	return nil
 }
 
-func (s *PostgresStorage) CreateTeam(team *models.Team) error {
+func (s *PostgresStorage) CreateTeam(team *models.Team, entry *models.AuditEntry) error {
 	tx, err := s.db.Begin()
 	if err != nil {
 		return err
@@ -55,6 +77,7 @@ func (s *PostgresStorage) CreateTeam(team *models.Team) error {
 		return err
 	}
 
+	now := time.Now()
 	for _, member := range team.Members {
 		_, err = tx.Exec(`
 			INSERT INTO users (user_id, username, team_name, is_active) 
@@ -67,8 +90,14 @@ func (s *PostgresStorage) CreateTeam(team *models.Team) error {
 		if err != nil {
 			return err
 		}
+		if err := recordStatusChange(tx, member.UserID, member.IsActive, now); err != nil {
+			return err
+		}
 	}
 
+	if err := insertAudit(tx, entry); err != nil {
+		return err
+	}
 	return tx.Commit()
 }
 
@@ -101,9 +130,16 @@ func (s *PostgresStorage) GetTeam(teamName string) (*models.Team, error) {
 	return &team, nil
 }
 
-func (s *PostgresStorage) SetUserActive(userID string, isActive bool) (*models.User, error) {
+// SetUserActive меняет статус пользователя и дописывает изменение в историю активности
+func (s *PostgresStorage) SetUserActive(userID string, isActive bool, entry *models.AuditEntry) (*models.User, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
 	var user models.User
-	err := s.db.QueryRow(`
+	err = tx.QueryRow(`
 		UPDATE users SET is_active = $1 
 		WHERE user_id = $2 
 		RETURNING user_id, username, team_name, is_active
@@ -112,20 +148,77 @@ func (s *PostgresStorage) SetUserActive(userID string, isActive bool) (*models.U
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("NOT_FOUND")
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := recordStatusChange(tx, userID, isActive, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := insertAudit(tx, entry); err != nil {
+		return nil, err
+	}
+
+	return &user, tx.Commit()
+}
+
+// CreatePR сохраняет PR вместе с событиями создания и первичного назначения ревьюверов
+func (s *PostgresStorage) CreatePR(pr *models.PullRequest, actor string, entry *models.AuditEntry) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-	return &user, err
+	if err := insertPR(tx, pr, actor); err != nil {
+		return err
+	}
+	if err := insertAudit(tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (s *PostgresStorage) CreatePR(pr *models.PullRequest) error {
+func insertPR(tx *sql.Tx, pr *models.PullRequest, actor string) error {
 	reviewersJSON, _ := json.Marshal(pr.AssignedReviewers)
 
-	_, err := s.db.Exec(`
+	createdAt := time.Now()
+	if pr.CreatedAt != nil {
+		createdAt = *pr.CreatedAt
+	}
+
+	_, err := tx.Exec(`
 		INSERT INTO pull_requests 
 		(pull_request_id, pull_request_name, author_id, status, assigned_reviewers, created_at) 
 		VALUES ($1, $2, $3, $4, $5, $6)
-	`, pr.PullRequestID, pr.PullRequestName, pr.AuthorID, pr.Status, reviewersJSON, time.Now())
+	`, pr.PullRequestID, pr.PullRequestName, pr.AuthorID, pr.Status, reviewersJSON, createdAt)
+	// PR с тем же id могли создать параллельно, уже после проверки PRExists
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return fmt.Errorf("PR_EXISTS")
+	}
+	if err != nil {
+		return err
+	}
 
-	return err
+	events := []models.PREvent{{Type: models.EventCreated, ToStatus: pr.Status}}
+	for _, reviewer := range pr.AssignedReviewers {
+		events = append(events, models.PREvent{
+			Type:       models.EventReviewerAssigned,
+			ReviewerID: reviewer,
+			Reason:     models.ReasonInitialAssignment,
+		})
+	}
+	for _, event := range events {
+		event.PullRequestID = pr.PullRequestID
+		event.Actor = actor
+		event.CreatedAt = createdAt
+		if err := insertPREvent(tx, &event); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (s *PostgresStorage) GetPR(prID string) (*models.PullRequest, error) {
@@ -135,12 +228,12 @@ func (s *PostgresStorage) GetPR(prID string) (*models.PullRequest, error) {
 
 	err := s.db.QueryRow(`
 		SELECT pull_request_id, pull_request_name, author_id, status, 
-		       assigned_reviewers, created_at, merged_at
+		       assigned_reviewers, created_at, merged_at, `+overdueColumn+`
 		FROM pull_requests 
 		WHERE pull_request_id = $1
 	`, prID).Scan(
 		&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status,
-		&reviewersJSON, &pr.CreatedAt, &mergedAt,
+		&reviewersJSON, &pr.CreatedAt, &mergedAt, &pr.Overdue,
 	)
 
 	if err == sql.ErrNoRows {
@@ -158,46 +251,107 @@ func (s *PostgresStorage) GetPR(prID string) (*models.PullRequest, error) {
 	return &pr, nil
 }
 
-func (s *PostgresStorage) MergePR(prID string) error {
-	_, err := s.db.Exec(`
+// MergePR переводит открытый PR в MERGED в момент at; для уже слитого PR ничего не делает и не пишет entry
+func (s *PostgresStorage) MergePR(prID, actor string, at time.Time, entry *models.AuditEntry) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	merged, err := mergePR(tx, prID, actor, at)
+	if err != nil || !merged {
+		return err
+	}
+	if err := insertAudit(tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// mergePR возвращает false, если PR уже слит
+func mergePR(tx *sql.Tx, prID, actor string, at time.Time) (bool, error) {
+	res, err := tx.Exec(`
 		UPDATE pull_requests 
 		SET status = 'MERGED', merged_at = $1 
 		WHERE pull_request_id = $2 AND status = 'OPEN'
-	`, time.Now(), prID)
-	return err
+	`, at, prID)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, nil
+	}
+
+	if err := resolveSLABreaches(tx, prID, "", at); err != nil {
+		return false, err
+	}
+
+	return true, insertPREvent(tx, &models.PREvent{
+		PullRequestID: prID,
+		Type:          models.EventMerged,
+		Actor:         actor,
+		FromStatus:    "OPEN",
+		ToStatus:      "MERGED",
+		CreatedAt:     at,
+	})
 }
 
-func (s *PostgresStorage) UpdatePRReviewers(prID string, reviewers []string) error {
+// ReplacePRReviewer сохраняет новый список ревьюверов и событие замены oldReviewer на newReviewer.
+// previous - ревьюверы, по которым выбиралась замена: если с тех пор PR слили или его ревьюверов
+// изменили, ничего не записывается и возвращается PR_MODIFIED.
+func (s *PostgresStorage) ReplacePRReviewer(prID string, previous, reviewers []string, oldReviewer, newReviewer, reason, actor string, entry *models.AuditEntry) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := replacePRReviewer(tx, prID, previous, reviewers, oldReviewer, newReviewer, reason, actor, time.Now()); err != nil {
+		return err
+	}
+	if err := insertAudit(tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func replacePRReviewer(tx *sql.Tx, prID string, previous, reviewers []string, oldReviewer, newReviewer, reason, actor string, at time.Time) error {
 	reviewersJSON, _ := json.Marshal(reviewers)
-	_, err := s.db.Exec(`
+	if previous == nil {
+		previous = []string{}
+	}
+	previousJSON, _ := json.Marshal(previous)
+
+	// Условие на прежнее состояние вместо блокировки: параллельная замена или merge между чтением PR
+	// и записью иначе затёрлись бы этой записью
+	res, err := tx.Exec(`
 		UPDATE pull_requests 
 		SET assigned_reviewers = $1 
-		WHERE pull_request_id = $2
-	`, reviewersJSON, prID)
-	return err
-}
-
-func (s *PostgresStorage) GetUserReviewPRs(userID string) ([]models.PullRequestShort, error) {
-	rows, err := s.db.Query(`
-		SELECT pull_request_id, pull_request_name, author_id, status
-		FROM pull_requests 
-		WHERE $1 = ANY(assigned_reviewers)
-	`, userID)
+		WHERE pull_request_id = $2 AND status = 'OPEN' AND assigned_reviewers = $3::jsonb
+	`, reviewersJSON, prID, previousJSON)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("PR_MODIFIED")
 	}
-	defer rows.Close()
 
-	var prs []models.PullRequestShort
-	for rows.Next() {
-		var pr models.PullRequestShort
-		if err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status); err != nil {
-			return nil, err
-		}
-		prs = append(prs, pr)
+	if err := resolveSLABreaches(tx, prID, oldReviewer, at); err != nil {
+		return err
 	}
 
-	return prs, nil
+	return insertPREvent(tx, &models.PREvent{
+		PullRequestID:      prID,
+		Type:               models.EventReviewerReplaced,
+		Actor:              actor,
+		ReviewerID:         newReviewer,
+		PreviousReviewerID: oldReviewer,
+		Reason:             reason,
+		CreatedAt:          at,
+	})
 }
 
 func (s *PostgresStorage) GetActiveTeamMembers(teamName string, excludeUserID string) ([]string, error) {
@@ -250,65 +404,18 @@ func (s *PostgresStorage) GetUserTeam(userID string) (string, error) {
 	return teamName, err
 }
 
-// GetStats возвращает статистику системы
-func (s *PostgresStorage) GetStats() (map[string]interface{}, error) {
-	stats := make(map[string]interface{})
-
-	// Общая статистика
-	var totalTeams, totalUsers, totalPRs, openPRs, mergedPRs int
-	err := s.db.QueryRow("SELECT COUNT(*) FROM teams").Scan(&totalTeams)
-	if err != nil {
-		return nil, err
-	}
-	err = s.db.QueryRow("SELECT COUNT(*) FROM users").Scan(&totalUsers)
-	if err != nil {
-		return nil, err
-	}
-	err = s.db.QueryRow("SELECT COUNT(*) FROM pull_requests").Scan(&totalPRs)
-	if err != nil {
-		return nil, err
-	}
-	err = s.db.QueryRow("SELECT COUNT(*) FROM pull_requests WHERE status = 'OPEN'").Scan(&openPRs)
-	if err != nil {
-		return nil, err
-	}
-	err = s.db.QueryRow("SELECT COUNT(*) FROM pull_requests WHERE status = 'MERGED'").Scan(&mergedPRs)
-	if err != nil {
-		return nil, err
+func (s *PostgresStorage) GetUser(userID string) (*models.User, error) {
+	var user models.User
+	err := s.db.QueryRow(`
+		SELECT user_id, username, team_name, is_active
+		FROM users
+		WHERE user_id = $1
+	`, userID).Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("NOT_FOUND")
 	}
-
-	// Статистика по назначениям ревьюверов - исправленный запрос
-	rows, err := s.db.Query(`
-		SELECT value as user_id, COUNT(*) as assignment_count 
-		FROM pull_requests, jsonb_array_elements_text(assigned_reviewers) 
-		GROUP BY value 
-		ORDER BY assignment_count DESC 
-		LIMIT 10
-	`)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	topReviewers := make([]map[string]interface{}, 0)
-	for rows.Next() {
-		var userID string
-		var count int
-		if err := rows.Scan(&userID, &count); err != nil {
-			return nil, err
-		}
-		topReviewers = append(topReviewers, map[string]interface{}{
-			"user_id":          userID,
-			"assignment_count": count,
-		})
-	}
-
-	stats["total_teams"] = totalTeams
-	stats["total_users"] = totalUsers
-	stats["total_prs"] = totalPRs
-	stats["open_prs"] = openPRs
-	stats["merged_prs"] = mergedPRs
-	stats["top_reviewers"] = topReviewers
-
-	return stats, nil
+	return &user, nil
 }
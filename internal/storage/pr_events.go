@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"database/sql"
+	"pr-reviewer-service/internal/models"
+	"time"
+)
+
+func insertPREvent(tx *sql.Tx, event *models.PREvent) error {
+	return tx.QueryRow(`
+		INSERT INTO pr_events
+		(pull_request_id, event_type, actor, reviewer_id, previous_reviewer_id, reason, from_status, to_status, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6, $7, $8, $9)
+		RETURNING id
+	`, event.PullRequestID, event.Type, event.Actor, event.ReviewerID, event.PreviousReviewerID,
+		event.Reason, event.FromStatus, event.ToStatus, event.CreatedAt,
+	).Scan(&event.ID)
+}
+
+// GetPREvents возвращает историю PR в порядке возникновения
+func (s *PostgresStorage) GetPREvents(prID string) ([]models.PREvent, error) {
+	rows, err := s.db.Query(`
+		SELECT id, pull_request_id, event_type, actor, COALESCE(reviewer_id, ''),
+		       COALESCE(previous_reviewer_id, ''), reason, from_status, to_status, created_at
+		FROM pr_events
+		WHERE pull_request_id = $1
+		ORDER BY created_at, id
+	`, prID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.PREvent{}
+	for rows.Next() {
+		var e models.PREvent
+		if err := rows.Scan(&e.ID, &e.PullRequestID, &e.Type, &e.Actor, &e.ReviewerID,
+			&e.PreviousReviewerID, &e.Reason, &e.FromStatus, &e.ToStatus, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// ListReviewerEvents возвращает события ревьювера с id больше afterID, созданные до before:
+// назначения и замены, где он новый или прежний ревьювер, и слияния PR, где он сейчас ревьювер.
+func (s *PostgresStorage) ListReviewerEvents(userID string, afterID int64, before time.Time, limit int) ([]models.PREvent, error) {
+	rows, err := s.db.Query(`
+		SELECT e.id, e.pull_request_id, e.event_type, e.actor, COALESCE(e.reviewer_id, ''),
+		       COALESCE(e.previous_reviewer_id, ''), e.reason, e.from_status, e.to_status, e.created_at
+		FROM pr_events e
+		WHERE e.id > $2 AND e.created_at < $3
+		  AND (
+		    (e.event_type IN ('REVIEWER_ASSIGNED', 'REVIEWER_REPLACED')
+		     AND (e.reviewer_id = $1 OR e.previous_reviewer_id = $1))
+		    OR (e.event_type = 'MERGED' AND EXISTS (
+		      SELECT 1 FROM pull_requests p
+		      WHERE p.pull_request_id = e.pull_request_id
+		        AND p.assigned_reviewers @> jsonb_build_array($1::text)))
+		  )
+		ORDER BY e.id
+		LIMIT $4
+	`, userID, afterID, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.PREvent{}
+	for rows.Next() {
+		var e models.PREvent
+		if err := rows.Scan(&e.ID, &e.PullRequestID, &e.Type, &e.Actor, &e.ReviewerID,
+			&e.PreviousReviewerID, &e.Reason, &e.FromStatus, &e.ToStatus, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// LastPREventID возвращает id последнего события журнала, созданного до before, или 0
+func (s *PostgresStorage) LastPREventID(before time.Time) (int64, error) {
+	var id int64
+	err := s.db.QueryRow(`SELECT COALESCE(MAX(id), 0) FROM pr_events WHERE created_at < $1`, before).Scan(&id)
+	return id, err
+}
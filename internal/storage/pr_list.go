@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"pr-reviewer-service/internal/models"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// likeEscaper экранирует спецсимволы LIKE, чтобы подстрока искалась буквально
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// prListConds строит условия WHERE по фильтру списка PR без учёта курсора
+func prListConds(filter models.PRListFilter) ([]string, []interface{}) {
+	var conds []string
+	var args []interface{}
+	add := func(cond string, arg ...interface{}) {
+		placeholders := make([]interface{}, len(arg))
+		for i := range arg {
+			args = append(args, arg[i])
+			placeholders[i] = len(args)
+		}
+		conds = append(conds, fmt.Sprintf(cond, placeholders...))
+	}
+
+	now := time.Now()
+	if filter.TeamName != "" {
+		add("author_id IN (SELECT user_id FROM users WHERE team_name = $%d)", filter.TeamName)
+	}
+	if filter.AuthorID != "" {
+		add("author_id = $%d", filter.AuthorID)
+	}
+	if filter.ReviewerID != "" {
+		add("assigned_reviewers ? $%d", filter.ReviewerID)
+	}
+	if len(filter.Statuses) > 0 {
+		add("status = ANY($%d)", pq.Array(filter.Statuses))
+	}
+	if filter.MinAge != nil {
+		add("created_at <= $%d", now.Add(-*filter.MinAge))
+	}
+	if filter.MaxAge != nil {
+		add("created_at >= $%d", now.Add(-*filter.MaxAge))
+	}
+	if filter.NameContains != "" {
+		add("pull_request_name ILIKE $%d", "%"+likeEscaper.Replace(filter.NameContains)+"%")
+	}
+	if filter.Search != "" {
+		add("to_tsvector('simple', pull_request_name) @@ plainto_tsquery('simple', $%d)", filter.Search)
+	}
+	if filter.NoReviewers {
+		conds = append(conds, "assigned_reviewers = '[]'::jsonb")
+	}
+	if filter.OverdueOnly {
+		conds = append(conds, overdueColumn)
+	}
+	if filter.After != nil {
+		cmp := ">"
+		if filter.SortDesc {
+			cmp = "<"
+		}
+		args = append(args, filter.After.CreatedAt, filter.After.ID)
+		conds = append(conds, fmt.Sprintf("(created_at, pull_request_id) %s ($%d, $%d)", cmp, len(args)-1, len(args)))
+	}
+
+	return conds, args
+}
+
+func whereClause(conds []string) string {
+	if len(conds) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(conds, " AND ")
+}
+
+// ListPRs возвращает до filter.Limit+1 PR и общее количество подходящих PR без учёта курсора
+func (s *PostgresStorage) ListPRs(filter models.PRListFilter) ([]models.PullRequest, int, error) {
+	countFilter := filter
+	countFilter.After = nil
+	conds, args := prListConds(countFilter)
+
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM pull_requests `+whereClause(conds), args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	filter.Limit++
+	prs := []models.PullRequest{}
+	err := s.StreamPRs(filter, func(pr *models.PullRequest) error {
+		prs = append(prs, *pr)
+		return nil
+	})
+	return prs, total, err
+}
+
+// StreamPRs передаёт в fn подходящие PR по мере чтения из БД, не накапливая их в памяти.
+// Нулевой filter.Limit означает все строки.
+func (s *PostgresStorage) StreamPRs(filter models.PRListFilter, fn func(*models.PullRequest) error) error {
+	conds, args := prListConds(filter)
+
+	order := "ASC"
+	if filter.SortDesc {
+		order = "DESC"
+	}
+	query := fmt.Sprintf(`
+		SELECT pull_request_id, pull_request_name, author_id, status,
+		       assigned_reviewers, created_at, merged_at, %s
+		FROM pull_requests
+		%s
+		ORDER BY created_at %s, pull_request_id %s`, overdueColumn, whereClause(conds), order, order)
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pr models.PullRequest
+		var reviewersJSON string
+		var createdAt time.Time
+		var mergedAt sql.NullTime
+		if err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status,
+			&reviewersJSON, &createdAt, &mergedAt, &pr.Overdue); err != nil {
+			return err
+		}
+		pr.CreatedAt = &createdAt
+		if mergedAt.Valid {
+			pr.MergedAt = &mergedAt.Time
+		}
+		json.Unmarshal([]byte(reviewersJSON), &pr.AssignedReviewers)
+		if err := fn(&pr); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
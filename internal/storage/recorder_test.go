@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recorder - драйвер database/sql, который запоминает запросы с аргументами, а также COMMIT и ROLLBACK.
+// Запрос, содержащий ключ из rows, возвращает одну строку с этими значениями, из fail - ошибку,
+// остальные запросы возвращают пустой результат, Exec изменяет affected строк.
+type recorder struct {
+	mu       sync.Mutex
+	queries  []recordedQuery
+	rows     map[string][]driver.Value
+	fail     map[string]error
+	affected int64
+}
+
+type recordedQuery struct {
+	query string
+	args  []driver.Value
+}
+
+func (r *recorder) record(query string, args []driver.Value) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.queries = append(r.queries, recordedQuery{query: query, args: args})
+	for key, err := range r.fail {
+		if strings.Contains(query, key) {
+			return err
+		}
+	}
+	return nil
+}
+
+// log возвращает запросы по порядку, каждый сокращённый до первой строки без отступов
+func (r *recorder) log() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var log []string
+	for _, q := range r.queries {
+		log = append(log, strings.SplitN(strings.TrimSpace(q.query), "\n", 2)[0])
+	}
+	return log
+}
+
+type recorderConn struct{ r *recorder }
+
+func (c *recorderConn) Prepare(query string) (driver.Stmt, error) {
+	return &recorderStmt{r: c.r, query: query}, nil
+}
+func (c *recorderConn) Close() error              { return nil }
+func (c *recorderConn) Begin() (driver.Tx, error) { return c, nil }
+func (c *recorderConn) Commit() error             { return c.r.record("COMMIT", nil) }
+func (c *recorderConn) Rollback() error           { return c.r.record("ROLLBACK", nil) }
+
+type recorderStmt struct {
+	r     *recorder
+	query string
+}
+
+func (s *recorderStmt) Close() error  { return nil }
+func (s *recorderStmt) NumInput() int { return -1 }
+
+func (s *recorderStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if err := s.r.record(s.query, args); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(s.r.affected), nil
+}
+
+func (s *recorderStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if err := s.r.record(s.query, args); err != nil {
+		return nil, err
+	}
+	for key, row := range s.r.rows {
+		if strings.Contains(s.query, key) {
+			return &recorderRows{row: row}, nil
+		}
+	}
+	return &recorderRows{}, nil
+}
+
+type recorderRows struct {
+	row  []driver.Value
+	done bool
+}
+
+func (r *recorderRows) Columns() []string { return make([]string, len(r.row)) }
+func (r *recorderRows) Close() error      { return nil }
+
+func (r *recorderRows) Next(dest []driver.Value) error {
+	if r.row == nil || r.done {
+		return io.EOF
+	}
+	copy(dest, r.row)
+	r.done = true
+	return nil
+}
+
+var (
+	recorderOnce sync.Once
+	recorders    sync.Map
+)
+
+// recorderDriver выбирает recorder теста по имени источника данных
+type recorderDriver struct{}
+
+func (recorderDriver) Open(name string) (driver.Conn, error) {
+	r, _ := recorders.Load(name)
+	return &recorderConn{r.(*recorder)}, nil
+}
+
+// newRecordingStorage возвращает хранилище поверх recorder: проверяет запросы без PostgreSQL
+func newRecordingStorage(t *testing.T) (*PostgresStorage, *recorder) {
+	recorderOnce.Do(func() { sql.Register("recorder", recorderDriver{}) })
+	r := &recorder{rows: make(map[string][]driver.Value), fail: make(map[string]error), affected: 1}
+	recorders.Store(t.Name(), r)
+	t.Cleanup(func() { recorders.Delete(t.Name()) })
+
+	db, err := sql.Open("recorder", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Одно соединение: запросы транзакции и вне её идут в одном порядке
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return NewPostgresStorageFromDB(db), r
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"pr-reviewer-service/internal/models"
+	"time"
+)
+
+const reminderColumns = `user_id, enabled, channel, address, time_zone, send_at, last_sent_on`
+
+func scanReminder(row interface{ Scan(...interface{}) error }) (*models.ReminderSettings, error) {
+	var r models.ReminderSettings
+	var lastSentOn sql.NullTime
+	if err := row.Scan(&r.UserID, &r.Enabled, &r.Channel, &r.Address, &r.TimeZone, &r.SendAt, &lastSentOn); err != nil {
+		return nil, err
+	}
+	if lastSentOn.Valid {
+		r.LastSentOn = &lastSentOn.Time
+	}
+	return &r, nil
+}
+
+func (s *PostgresStorage) GetReminderSettings(userID string) (*models.ReminderSettings, error) {
+	r, err := scanReminder(s.db.QueryRow(`SELECT `+reminderColumns+` FROM reminder_settings WHERE user_id = $1`, userID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("NOT_FOUND")
+	}
+	return r, err
+}
+
+func (s *PostgresStorage) SetReminderSettings(r *models.ReminderSettings) error {
+	_, err := s.db.Exec(`
+		INSERT INTO reminder_settings (user_id, enabled, channel, address, time_zone, send_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id) DO UPDATE SET
+			enabled = EXCLUDED.enabled,
+			channel = EXCLUDED.channel,
+			address = EXCLUDED.address,
+			time_zone = EXCLUDED.time_zone,
+			send_at = EXCLUDED.send_at,
+			updated_at = EXCLUDED.updated_at
+	`, r.UserID, r.Enabled, r.Channel, r.Address, r.TimeZone, r.SendAt, time.Now())
+	return err
+}
+
+// ListEnabledReminders возвращает подписки активных пользователей
+func (s *PostgresStorage) ListEnabledReminders() ([]models.ReminderSettings, error) {
+	rows, err := s.db.Query(`
+		SELECT r.user_id, r.enabled, r.channel, r.address, r.time_zone, r.send_at, r.last_sent_on
+		FROM reminder_settings r
+		JOIN users u ON u.user_id = r.user_id
+		WHERE r.enabled AND u.is_active
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reminders := []models.ReminderSettings{}
+	for rows.Next() {
+		r, err := scanReminder(rows)
+		if err != nil {
+			return nil, err
+		}
+		reminders = append(reminders, *r)
+	}
+	return reminders, rows.Err()
+}
+
+// MarkReminderSent запоминает локальную дату отправки
+func (s *PostgresStorage) MarkReminderSent(userID string, localDate string) error {
+	_, err := s.db.Exec(`UPDATE reminder_settings SET last_sent_on = $2::date WHERE user_id = $1`, userID, localDate)
+	return err
+}
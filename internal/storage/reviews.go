@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"fmt"
+	"pr-reviewer-service/internal/models"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// GetUserReviewPRs возвращает до filter.Limit+1 PR, где пользователь назначен ревьювером,
+// и общее количество подходящих PR без учёта курсора. Лишняя запись означает, что есть следующая страница.
+func (s *PostgresStorage) GetUserReviewPRs(filter models.ReviewFilter) ([]models.PullRequestShort, int, error) {
+	conds := []string{"assigned_reviewers ? $1"}
+	args := []interface{}{filter.UserID}
+	add := func(cond string, arg ...interface{}) {
+		placeholders := make([]interface{}, len(arg))
+		for i := range arg {
+			args = append(args, arg[i])
+			placeholders[i] = len(args)
+		}
+		conds = append(conds, fmt.Sprintf(cond, placeholders...))
+	}
+
+	if len(filter.Statuses) > 0 {
+		add("status = ANY($%d)", pq.Array(filter.Statuses))
+	}
+	if filter.CreatedAfter != nil {
+		add("created_at >= $%d", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		add("created_at < $%d", *filter.CreatedBefore)
+	}
+
+	var total int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM pull_requests WHERE `+strings.Join(conds, " AND "), args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	order := "ASC"
+	cmp := ">"
+	if filter.SortDesc {
+		order = "DESC"
+		cmp = "<"
+	}
+	if filter.After != nil {
+		add("(created_at, pull_request_id) "+cmp+" ($%d, $%d)", filter.After.CreatedAt, filter.After.ID)
+	}
+
+	args = append(args, filter.Limit+1)
+	rows, err := s.db.Query(fmt.Sprintf(`
+		SELECT pull_request_id, pull_request_name, author_id, status, created_at, %s
+		FROM pull_requests
+		WHERE %s
+		ORDER BY created_at %s, pull_request_id %s
+		LIMIT $%d
+	`, overdueColumn, strings.Join(conds, " AND "), order, order, len(args)), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	prs := []models.PullRequestShort{}
+	for rows.Next() {
+		var pr models.PullRequestShort
+		if err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.Overdue); err != nil {
+			return nil, 0, err
+		}
+		prs = append(prs, pr)
+	}
+
+	return prs, total, rows.Err()
+}
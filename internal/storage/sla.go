@@ -0,0 +1,215 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"pr-reviewer-service/internal/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func (s *PostgresStorage) GetTeamSLA(teamName string) (*models.TeamSLA, error) {
+	var sla models.TeamSLA
+	var weekend, holidays pq.StringArray
+	err := s.db.QueryRow(`
+		SELECT team_name, first_review_minutes, time_zone, workday_start, workday_end,
+		       weekend_days, holidays::text[], auto_reassign
+		FROM team_sla
+		WHERE team_name = $1
+	`, teamName).Scan(&sla.TeamName, &sla.FirstReviewMinutes, &sla.TimeZone, &sla.WorkdayStart,
+		&sla.WorkdayEnd, &weekend, &holidays, &sla.AutoReassign)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("NOT_FOUND")
+	}
+	if err != nil {
+		return nil, err
+	}
+	sla.WeekendDays = []string(weekend)
+	sla.Holidays = []string(holidays)
+	return &sla, nil
+}
+
+func (s *PostgresStorage) ListTeamSLAs() ([]models.TeamSLA, error) {
+	rows, err := s.db.Query(`
+		SELECT team_name, first_review_minutes, time_zone, workday_start, workday_end,
+		       weekend_days, holidays::text[], auto_reassign
+		FROM team_sla
+		ORDER BY team_name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	slas := []models.TeamSLA{}
+	for rows.Next() {
+		var sla models.TeamSLA
+		var weekend, holidays pq.StringArray
+		if err := rows.Scan(&sla.TeamName, &sla.FirstReviewMinutes, &sla.TimeZone, &sla.WorkdayStart,
+			&sla.WorkdayEnd, &weekend, &holidays, &sla.AutoReassign); err != nil {
+			return nil, err
+		}
+		sla.WeekendDays = []string(weekend)
+		sla.Holidays = []string(holidays)
+		slas = append(slas, sla)
+	}
+	return slas, rows.Err()
+}
+
+func (s *PostgresStorage) SetTeamSLA(sla *models.TeamSLA) error {
+	var exists bool
+	err := s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM teams WHERE team_name = $1)", sla.TeamName).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("NOT_FOUND")
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO team_sla
+		(team_name, first_review_minutes, time_zone, workday_start, workday_end, weekend_days, holidays, auto_reassign, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7::date[], $8, $9)
+		ON CONFLICT (team_name) DO UPDATE SET
+			first_review_minutes = EXCLUDED.first_review_minutes,
+			time_zone = EXCLUDED.time_zone,
+			workday_start = EXCLUDED.workday_start,
+			workday_end = EXCLUDED.workday_end,
+			weekend_days = EXCLUDED.weekend_days,
+			holidays = EXCLUDED.holidays,
+			auto_reassign = EXCLUDED.auto_reassign,
+			updated_at = EXCLUDED.updated_at
+	`, sla.TeamName, sla.FirstReviewMinutes, sla.TimeZone, sla.WorkdayStart, sla.WorkdayEnd,
+		pq.Array(sla.WeekendDays), pq.Array(sla.Holidays), sla.AutoReassign, time.Now())
+	return err
+}
+
+// assignmentEvents - события, которые назначают ревьювера и запускают отсчёт SLA.
+// SLA_BREACHED тоже указывает ревьювера, но назначение не меняет.
+var assignmentEvents = []string{models.EventReviewerAssigned, models.EventReviewerReplaced}
+
+// ListOpenAssignments возвращает назначения на открытые PR команд с настроенным SLA,
+// по которым ещё нет незакрытого нарушения. Время назначения берётся из истории PR.
+func (s *PostgresStorage) ListOpenAssignments() ([]models.ReviewAssignment, error) {
+	rows, err := s.db.Query(`
+		SELECT p.pull_request_id, r.reviewer_id, a.team_name,
+		       COALESCE((
+		           SELECT MAX(e.created_at)
+		           FROM pr_events e
+		           WHERE e.pull_request_id = p.pull_request_id AND e.reviewer_id = r.reviewer_id
+		             AND e.event_type = ANY($1)
+		       ), p.created_at)
+		FROM pull_requests p
+		CROSS JOIN LATERAL jsonb_array_elements_text(p.assigned_reviewers) AS r(reviewer_id)
+		JOIN users a ON a.user_id = p.author_id
+		JOIN team_sla t ON t.team_name = a.team_name
+		WHERE p.status = 'OPEN'
+		  AND NOT EXISTS (
+		      SELECT 1 FROM sla_breaches b
+		      WHERE b.pull_request_id = p.pull_request_id
+		        AND b.reviewer_id = r.reviewer_id
+		        AND b.resolved_at IS NULL
+		  )
+	`, pq.Array(assignmentEvents))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	assignments := []models.ReviewAssignment{}
+	for rows.Next() {
+		var a models.ReviewAssignment
+		if err := rows.Scan(&a.PullRequestID, &a.ReviewerID, &a.TeamName, &a.AssignedAt); err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, a)
+	}
+	return assignments, rows.Err()
+}
+
+// RecordSLABreach сохраняет нарушение и событие в истории PR.
+// Возвращает false, если это нарушение уже было зафиксировано.
+func (s *PostgresStorage) RecordSLABreach(a models.ReviewAssignment, actor string) (bool, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	var id int64
+	err = tx.QueryRow(`
+		INSERT INTO sla_breaches (pull_request_id, reviewer_id, assigned_at, detected_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (pull_request_id, reviewer_id, assigned_at) DO NOTHING
+		RETURNING id
+	`, a.PullRequestID, a.ReviewerID, a.AssignedAt, now).Scan(&id)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	err = insertPREvent(tx, &models.PREvent{
+		PullRequestID: a.PullRequestID,
+		Type:          models.EventSLABreached,
+		Actor:         actor,
+		ReviewerID:    a.ReviewerID,
+		CreatedAt:     now,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// ListSLABreaches возвращает нарушения, при openOnly - только незакрытые
+func (s *PostgresStorage) ListSLABreaches(teamName string, openOnly bool) ([]models.SLABreach, error) {
+	rows, err := s.db.Query(`
+		SELECT b.id, b.pull_request_id, b.reviewer_id, a.team_name, b.assigned_at, b.detected_at, b.resolved_at
+		FROM sla_breaches b
+		JOIN pull_requests p ON p.pull_request_id = b.pull_request_id
+		JOIN users a ON a.user_id = p.author_id
+		WHERE ($1 = '' OR a.team_name = $1)
+		  AND (NOT $2 OR b.resolved_at IS NULL)
+		ORDER BY b.detected_at DESC, b.id DESC
+		LIMIT 1000
+	`, teamName, openOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	breaches := []models.SLABreach{}
+	for rows.Next() {
+		var b models.SLABreach
+		var resolvedAt sql.NullTime
+		if err := rows.Scan(&b.ID, &b.PullRequestID, &b.ReviewerID, &b.TeamName,
+			&b.AssignedAt, &b.DetectedAt, &resolvedAt); err != nil {
+			return nil, err
+		}
+		if resolvedAt.Valid {
+			b.ResolvedAt = &resolvedAt.Time
+		}
+		breaches = append(breaches, b)
+	}
+	return breaches, rows.Err()
+}
+
+// resolveSLABreaches закрывает нарушения PR; пустой reviewerID закрывает нарушения всех ревьюверов
+func resolveSLABreaches(tx *sql.Tx, prID, reviewerID string, at time.Time) error {
+	_, err := tx.Exec(`
+		UPDATE sla_breaches SET resolved_at = $3
+		WHERE pull_request_id = $1 AND ($2 = '' OR reviewer_id = $2) AND resolved_at IS NULL
+	`, prID, reviewerID, at)
+	return err
+}
+
+// overdueColumn - выражение для флага overdue в запросах по pull_requests
+const overdueColumn = `EXISTS (
+		           SELECT 1 FROM sla_breaches b
+		           WHERE b.pull_request_id = pull_requests.pull_request_id AND b.resolved_at IS NULL
+		       )`
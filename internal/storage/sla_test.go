@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"pr-reviewer-service/internal/models"
+	"strings"
+	"testing"
+)
+
+func TestListOpenAssignmentsCountsOnlyAssignmentEvents(t *testing.T) {
+	s, r := newRecordingStorage(t)
+	if _, err := s.ListOpenAssignments(); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.queries) != 1 {
+		t.Fatalf("got %d queries, want 1", len(r.queries))
+	}
+	q := r.queries[0]
+	if !strings.Contains(q.query, "e.event_type = ANY($1)") || len(q.args) != 1 {
+		t.Fatalf("assignment time is not filtered by event type:\n%s\nargs %v", q.query, q.args)
+	}
+
+	// Отметка о нарушении SLA не должна перезапускать отсчёт: иначе нарушение повторяется через каждый срок SLA
+	types, _ := q.args[0].(string)
+	for _, want := range []string{models.EventReviewerAssigned, models.EventReviewerReplaced} {
+		if !strings.Contains(types, `"`+want+`"`) {
+			t.Errorf("event types %s miss %s", types, want)
+		}
+	}
+	for _, other := range []string{models.EventSLABreached, models.EventCreated, models.EventMerged} {
+		if strings.Contains(types, other) {
+			t.Errorf("event types %s include %s", types, other)
+		}
+	}
+}
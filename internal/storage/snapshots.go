@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"database/sql"
+	"pr-reviewer-service/internal/models"
+	"time"
+)
+
+// TakeStatsSnapshot сохраняет снимок метрик по пользователям, командам и системе целиком.
+// Снимки команд и системы суммируются из снимков пользователей, поэтому ряды согласованы между собой.
+func (s *PostgresStorage) TakeStatsSnapshot(at time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		WITH authored AS (
+			SELECT author_id AS user_id,
+			       COUNT(*) FILTER (WHERE status = 'OPEN') AS open_prs,
+			       COUNT(*) FILTER (WHERE status = 'MERGED') AS merges
+			FROM pull_requests
+			GROUP BY author_id
+		),
+		reviewing AS (
+			SELECT r.value AS user_id, COUNT(*) AS n
+			FROM pull_requests p
+			CROSS JOIN LATERAL jsonb_array_elements_text(p.assigned_reviewers) r
+			WHERE p.status = 'OPEN'
+			GROUP BY r.value
+		),
+		assigned AS (
+			SELECT reviewer_id AS user_id, COUNT(*) AS n
+			FROM pr_events
+			WHERE event_type IN ('`+models.EventReviewerAssigned+`', '`+models.EventReviewerReplaced+`')
+			  AND reviewer_id IS NOT NULL
+			GROUP BY reviewer_id
+		)
+		INSERT INTO stats_snapshots (taken_at, scope, subject, open_prs, open_reviews, assignments_total, merges_total)
+		SELECT $1, '`+models.ScopeUser+`', u.user_id,
+		       COALESCE(a.open_prs, 0), COALESCE(r.n, 0), COALESCE(s.n, 0), COALESCE(a.merges, 0)
+		FROM users u
+		LEFT JOIN authored a ON a.user_id = u.user_id
+		LEFT JOIN reviewing r ON r.user_id = u.user_id
+		LEFT JOIN assigned s ON s.user_id = u.user_id
+	`, at)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO stats_snapshots (taken_at, scope, subject, open_prs, open_reviews, assignments_total, merges_total)
+		SELECT $1, '`+models.ScopeTeam+`', u.team_name,
+		       SUM(s.open_prs), SUM(s.open_reviews), SUM(s.assignments_total), SUM(s.merges_total)
+		FROM stats_snapshots s
+		JOIN users u ON u.user_id = s.subject
+		WHERE s.scope = '`+models.ScopeUser+`' AND s.taken_at = $1
+		GROUP BY u.team_name
+	`, at)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO stats_snapshots (taken_at, scope, subject, open_prs, open_reviews, assignments_total, merges_total)
+		SELECT $1, '`+models.ScopeGlobal+`', '',
+		       COALESCE(SUM(open_prs), 0), COALESCE(SUM(open_reviews), 0),
+		       COALESCE(SUM(assignments_total), 0), COALESCE(SUM(merges_total), 0)
+		FROM stats_snapshots
+		WHERE scope = '`+models.ScopeUser+`' AND taken_at = $1
+	`, at)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// PruneStatsSnapshots удаляет снимки старше deleteBefore, а снимки старше rawBefore
+// прореживает до последнего за сутки. Счётчики накопленные, поэтому приросты по дням не теряются.
+func (s *PostgresStorage) PruneStatsSnapshots(rawBefore, deleteBefore time.Time) (int64, error) {
+	res, err := s.db.Exec(`DELETE FROM stats_snapshots WHERE taken_at < $1`, deleteBefore)
+	if err != nil {
+		return 0, err
+	}
+	deleted, _ := res.RowsAffected()
+
+	res, err = s.db.Exec(`
+		DELETE FROM stats_snapshots s
+		WHERE s.taken_at < $1
+		  AND EXISTS (
+			SELECT 1 FROM stats_snapshots n
+			WHERE n.scope = s.scope AND n.subject = s.subject
+			  AND date_trunc('day', n.taken_at) = date_trunc('day', s.taken_at)
+			  AND n.taken_at > s.taken_at
+		  )
+	`, rawBefore)
+	if err != nil {
+		return deleted, err
+	}
+	n, _ := res.RowsAffected()
+	return deleted + n, nil
+}
+
+// ListStatsSnapshots возвращает последний снимок ряда в каждом интервале bucket (hour, day, week)
+// внутри [from, to), а также последний снимок до from как точку отсчёта приростов.
+func (s *PostgresStorage) ListStatsSnapshots(scope, subject, bucket string, from, to time.Time) ([]models.StatsSnapshot, *models.StatsSnapshot, error) {
+	rows, err := s.db.Query(`
+		SELECT DISTINCT ON (date_trunc($3, taken_at))
+		       date_trunc($3, taken_at), open_prs, open_reviews, assignments_total, merges_total
+		FROM stats_snapshots
+		WHERE scope = $1 AND subject = $2 AND taken_at >= $4 AND taken_at < $5
+		ORDER BY date_trunc($3, taken_at), taken_at DESC
+	`, scope, subject, bucket, from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	snapshots := []models.StatsSnapshot{}
+	for rows.Next() {
+		var snap models.StatsSnapshot
+		if err := rows.Scan(&snap.TakenAt, &snap.OpenPRs, &snap.OpenReviews, &snap.AssignmentsTotal, &snap.MergesTotal); err != nil {
+			return nil, nil, err
+		}
+		snapshots = append(snapshots, snap)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	var base models.StatsSnapshot
+	err = s.db.QueryRow(`
+		SELECT taken_at, open_prs, open_reviews, assignments_total, merges_total
+		FROM stats_snapshots
+		WHERE scope = $1 AND subject = $2 AND taken_at < $3
+		ORDER BY taken_at DESC
+		LIMIT 1
+	`, scope, subject, from).Scan(&base.TakenAt, &base.OpenPRs, &base.OpenReviews, &base.AssignmentsTotal, &base.MergesTotal)
+	if err == sql.ErrNoRows {
+		return snapshots, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return snapshots, &base, nil
+}
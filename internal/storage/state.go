@@ -0,0 +1,292 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"pr-reviewer-service/internal/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// SchemaVersion - номер последней миграции в migrations/; увеличивается вместе с новой миграцией
+const SchemaVersion = 13
+
+// stateTables - таблицы, которые должны быть пустыми перед восстановлением, в порядке зависимостей
+var stateTables = []string{
+	"teams", "users", "user_status_changes", "pull_requests", "pr_events", "team_sla",
+	"sla_breaches", "reminder_settings", "chat_identities", "api_keys",
+}
+
+// DumpState читает всё состояние в одной транзакции REPEATABLE READ, чтобы архив был согласованным
+func (s *PostgresStorage) DumpState(ctx context.Context) (*models.StateData, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	data := &models.StateData{}
+	each := func(query string, scan func(*sql.Rows) error) error {
+		rows, err := tx.QueryContext(ctx, query)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			if err := scan(rows); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	}
+
+	steps := []struct {
+		query string
+		scan  func(*sql.Rows) error
+	}{
+		{`SELECT team_name FROM teams ORDER BY team_name`, func(rows *sql.Rows) error {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				return err
+			}
+			data.Teams = append(data.Teams, name)
+			return nil
+		}},
+		{`SELECT user_id, username, team_name, is_active FROM users ORDER BY user_id`, func(rows *sql.Rows) error {
+			var u models.User
+			if err := rows.Scan(&u.UserID, &u.Username, &u.TeamName, &u.IsActive); err != nil {
+				return err
+			}
+			data.Users = append(data.Users, u)
+			return nil
+		}},
+		{`SELECT user_id, is_active, changed_at FROM user_status_changes ORDER BY changed_at, id`, func(rows *sql.Rows) error {
+			var c models.StatusChange
+			if err := rows.Scan(&c.UserID, &c.IsActive, &c.ChangedAt); err != nil {
+				return err
+			}
+			data.StatusChanges = append(data.StatusChanges, c)
+			return nil
+		}},
+		{`SELECT pull_request_id, pull_request_name, author_id, status, assigned_reviewers, created_at, merged_at
+		  FROM pull_requests ORDER BY created_at, pull_request_id`, func(rows *sql.Rows) error {
+			var pr models.PullRequest
+			var reviewersJSON string
+			var createdAt time.Time
+			var mergedAt sql.NullTime
+			if err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status,
+				&reviewersJSON, &createdAt, &mergedAt); err != nil {
+				return err
+			}
+			pr.CreatedAt = &createdAt
+			if mergedAt.Valid {
+				pr.MergedAt = &mergedAt.Time
+			}
+			if err := json.Unmarshal([]byte(reviewersJSON), &pr.AssignedReviewers); err != nil {
+				return err
+			}
+			data.PullRequests = append(data.PullRequests, pr)
+			return nil
+		}},
+		{`SELECT id, pull_request_id, event_type, actor, COALESCE(reviewer_id, ''), COALESCE(previous_reviewer_id, ''),
+		         reason, from_status, to_status, created_at
+		  FROM pr_events ORDER BY id`, func(rows *sql.Rows) error {
+			var e models.PREvent
+			if err := rows.Scan(&e.ID, &e.PullRequestID, &e.Type, &e.Actor, &e.ReviewerID,
+				&e.PreviousReviewerID, &e.Reason, &e.FromStatus, &e.ToStatus, &e.CreatedAt); err != nil {
+				return err
+			}
+			data.PREvents = append(data.PREvents, e)
+			return nil
+		}},
+		{`SELECT team_name, first_review_minutes, time_zone, workday_start, workday_end,
+		         weekend_days, holidays::text[], auto_reassign
+		  FROM team_sla ORDER BY team_name`, func(rows *sql.Rows) error {
+			var sla models.TeamSLA
+			var weekend, holidays pq.StringArray
+			if err := rows.Scan(&sla.TeamName, &sla.FirstReviewMinutes, &sla.TimeZone, &sla.WorkdayStart,
+				&sla.WorkdayEnd, &weekend, &holidays, &sla.AutoReassign); err != nil {
+				return err
+			}
+			sla.WeekendDays = []string(weekend)
+			sla.Holidays = []string(holidays)
+			data.TeamSLAs = append(data.TeamSLAs, sla)
+			return nil
+		}},
+		{`SELECT id, pull_request_id, reviewer_id, assigned_at, detected_at, resolved_at
+		  FROM sla_breaches ORDER BY id`, func(rows *sql.Rows) error {
+			var b models.SLABreach
+			var resolvedAt sql.NullTime
+			if err := rows.Scan(&b.ID, &b.PullRequestID, &b.ReviewerID, &b.AssignedAt, &b.DetectedAt, &resolvedAt); err != nil {
+				return err
+			}
+			if resolvedAt.Valid {
+				b.ResolvedAt = &resolvedAt.Time
+			}
+			data.SLABreaches = append(data.SLABreaches, b)
+			return nil
+		}},
+		{`SELECT ` + reminderColumns + ` FROM reminder_settings ORDER BY user_id`, func(rows *sql.Rows) error {
+			r, err := scanReminder(rows)
+			if err != nil {
+				return err
+			}
+			data.ReminderSettings = append(data.ReminderSettings, *r)
+			return nil
+		}},
+		{`SELECT chat_user_id, user_id FROM chat_identities ORDER BY chat_user_id`, func(rows *sql.Rows) error {
+			var c models.ChatIdentity
+			if err := rows.Scan(&c.ChatUserID, &c.UserID); err != nil {
+				return err
+			}
+			data.ChatIdentities = append(data.ChatIdentities, c)
+			return nil
+		}},
+		{`SELECT ` + apiKeyColumns + `, key_hash FROM api_keys ORDER BY id`, func(rows *sql.Rows) error {
+			var key models.StateAPIKey
+			var lastUsedAt, revokedAt sql.NullTime
+			if err := rows.Scan(&key.ID, &key.Name, &key.Prefix, &key.Role, &key.UserID, &key.TeamName,
+				&key.CreatedAt, &lastUsedAt, &revokedAt, &key.KeyHash); err != nil {
+				return err
+			}
+			if lastUsedAt.Valid {
+				key.LastUsedAt = &lastUsedAt.Time
+			}
+			if revokedAt.Valid {
+				key.RevokedAt = &revokedAt.Time
+			}
+			data.APIKeys = append(data.APIKeys, key)
+			return nil
+		}},
+	}
+	for _, step := range steps {
+		if err := each(step.query, step.scan); err != nil {
+			return nil, err
+		}
+	}
+
+	return data, tx.Commit()
+}
+
+// RestoreState загружает архив в пустую БД в одной транзакции и перед фиксацией
+// сверяет количество записей в таблицах с архивом; entry пишется в журнал аудита в той же транзакции
+func (s *PostgresStorage) RestoreState(ctx context.Context, data *models.StateData, entry *models.AuditEntry) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range stateTables {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM `+table+`)`).Scan(&exists); err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("NOT_EMPTY")
+		}
+	}
+
+	exec := func(query string, args ...interface{}) error {
+		_, err := tx.ExecContext(ctx, query, args...)
+		return err
+	}
+
+	for _, team := range data.Teams {
+		if err := exec(`INSERT INTO teams (team_name) VALUES ($1)`, team); err != nil {
+			return err
+		}
+	}
+	for _, u := range data.Users {
+		if err := exec(`INSERT INTO users (user_id, username, team_name, is_active) VALUES ($1, $2, $3, $4)`,
+			u.UserID, u.Username, u.TeamName, u.IsActive); err != nil {
+			return err
+		}
+	}
+	for _, c := range data.StatusChanges {
+		if err := exec(`INSERT INTO user_status_changes (user_id, is_active, changed_at) VALUES ($1, $2, $3)`,
+			c.UserID, c.IsActive, c.ChangedAt); err != nil {
+			return err
+		}
+	}
+	for _, pr := range data.PullRequests {
+		reviewersJSON, _ := json.Marshal(pr.AssignedReviewers)
+		if err := exec(`
+			INSERT INTO pull_requests
+			(pull_request_id, pull_request_name, author_id, status, assigned_reviewers, created_at, merged_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, pr.PullRequestID, pr.PullRequestName, pr.AuthorID, pr.Status, reviewersJSON, pr.CreatedAt, pr.MergedAt); err != nil {
+			return err
+		}
+	}
+	for i := range data.PREvents {
+		if err := insertPREvent(tx, &data.PREvents[i]); err != nil {
+			return err
+		}
+	}
+	for _, sla := range data.TeamSLAs {
+		if err := exec(`
+			INSERT INTO team_sla
+			(team_name, first_review_minutes, time_zone, workday_start, workday_end, weekend_days, holidays, auto_reassign)
+			VALUES ($1, $2, $3, $4, $5, $6, $7::date[], $8)
+		`, sla.TeamName, sla.FirstReviewMinutes, sla.TimeZone, sla.WorkdayStart, sla.WorkdayEnd,
+			pq.Array(sla.WeekendDays), pq.Array(sla.Holidays), sla.AutoReassign); err != nil {
+			return err
+		}
+	}
+	for _, b := range data.SLABreaches {
+		if err := exec(`
+			INSERT INTO sla_breaches (pull_request_id, reviewer_id, assigned_at, detected_at, resolved_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`, b.PullRequestID, b.ReviewerID, b.AssignedAt, b.DetectedAt, b.ResolvedAt); err != nil {
+			return err
+		}
+	}
+	for _, r := range data.ReminderSettings {
+		if err := exec(`
+			INSERT INTO reminder_settings (user_id, enabled, channel, address, time_zone, send_at, last_sent_on)
+			VALUES ($1, $2, $3, $4, $5, $6, $7::date)
+		`, r.UserID, r.Enabled, r.Channel, r.Address, r.TimeZone, r.SendAt, r.LastSentOn); err != nil {
+			return err
+		}
+	}
+	for _, c := range data.ChatIdentities {
+		if err := exec(`INSERT INTO chat_identities (chat_user_id, user_id) VALUES ($1, $2)`, c.ChatUserID, c.UserID); err != nil {
+			return err
+		}
+	}
+	for _, key := range data.APIKeys {
+		if err := exec(`
+			INSERT INTO api_keys (name, key_hash, prefix, role, user_id, team_name, created_at, last_used_at, revoked_at)
+			VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7, $8, $9)
+		`, key.Name, key.KeyHash, key.Prefix, key.Role, key.UserID, key.TeamName,
+			key.CreatedAt, key.LastUsedAt, key.RevokedAt); err != nil {
+			return err
+		}
+	}
+
+	// Проверка целостности: в таблицах ровно столько записей, сколько в архиве
+	counts := data.Counts()
+	tableKeys := map[string]string{"team_sla": "team_slas", "user_status_changes": "status_changes"}
+	for _, table := range stateTables {
+		key := table
+		if k, ok := tableKeys[table]; ok {
+			key = k
+		}
+		var n int
+		if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+table).Scan(&n); err != nil {
+			return err
+		}
+		if n != counts[key] {
+			return fmt.Errorf("restore verification failed: %s has %d rows, archive has %d", table, n, counts[key])
+		}
+	}
+
+	if err := insertAudit(tx, entry); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,171 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"pr-reviewer-service/internal/models"
+	"strings"
+	"time"
+)
+
+// openAgeBuckets - границы распределения возраста открытых PR в часах
+var openAgeBuckets = []models.AgeBucket{
+	{Label: "<1d", MinHours: 0, MaxHours: 24},
+	{Label: "1-3d", MinHours: 24, MaxHours: 72},
+	{Label: "3-7d", MinHours: 72, MaxHours: 168},
+	{Label: ">7d", MinHours: 168},
+}
+
+// statsScope возвращает CTE prs с PR, подходящими под фильтр, и его аргументы.
+// $1 - команда (пустая строка - все команды).
+func statsScope(filter models.StatsFilter) (string, []interface{}) {
+	conds := []string{"($1 = '' OR author_id IN (SELECT user_id FROM users WHERE team_name = $1))"}
+	args := []interface{}{filter.TeamName}
+
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		conds = append(conds, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		conds = append(conds, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+
+	return `WITH prs AS (
+		SELECT pull_request_id, status, assigned_reviewers, created_at, merged_at
+		FROM pull_requests
+		WHERE ` + strings.Join(conds, " AND ") + `
+	)`, args
+}
+
+// GetStats считает статистику по PR, подходящим под фильтр, за три агрегирующих запроса
+func (s *PostgresStorage) GetStats(filter models.StatsFilter) (*models.Stats, error) {
+	stats := &models.Stats{
+		Filter:       filter,
+		TopReviewers: []models.ReviewerStats{},
+		ReviewerLoad: []models.ReviewerStats{},
+	}
+	scope, args := statsScope(filter)
+
+	// Счётчики, время до слияния и возраст открытых PR
+	now := len(args) + 1
+	ageColumns := make([]string, len(openAgeBuckets))
+	for i, b := range openAgeBuckets {
+		cond := fmt.Sprintf("$%d::timestamp - created_at >= interval '%d hours'", now, b.MinHours)
+		if b.MaxHours > 0 {
+			cond += fmt.Sprintf(" AND $%d::timestamp - created_at < interval '%d hours'", now, b.MaxHours)
+		}
+		ageColumns[i] = "COUNT(*) FILTER (WHERE status = 'OPEN' AND " + cond + ")"
+	}
+
+	var median, p90 sql.NullFloat64
+	ages := make([]int, len(openAgeBuckets))
+	dest := []interface{}{
+		&stats.TotalTeams, &stats.TotalUsers,
+		&stats.TotalPRs, &stats.OpenPRs, &stats.MergedPRs,
+		&stats.TimeToMerge.Count, &median, &p90,
+	}
+	for i := range ages {
+		dest = append(dest, &ages[i])
+	}
+
+	err := s.db.QueryRow(scope+`
+		SELECT
+			(SELECT COUNT(*) FROM teams WHERE $1 = '' OR team_name = $1),
+			(SELECT COUNT(*) FROM users WHERE $1 = '' OR team_name = $1),
+			COUNT(*),
+			COUNT(*) FILTER (WHERE status = 'OPEN'),
+			COUNT(*) FILTER (WHERE status = 'MERGED'),
+			COUNT(merged_at),
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM merged_at - created_at)::float8),
+			percentile_cont(0.9) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM merged_at - created_at)::float8),
+			`+strings.Join(ageColumns, ",\n\t\t\t")+`
+		FROM prs
+	`, append(args, time.Now())...).Scan(dest...)
+	if err != nil {
+		return nil, err
+	}
+
+	if median.Valid {
+		stats.TimeToMerge.MedianSeconds = &median.Float64
+	}
+	if p90.Valid {
+		stats.TimeToMerge.P90Seconds = &p90.Float64
+	}
+	for i, b := range openAgeBuckets {
+		b.Count = ages[i]
+		stats.OpenPRAge = append(stats.OpenPRAge, b)
+	}
+
+	// Переназначения
+	err = s.db.QueryRow(scope+`
+		SELECT COUNT(*), COUNT(DISTINCT e.pull_request_id)
+		FROM pr_events e
+		JOIN prs ON prs.pull_request_id = e.pull_request_id
+		WHERE e.event_type = '`+models.EventReviewerReplaced+`'
+	`, args...).Scan(&stats.Reassignments.Total, &stats.Reassignments.PRsReassigned)
+	if err != nil {
+		return nil, err
+	}
+	if stats.TotalPRs > 0 {
+		stats.Reassignments.Rate = float64(stats.Reassignments.PRsReassigned) / float64(stats.TotalPRs)
+	}
+
+	// Нагрузка на ревьюверов: назначения за период, текущие открытые ревью и снятия с ревью
+	rows, err := s.db.Query(scope+`,
+	assigned AS (
+		SELECT e.reviewer_id AS user_id, COUNT(*) AS n
+		FROM pr_events e
+		JOIN prs ON prs.pull_request_id = e.pull_request_id
+		WHERE e.event_type IN ('`+models.EventReviewerAssigned+`', '`+models.EventReviewerReplaced+`')
+		  AND e.reviewer_id IS NOT NULL
+		GROUP BY e.reviewer_id
+	),
+	away AS (
+		SELECT e.previous_reviewer_id AS user_id, COUNT(*) AS n
+		FROM pr_events e
+		JOIN prs ON prs.pull_request_id = e.pull_request_id
+		WHERE e.event_type = '`+models.EventReviewerReplaced+`'
+		  AND e.previous_reviewer_id IS NOT NULL
+		GROUP BY e.previous_reviewer_id
+	),
+	open_reviews AS (
+		SELECT r.value AS user_id, COUNT(*) AS n
+		FROM prs, jsonb_array_elements_text(prs.assigned_reviewers) r
+		WHERE prs.status = 'OPEN'
+		GROUP BY r.value
+	)
+	SELECT u.user_id, COALESCE(a.n, 0), COALESCE(o.n, 0), COALESCE(w.n, 0)
+	FROM (
+		SELECT user_id FROM assigned
+		UNION SELECT user_id FROM away
+		UNION SELECT user_id FROM open_reviews
+	) u
+	LEFT JOIN assigned a ON a.user_id = u.user_id
+	LEFT JOIN open_reviews o ON o.user_id = u.user_id
+	LEFT JOIN away w ON w.user_id = u.user_id
+	ORDER BY 2 DESC, 3 DESC, u.user_id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r models.ReviewerStats
+		if err := rows.Scan(&r.UserID, &r.AssignmentCount, &r.OpenReviews, &r.ReassignedAway); err != nil {
+			return nil, err
+		}
+		stats.ReviewerLoad = append(stats.ReviewerLoad, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	stats.TopReviewers = stats.ReviewerLoad
+	if len(stats.TopReviewers) > 10 {
+		stats.TopReviewers = stats.TopReviewers[:10]
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,349 @@
+package ui
+
+import (
+	"fmt"
+	"net/http"
+	"pr-reviewer-service/internal/auth"
+	"pr-reviewer-service/internal/models"
+	"pr-reviewer-service/internal/pagination"
+	"pr-reviewer-service/internal/validation"
+	"sort"
+)
+
+// Dashboard - статистика сервиса и список команд
+func (h *Handler) Dashboard(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	r, ok := h.authorize(w, r, viewers)
+	if !ok {
+		return
+	}
+
+	stats, err := h.service.GetStats(models.StatsFilter{})
+	if err != nil {
+		h.internalError(w, r, err)
+		return
+	}
+
+	teams := []models.TeamSummary{}
+	var cursor *models.PageCursor
+	for {
+		page, next, err := h.service.ListTeams(cursor, pagination.MaxLimit)
+		if err != nil {
+			h.internalError(w, r, err)
+			return
+		}
+		teams = append(teams, page...)
+		if next == "" {
+			break
+		}
+		cursor = &models.PageCursor{ID: page[len(page)-1].TeamName}
+	}
+
+	h.render(w, r, http.StatusOK, "dashboard", "Обзор", map[string]interface{}{
+		"Stats": stats,
+		"Teams": teams,
+	})
+}
+
+type memberRow struct {
+	models.TeamMember
+	OpenReviews int
+}
+
+// Team - участники команды с нагрузкой и переключателем активности
+func (h *Handler) Team(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	r, ok := h.authorize(w, r, viewers)
+	if !ok {
+		return
+	}
+
+	teamName := r.URL.Query().Get("team_name")
+	if msg := validation.Name(teamName); msg != "" {
+		h.renderError(w, r, http.StatusBadRequest, "team_name: "+msg)
+		return
+	}
+
+	team, err := h.service.GetTeam(teamName)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			h.renderError(w, r, http.StatusNotFound, fmt.Sprintf("Команда %s не найдена.", teamName))
+			return
+		}
+		h.internalError(w, r, err)
+		return
+	}
+	load, err := h.service.GetTeamReviewLoad(teamName)
+	if err != nil {
+		h.internalError(w, r, err)
+		return
+	}
+	openReviews := make(map[string]int, len(load))
+	for _, l := range load {
+		openReviews[l.UserID] = l.OpenReviews
+	}
+
+	members := make([]memberRow, 0, len(team.Members))
+	for _, m := range team.Members {
+		members = append(members, memberRow{TeamMember: m, OpenReviews: openReviews[m.UserID]})
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].UserID < members[j].UserID })
+
+	h.render(w, r, http.StatusOK, "team", "Команда "+teamName, map[string]interface{}{
+		"TeamName":    teamName,
+		"Members":     members,
+		"CanActivate": canActivate(r, teamName),
+	})
+}
+
+// Queue - открытые PR, где пользователь ревьювер, от самых старых
+func (h *Handler) Queue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	r, ok := h.authorize(w, r, viewers)
+	if !ok {
+		return
+	}
+
+	userID := r.URL.Query().Get("user_id")
+	if msg := validation.ID(userID); msg != "" {
+		h.renderError(w, r, http.StatusBadRequest, "user_id: "+msg)
+		return
+	}
+
+	user, err := h.service.GetUser(userID)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			h.renderError(w, r, http.StatusNotFound, fmt.Sprintf("Пользователь %s не найден.", userID))
+			return
+		}
+		h.internalError(w, r, err)
+		return
+	}
+	prs, err := h.service.OpenReviews(userID)
+	if err != nil {
+		h.internalError(w, r, err)
+		return
+	}
+
+	h.render(w, r, http.StatusOK, "queue", "Очередь ревью "+user.Username, map[string]interface{}{
+		"User":         user,
+		"PullRequests": prs,
+		"CanReassign":  canReassign(r),
+	})
+}
+
+// Pulls - открытые PR с возрастом и ревьюверами, постранично, с фильтром по команде
+func (h *Handler) Pulls(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	r, ok := h.authorize(w, r, viewers)
+	if !ok {
+		return
+	}
+
+	q := r.URL.Query()
+	filter := models.PRListFilter{
+		TeamName: q.Get("team_name"),
+		Statuses: []string{"OPEN"},
+		Limit:    pagination.DefaultLimit,
+	}
+	if c := q.Get("cursor"); c != "" {
+		var cursor models.PageCursor
+		if err := pagination.Decode(c, &cursor); err != nil {
+			h.renderError(w, r, http.StatusBadRequest, "Некорректный курсор страницы.")
+			return
+		}
+		filter.After = &cursor
+	}
+
+	page, err := h.service.ListPRs(filter)
+	if err != nil {
+		if err.Error() == "INVALID_CURSOR" {
+			h.renderError(w, r, http.StatusBadRequest, "Некорректный курсор страницы.")
+			return
+		}
+		h.internalError(w, r, err)
+		return
+	}
+
+	h.render(w, r, http.StatusOK, "pulls", "Открытые PR", map[string]interface{}{
+		"TeamName":    filter.TeamName,
+		"Page":        page,
+		"CanReassign": canReassign(r),
+	})
+}
+
+// SetActive переключает активность пользователя; тимлид - только в своей команде
+func (h *Handler) SetActive(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	r, ok := h.authorize(w, r, activators)
+	if !ok || !h.parseForm(w, r) {
+		return
+	}
+
+	userID := r.PostForm.Get("user_id")
+	isActive := r.PostForm.Get("is_active") == "true"
+	if msg := validation.ID(userID); msg != "" {
+		setFlash(w, r, true, "user_id: "+msg)
+		redirectBack(w, r)
+		return
+	}
+
+	if principal := auth.FromContext(r.Context()); principal != nil && principal.Role == auth.RoleTeamLead {
+		userTeam, err := h.service.GetUserTeam(userID)
+		if err != nil && err.Error() != "NOT_FOUND" {
+			h.internalError(w, r, err)
+			return
+		}
+		if err == nil && userTeam != principal.TeamName {
+			setFlash(w, r, true, "Тимлид может менять активность только участников своей команды.")
+			redirectBack(w, r)
+			return
+		}
+	}
+
+	user, err := h.service.SetUserActive(r.Context(), userID, isActive)
+	switch {
+	case err == nil && user.IsActive:
+		setFlash(w, r, false, fmt.Sprintf("%s снова получает назначения.", user.Username))
+	case err == nil:
+		setFlash(w, r, false, fmt.Sprintf("%s больше не получает назначения.", user.Username))
+	case err.Error() == "NOT_FOUND":
+		setFlash(w, r, true, fmt.Sprintf("Пользователь %s не найден.", userID))
+	default:
+		h.internalError(w, r, err)
+		return
+	}
+	redirectBack(w, r)
+}
+
+// Reassign заменяет ревьювера PR так же, как /pullRequest/reassign
+func (h *Handler) Reassign(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	r, ok := h.authorize(w, r, reassigners)
+	if !ok || !h.parseForm(w, r) {
+		return
+	}
+
+	prID := r.PostForm.Get("pull_request_id")
+	oldUserID := r.PostForm.Get("old_user_id")
+	for _, f := range []struct{ name, value string }{{"pull_request_id", prID}, {"old_user_id", oldUserID}} {
+		if msg := validation.ID(f.value); msg != "" {
+			setFlash(w, r, true, f.name+": "+msg)
+			redirectBack(w, r)
+			return
+		}
+	}
+
+	_, newUserID, err := h.service.ReassignReviewer(r.Context(), prID, oldUserID)
+	if err != nil {
+		switch err.Error() {
+		case "NOT_FOUND":
+			setFlash(w, r, true, fmt.Sprintf("PR %s или пользователь %s не найден.", prID, oldUserID))
+		case "PR_MERGED":
+			setFlash(w, r, true, fmt.Sprintf("PR %s уже слит, ревьюверов менять нельзя.", prID))
+		case "NOT_ASSIGNED":
+			setFlash(w, r, true, fmt.Sprintf("%s не ревьювер PR %s.", oldUserID, prID))
+		case "NO_CANDIDATE":
+			setFlash(w, r, true, fmt.Sprintf("В команде нет активного кандидата на замену %s.", oldUserID))
+		case "PR_MODIFIED":
+			setFlash(w, r, true, fmt.Sprintf("PR %s только что изменили, обновите страницу и повторите.", prID))
+		default:
+			h.internalError(w, r, err)
+			return
+		}
+		redirectBack(w, r)
+		return
+	}
+
+	setFlash(w, r, false, fmt.Sprintf("PR %s: ревьювер %s заменён на %s.", prID, oldUserID, newUserID))
+	redirectBack(w, r)
+}
+
+// Login принимает API-ключ или JWT и сохраняет его в cookie сессии
+func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.auth == nil {
+		http.Redirect(w, r, "/ui", http.StatusSeeOther)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		h.render(w, r, http.StatusOK, "login", "Вход", "")
+	case "POST":
+		if !h.parseForm(w, r) {
+			return
+		}
+		credential := r.PostForm.Get("credential")
+		if _, err := h.authenticate(credential); err != nil {
+			if err.Error() != "UNAUTHORIZED" {
+				h.internalError(w, r, err)
+				return
+			}
+			h.render(w, r, http.StatusUnauthorized, "login", "Вход", "Ключ или токен не подошёл.")
+			return
+		}
+		setCookie(w, r, sessionCookie, credential)
+		http.Redirect(w, r, "/ui", http.StatusSeeOther)
+	}
+}
+
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.parseForm(w, r) {
+		return
+	}
+	clearCookie(w, r, sessionCookie)
+	http.Redirect(w, r, "/ui/login", http.StatusSeeOther)
+}
+
+func (h *Handler) Style(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/css; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Write(styleCSS)
+}
+
+// canActivate - показывать ли переключатель активности в команде
+func canActivate(r *http.Request, teamName string) bool {
+	principal := auth.FromContext(r.Context())
+	if principal == nil {
+		return true
+	}
+	if principal.Role == auth.RoleTeamLead {
+		return principal.TeamName == teamName
+	}
+	return activators.Allows(principal.Role)
+}
+
+func canReassign(r *http.Request) bool {
+	principal := auth.FromContext(r.Context())
+	return principal == nil || reassigners.Allows(principal.Role)
+}
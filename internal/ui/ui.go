@@ -0,0 +1,287 @@
+// Package ui - встроенная HTML-панель /ui: команды и участники, очереди ревью, открытые PR и статистика.
+// Страницы рендерятся на сервере из встроенных шаблонов, внешних CDN и JavaScript нет.
+package ui
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"pr-reviewer-service/internal/audit"
+	"pr-reviewer-service/internal/auth"
+	"pr-reviewer-service/internal/service"
+	"strings"
+	"time"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+//go:embed assets/style.css
+var styleCSS []byte
+
+const (
+	sessionCookie = "prr_session"
+	csrfCookie    = "prr_csrf"
+	flashCookie   = "prr_flash"
+	csrfField     = "csrf_token"
+
+	maxFormSize = 64 << 10
+)
+
+var (
+	viewers     = auth.Policy{Roles: []auth.Role{auth.RoleAdmin, auth.RoleTeamLead, auth.RoleMember, auth.RoleBot}}
+	reassigners = auth.Policy{Roles: []auth.Role{auth.RoleAdmin, auth.RoleTeamLead, auth.RoleMember}}
+	activators  = auth.Policy{Roles: []auth.Role{auth.RoleAdmin, auth.RoleTeamLead}}
+)
+
+type Handler struct {
+	service *service.PRService
+	// auth равен nil, если аутентификация выключена
+	auth  *auth.Authenticator
+	pages map[string]*template.Template
+}
+
+func NewHandler(service *service.PRService, authenticator *auth.Authenticator) *Handler {
+	h := &Handler{service: service, auth: authenticator, pages: make(map[string]*template.Template)}
+	for _, name := range []string{"dashboard", "team", "queue", "pulls", "login", "error"} {
+		h.pages[name] = template.Must(template.New("layout.html").Funcs(funcs).
+			ParseFS(templateFS, "templates/layout.html", "templates/"+name+".html"))
+	}
+	return h
+}
+
+// page - данные, общие для всех страниц
+type page struct {
+	Title       string
+	Principal   *auth.Principal
+	AuthEnabled bool
+	CSRF        string
+	Flash       *flash
+	// Return - адрес текущей страницы, куда вернуться после действия
+	Return string
+	Data   interface{}
+}
+
+type flash struct {
+	Error bool
+	Text  string
+}
+
+func (h *Handler) render(w http.ResponseWriter, r *http.Request, status int, name, title string, data interface{}) {
+	p := page{
+		Title:       title,
+		Principal:   auth.FromContext(r.Context()),
+		AuthEnabled: h.auth != nil,
+		CSRF:        csrfToken(w, r),
+		Flash:       takeFlash(w, r),
+		Return:      r.URL.RequestURI(),
+		Data:        data,
+	}
+
+	var buf bytes.Buffer
+	if err := h.pages[name].Execute(&buf, p); err != nil {
+		log.Printf("ui: render %s: %v", name, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	setSecurityHeaders(w)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
+
+func (h *Handler) renderError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	h.render(w, r, status, "error", http.StatusText(status), message)
+}
+
+func (h *Handler) internalError(w http.ResponseWriter, r *http.Request, err error) {
+	log.Printf("ui: %s %s: %v", r.Method, r.URL.Path, err)
+	h.renderError(w, r, http.StatusInternalServerError, "Что-то пошло не так, попробуйте позже.")
+}
+
+func setSecurityHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Security-Policy", "default-src 'self'; form-action 'self'; frame-ancestors 'none'")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Referrer-Policy", "same-origin")
+	w.Header().Set("Cache-Control", "no-store")
+}
+
+// authorize проверяет сессию и роль. Маршруты /ui публичны для auth-middleware: браузер
+// передаёт учётные данные в cookie, а не в заголовке, поэтому панель проверяет их сама.
+// Без сессии GET-запрос перенаправляется на /ui/login.
+func (h *Handler) authorize(w http.ResponseWriter, r *http.Request, policy auth.Policy) (*http.Request, bool) {
+	if h.auth == nil {
+		return r, true
+	}
+
+	principal, err := h.sessionPrincipal(r)
+	if err != nil {
+		if err.Error() == "UNAUTHORIZED" {
+			clearCookie(w, r, sessionCookie)
+			http.Redirect(w, r, "/ui/login", http.StatusSeeOther)
+			return nil, false
+		}
+		h.internalError(w, r, err)
+		return nil, false
+	}
+
+	ctx := auth.WithPrincipal(r.Context(), principal)
+	ctx = audit.WithActor(ctx, principal.Subject)
+	r = r.WithContext(ctx)
+	if !policy.Allows(principal.Role) {
+		h.renderError(w, r, http.StatusForbidden, "Вашей роли это действие недоступно.")
+		return nil, false
+	}
+	return r, true
+}
+
+func (h *Handler) sessionPrincipal(r *http.Request) (*auth.Principal, error) {
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil || cookie.Value == "" {
+		return nil, fmt.Errorf("UNAUTHORIZED")
+	}
+	return h.authenticate(cookie.Value)
+}
+
+// authenticate проверяет API-ключ или JWT так же, как это делает auth-middleware для заголовков
+func (h *Handler) authenticate(credential string) (*auth.Principal, error) {
+	header := http.Header{}
+	if strings.Count(credential, ".") == 2 && !strings.HasPrefix(credential, auth.APIKeyPrefix) {
+		header.Set("Authorization", "Bearer "+credential)
+	} else {
+		header.Set("X-API-Key", credential)
+	}
+	return h.auth.AuthenticateHeader(header)
+}
+
+// parseForm читает форму действия и проверяет CSRF: токен из скрытого поля должен совпасть
+// с cookie (double submit), а Origin, если браузер его прислал, - с адресом сервиса
+func (h *Handler) parseForm(w http.ResponseWriter, r *http.Request) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, maxFormSize)
+	if err := r.ParseForm(); err != nil {
+		h.renderError(w, r, http.StatusBadRequest, "Некорректная форма.")
+		return false
+	}
+
+	cookie, err := r.Cookie(csrfCookie)
+	token := r.PostForm.Get(csrfField)
+	if err != nil || cookie.Value == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(token)) != 1 || !sameOrigin(r) {
+		h.renderError(w, r, http.StatusForbidden, "Форма устарела или отправлена с другого сайта. Обновите страницу и повторите.")
+		return false
+	}
+	return true
+}
+
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+// csrfToken возвращает токен из cookie, выпуская новый при первом визите
+func csrfToken(w http.ResponseWriter, r *http.Request) string {
+	if cookie, err := r.Cookie(csrfCookie); err == nil && len(cookie.Value) == 64 {
+		return cookie.Value
+	}
+	buf := make([]byte, 32)
+	rand.Read(buf)
+	token := hex.EncodeToString(buf)
+	setCookie(w, r, csrfCookie, token)
+	return token
+}
+
+func setFlash(w http.ResponseWriter, r *http.Request, isError bool, text string) {
+	kind := "i"
+	if isError {
+		kind = "e"
+	}
+	setCookie(w, r, flashCookie, url.QueryEscape(kind+text))
+}
+
+// takeFlash возвращает сообщение о результате предыдущего действия и удаляет его
+func takeFlash(w http.ResponseWriter, r *http.Request) *flash {
+	cookie, err := r.Cookie(flashCookie)
+	if err != nil {
+		return nil
+	}
+	clearCookie(w, r, flashCookie)
+	value, err := url.QueryUnescape(cookie.Value)
+	if err != nil || value == "" {
+		return nil
+	}
+	return &flash{Error: value[0] == 'e', Text: value[1:]}
+}
+
+func setCookie(w http.ResponseWriter, r *http.Request, name, value string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/ui",
+		HttpOnly: true,
+		Secure:   secure(r),
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+func clearCookie(w http.ResponseWriter, r *http.Request, name string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Path:     "/ui",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   secure(r),
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+func secure(r *http.Request) bool {
+	return r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https"
+}
+
+// redirectBack возвращает на страницу, с которой отправлена форма; чужие адреса заменяются на /ui
+func redirectBack(w http.ResponseWriter, r *http.Request) {
+	target := r.PostForm.Get("return")
+	if !strings.HasPrefix(target, "/ui") || strings.HasPrefix(target, "//") || strings.ContainsAny(target, "\\\r\n") {
+		target = "/ui"
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
+
+var funcs = template.FuncMap{
+	"age": func(t *time.Time) string {
+		if t == nil {
+			return "—"
+		}
+		return formatDuration(time.Since(*t))
+	},
+	"seconds": func(v *float64) string {
+		if v == nil {
+			return "—"
+		}
+		return formatDuration(time.Duration(*v * float64(time.Second)))
+	},
+	"percent": func(v float64) string {
+		return fmt.Sprintf("%.0f%%", v*100)
+	},
+}
+
+func formatDuration(d time.Duration) string {
+	if days := int(d.Hours()) / 24; days > 0 {
+		return fmt.Sprintf("%dд %dч", days, int(d.Hours())%24)
+	}
+	if d >= time.Hour {
+		return fmt.Sprintf("%dч %dм", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%dм", int(d.Minutes()))
+}
@@ -0,0 +1,59 @@
+// Package validation - общие правила для идентификаторов и имён, которые попадают в столбцы VARCHAR(255).
+// Их применяют проверка запросов по OpenAPI (форматы identifier и name), gRPC API, веб-интерфейс,
+// пакетные операции с PR и импорт.
+package validation
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxLength - длина столбцов team_name, user_id, username, pull_request_id и pull_request_name
+const MaxLength = 255
+
+// IDChars - допустимые символы идентификатора, кроме букв и цифр ASCII
+const IDChars = "._-:@/"
+
+// ID возвращает описание нарушения для идентификатора (user_id, pull_request_id) или пустую строку
+func ID(value string) string {
+	if msg := length(value); msg != "" {
+		return msg
+	}
+	for _, r := range value {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune(IDChars, r)) {
+			return "may contain only latin letters, digits and " + strings.Join(strings.Split(IDChars, ""), " ")
+		}
+	}
+	return ""
+}
+
+// Name возвращает описание нарушения для имени (team_name, username, pull_request_name) или пустую строку
+func Name(value string) string {
+	if msg := length(value); msg != "" {
+		return msg
+	}
+	if !utf8.ValidString(value) {
+		return "must be valid UTF-8"
+	}
+	for _, r := range value {
+		if unicode.IsControl(r) {
+			return "must not contain control characters"
+		}
+	}
+	if strings.TrimSpace(value) != value {
+		return "must not start or end with whitespace"
+	}
+	return ""
+}
+
+func length(value string) string {
+	if value == "" {
+		return "must not be empty"
+	}
+	if utf8.RuneCountInString(value) > MaxLength {
+		return fmt.Sprintf("must be at most %d characters", MaxLength)
+	}
+	return ""
+}
@@ -0,0 +1,52 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestID(t *testing.T) {
+	const chars = "may contain only latin letters, digits and . _ - : @ /"
+	tests := []struct {
+		value, want string
+	}{
+		{"u1", ""},
+		{"team.backend_2:pr-1@gitlab/42", ""},
+		{strings.Repeat("a", MaxLength), ""},
+		{"", "must not be empty"},
+		{strings.Repeat("a", MaxLength+1), "must be at most 255 characters"},
+		{"u 1", chars},
+		{"ю1", chars},
+		{"u1\n", chars},
+		{"u1#", chars},
+		{"ｕ1", chars},
+	}
+	for _, tt := range tests {
+		if got := ID(tt.value); got != tt.want {
+			t.Errorf("ID(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		value, want string
+	}{
+		{"backend", ""},
+		{"Команда поддержки (ночь)", ""},
+		{"Add search: #42 & more", ""},
+		{strings.Repeat("я", MaxLength), ""},
+		{"", "must not be empty"},
+		{strings.Repeat("я", MaxLength+1), "must be at most 255 characters"},
+		{"bad\xffname", "must be valid UTF-8"},
+		{"line\nbreak", "must not contain control characters"},
+		{"tab\there", "must not contain control characters"},
+		{" padded", "must not start or end with whitespace"},
+		{"padded ", "must not start or end with whitespace"},
+	}
+	for _, tt := range tests {
+		if got := Name(tt.value); got != tt.want {
+			t.Errorf("Name(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
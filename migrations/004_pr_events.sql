@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS pr_events (
+    id BIGSERIAL PRIMARY KEY,
+    pull_request_id VARCHAR(255) NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    event_type VARCHAR(64) NOT NULL,
+    actor VARCHAR(255) NOT NULL DEFAULT '',
+    reviewer_id VARCHAR(255),
+    previous_reviewer_id VARCHAR(255),
+    reason VARCHAR(64) NOT NULL DEFAULT '',
+    from_status VARCHAR(50) NOT NULL DEFAULT '',
+    to_status VARCHAR(50) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_events_pr ON pr_events(pull_request_id, id);
+CREATE INDEX IF NOT EXISTS idx_pr_events_reviewer ON pr_events(reviewer_id, created_at);
+
+-- История для PR, созданных до появления таблицы
+INSERT INTO pr_events (pull_request_id, event_type, actor, to_status, created_at)
+SELECT pull_request_id, 'CREATED', 'system', 'OPEN', COALESCE(created_at, CURRENT_TIMESTAMP)
+FROM pull_requests p
+WHERE NOT EXISTS (SELECT 1 FROM pr_events e WHERE e.pull_request_id = p.pull_request_id);
+
+INSERT INTO pr_events (pull_request_id, event_type, actor, reviewer_id, reason, created_at)
+SELECT p.pull_request_id, 'REVIEWER_ASSIGNED', 'system', r.value, 'INITIAL_ASSIGNMENT', COALESCE(p.created_at, CURRENT_TIMESTAMP)
+FROM pull_requests p, jsonb_array_elements_text(p.assigned_reviewers) r
+WHERE NOT EXISTS (SELECT 1 FROM pr_events e WHERE e.pull_request_id = p.pull_request_id AND e.event_type <> 'CREATED');
+
+INSERT INTO pr_events (pull_request_id, event_type, actor, from_status, to_status, created_at)
+SELECT pull_request_id, 'MERGED', 'system', 'OPEN', 'MERGED', merged_at
+FROM pull_requests p
+WHERE status = 'MERGED' AND merged_at IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM pr_events e WHERE e.pull_request_id = p.pull_request_id AND e.event_type = 'MERGED');
@@ -0,0 +1,6 @@
+UPDATE pull_requests SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+ALTER TABLE pull_requests ALTER COLUMN created_at SET NOT NULL;
+
+-- Поиск PR по ревьюверу: assigned_reviewers ? user_id
+CREATE INDEX IF NOT EXISTS idx_pr_reviewers ON pull_requests USING GIN (assigned_reviewers);
+CREATE INDEX IF NOT EXISTS idx_pr_created ON pull_requests(created_at, pull_request_id);
@@ -0,0 +1,9 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Поиск подстроки в названии (ILIKE) и полнотекстовый поиск
+CREATE INDEX IF NOT EXISTS idx_pr_name_trgm ON pull_requests USING GIN (pull_request_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_pr_name_fts ON pull_requests USING GIN (to_tsvector('simple', pull_request_name));
+
+-- PR без ревьюверов
+CREATE INDEX IF NOT EXISTS idx_pr_no_reviewers ON pull_requests(created_at) WHERE assigned_reviewers = '[]'::jsonb;
+CREATE INDEX IF NOT EXISTS idx_pr_status_created ON pull_requests(status, created_at, pull_request_id);
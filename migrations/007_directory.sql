@@ -0,0 +1,3 @@
+-- Поиск пользователей по префиксу имени
+CREATE INDEX IF NOT EXISTS idx_users_username_prefix ON users (lower(username) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_users_team_user ON users(team_name, user_id);
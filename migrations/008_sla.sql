@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS team_sla (
+    team_name VARCHAR(255) PRIMARY KEY REFERENCES teams(team_name) ON DELETE CASCADE,
+    first_review_minutes INTEGER NOT NULL CHECK (first_review_minutes > 0),
+    time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    workday_start VARCHAR(5) NOT NULL DEFAULT '09:00',
+    workday_end VARCHAR(5) NOT NULL DEFAULT '18:00',
+    weekend_days TEXT[] NOT NULL DEFAULT ARRAY['Saturday', 'Sunday'],
+    holidays DATE[] NOT NULL DEFAULT '{}',
+    auto_reassign BOOLEAN NOT NULL DEFAULT false,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS sla_breaches (
+    id BIGSERIAL PRIMARY KEY,
+    pull_request_id VARCHAR(255) NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    reviewer_id VARCHAR(255) NOT NULL,
+    assigned_at TIMESTAMP NOT NULL,
+    detected_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP,
+    UNIQUE (pull_request_id, reviewer_id, assigned_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sla_breaches_open ON sla_breaches(pull_request_id) WHERE resolved_at IS NULL;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS reminder_settings (
+    user_id VARCHAR(255) PRIMARY KEY REFERENCES users(user_id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    channel VARCHAR(32) NOT NULL,
+    address VARCHAR(1024) NOT NULL DEFAULT '',
+    time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    send_at VARCHAR(5) NOT NULL DEFAULT '09:00',
+    -- локальная дата последней отправки, чтобы слать не больше одного дайджеста в день
+    last_sent_on DATE,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_reminders_enabled ON reminder_settings(enabled) WHERE enabled;
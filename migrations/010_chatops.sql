@@ -0,0 +1,6 @@
+-- Связь пользователя чата (Slack, Mattermost) с пользователем сервиса
+CREATE TABLE IF NOT EXISTS chat_identities (
+    chat_user_id VARCHAR(255) PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
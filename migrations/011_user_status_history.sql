@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS user_status_changes (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    is_active BOOLEAN NOT NULL,
+    changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_status_changes_user ON user_status_changes(user_id, changed_at);
+
+-- Начальное состояние: до первого изменения из журнала аудита, иначе текущее
+INSERT INTO user_status_changes (user_id, is_active, changed_at)
+SELECT u.user_id,
+       COALESCE((
+           SELECT (a.before->>'is_active')::boolean
+           FROM audit_log a
+           WHERE a.action = 'user.set_active' AND a.target_id = u.user_id
+           ORDER BY a.id
+           LIMIT 1
+       ), u.is_active),
+       'epoch'::timestamp
+FROM users u
+WHERE NOT EXISTS (SELECT 1 FROM user_status_changes c WHERE c.user_id = u.user_id);
+
+INSERT INTO user_status_changes (user_id, is_active, changed_at)
+SELECT a.target_id, (a.after->>'is_active')::boolean, a.created_at
+FROM audit_log a
+JOIN users u ON u.user_id = a.target_id
+WHERE a.action = 'user.set_active'
+  AND NOT EXISTS (SELECT 1 FROM user_status_changes c WHERE c.user_id = a.target_id AND c.changed_at > 'epoch'::timestamp);
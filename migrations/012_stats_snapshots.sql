@@ -0,0 +1,15 @@
+-- Снимки метрик для графиков. scope: global, team или user; subject - имя команды или user_id.
+-- open_prs и open_reviews - значения на момент снимка, assignments_total и merges_total - накопленные счётчики.
+CREATE TABLE IF NOT EXISTS stats_snapshots (
+    id BIGSERIAL PRIMARY KEY,
+    taken_at TIMESTAMP NOT NULL,
+    scope VARCHAR(16) NOT NULL,
+    subject VARCHAR(255) NOT NULL DEFAULT '',
+    open_prs INTEGER NOT NULL,
+    open_reviews INTEGER NOT NULL,
+    assignments_total INTEGER NOT NULL,
+    merges_total INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_stats_snapshots_series ON stats_snapshots(scope, subject, taken_at);
+CREATE INDEX IF NOT EXISTS idx_stats_snapshots_taken ON stats_snapshots(taken_at);